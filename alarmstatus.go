@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	internal_models "github.com/SKF/go-pas-client/internal/models"
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+const (
+	readBackAttempts = 3
+	readBackDelay    = 100 * time.Millisecond
+)
+
+// ErrNotReadBack is wrapped by a ReadBackError when the alarm status read
+// after the write doesn't reflect the write yet.
+var ErrNotReadBack = errors.New("alarm status doesn't reflect the write")
+
+// ReadBackError is returned by UpdateAlarmStatusAndGet and
+// SetExternalAlarmStatusAndGet when the write succeeded, but the resulting
+// alarm status couldn't be read. The write should not be retried in that
+// case, it wraps the error of the read.
+type ReadBackError struct {
+	NodeID uuid.UUID
+	Err    error
+}
+
+func (e ReadBackError) Error() string {
+	return fmt.Sprintf("reading alarm status of node %s after write failed: %s", e.NodeID, e.Err)
+}
+
+func (e ReadBackError) Unwrap() error {
+	return e.Err
+}
+
+// UpdateAlarmStatusAndGet updates the alarm status of the node with the
+// measurement, like UpdateAlarmStatus, and returns the resulting alarm status.
+//
+// The alarm status is taken from the response when the PAS API includes it,
+// which is exactly the outcome of the measurement. Otherwise the alarm status
+// is read in a follow-up request, which also reflects any writes made by
+// others in between. The read is retried a few times until the alarm status
+// reflects the measurement, i.e. it's triggered by the measurement or updated
+// after the alarm status read before the write, after which a ReadBackError
+// wrapping ErrNotReadBack is returned. The alarm status is read before the
+// write as the creation time of a measurement, e.g. a backfilled one, doesn't
+// tell when it was applied.
+func (c *Client) UpdateAlarmStatusAndGet(
+	ctx context.Context,
+	nodeID uuid.UUID,
+	measurement *models.Measurement,
+) (models.AlarmStatus, error) {
+	var before models.AlarmStatus
+
+	if measurement != nil {
+		var err error
+
+		// A node without an alarm status has never been updated.
+		if before, err = c.GetAlarmStatus(ctx, nodeID); err != nil && !errors.Is(err, ErrNotFound) {
+			return models.AlarmStatus{}, fmt.Errorf("reading alarm status before write failed: %w", err)
+		}
+	}
+
+	return c.doAndGetAlarmStatus(ctx, nodeID, updateAlarmStatusRequest(nodeID, measurement), func(a models.AlarmStatus) bool {
+		return reflectsMeasurement(a, measurement, before.UpdatedAt)
+	})
+}
+
+// SetExternalAlarmStatusAndGet sets the external alarm status of the node,
+// like SetExternalAlarmStatus, and returns the resulting alarm status. See
+// UpdateAlarmStatusAndGet for how the alarm status is retrieved, a read alarm
+// status reflects the write when its external alarm status is the one set.
+func (c *Client) SetExternalAlarmStatusAndGet(
+	ctx context.Context,
+	nodeID uuid.UUID,
+	status models.ExternalAlarmStatus,
+) (models.AlarmStatus, error) {
+	return c.doAndGetAlarmStatus(ctx, nodeID, setExternalAlarmStatusRequest(nodeID, status), func(a models.AlarmStatus) bool {
+		return reflectsExternalStatus(a, status)
+	})
+}
+
+func (c *Client) doAndGetAlarmStatus(
+	ctx context.Context,
+	nodeID uuid.UUID,
+	r request,
+	reflectsWrite func(models.AlarmStatus) bool,
+) (models.AlarmStatus, error) {
+	httpResponse, err := c.do(ctx, r)
+	if err != nil {
+		return models.AlarmStatus{}, fmt.Errorf("request failed: %w", err)
+	}
+
+	var response internal_models.ModelsGetAlarmStatusResponse
+
+	if err = unmarshal(httpResponse, &response); err != nil {
+		return models.AlarmStatus{}, ReadBackError{NodeID: nodeID, Err: err}
+	}
+
+	// Responses without a node ID don't carry an alarm status.
+	if response.NodeID == nil {
+		return c.readBackAlarmStatus(ctx, nodeID, reflectsWrite)
+	}
+
+	var alarmStatus models.AlarmStatus
+
+	alarmStatus.FromInternal(response)
+
+	c.options.alarmStatusValidators.store(nodeID, httpResponse.Header, alarmStatus)
+
+	return alarmStatus, nil
+}
+
+// readBackAlarmStatus reads the alarm status of the node until it reflects the
+// write, the PAS API might not have applied the write to the read model yet.
+func (c *Client) readBackAlarmStatus(
+	ctx context.Context,
+	nodeID uuid.UUID,
+	reflectsWrite func(models.AlarmStatus) bool,
+) (models.AlarmStatus, error) {
+	for attempt := 1; ; attempt++ {
+		alarmStatus, err := c.GetAlarmStatus(ctx, nodeID)
+		if err != nil {
+			return models.AlarmStatus{}, ReadBackError{NodeID: nodeID, Err: err}
+		}
+
+		if reflectsWrite(alarmStatus) {
+			return alarmStatus, nil
+		}
+
+		if attempt == readBackAttempts {
+			return models.AlarmStatus{}, ReadBackError{NodeID: nodeID, Err: ErrNotReadBack}
+		}
+
+		if !sleep(ctx, readBackDelay) {
+			return models.AlarmStatus{}, ReadBackError{NodeID: nodeID, Err: ctx.Err()}
+		}
+	}
+}
+
+// reflectsMeasurement reports whether the alarm status is the outcome of the
+// measurement, or of a write made after the alarm status was last updated
+// before the write. Without a measurement any alarm status reflects it.
+func reflectsMeasurement(alarmStatus models.AlarmStatus, measurement *models.Measurement, updatedBefore time.Time) bool {
+	if measurement == nil {
+		return true
+	}
+
+	triggered := func(status *models.GenericAlarmStatus) bool {
+		return status != nil && strings.EqualFold(string(status.TriggeringMeasurement), string(measurement.MeasurementID))
+	}
+
+	if triggered(alarmStatus.Overall) || triggered(alarmStatus.RateOfChange) || triggered(alarmStatus.Inspection) {
+		return true
+	}
+
+	for i := range alarmStatus.Band {
+		if triggered(&alarmStatus.Band[i].GenericAlarmStatus) {
+			return true
+		}
+	}
+
+	for i := range alarmStatus.HAL {
+		if triggered(&alarmStatus.HAL[i].GenericAlarmStatus) {
+			return true
+		}
+	}
+
+	return alarmStatus.UpdatedAt.After(updatedBefore)
+}
+
+func reflectsExternalStatus(alarmStatus models.AlarmStatus, status models.ExternalAlarmStatus) bool {
+	external := alarmStatus.External
+	if external == nil || external.Status != status.Status {
+		return false
+	}
+
+	if status.SetBy == nil {
+		return true
+	}
+
+	return external.SetBy != nil && strings.EqualFold(string(*status.SetBy), string(*external.SetBy))
+}
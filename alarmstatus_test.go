@@ -0,0 +1,251 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SKF/go-pas-client/models"
+	rest "github.com/SKF/go-rest-utility/client"
+	"github.com/SKF/go-rest-utility/problems"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+// alarmStatusServer responds to writes with the write body, and to reads with
+// the read body, or not found if the read body is empty. It counts the reads.
+func alarmStatusServer(t *testing.T, writeBody, readBody string) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var reads int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := writeBody
+
+		if r.Method == http.MethodGet {
+			atomic.AddInt32(&reads, 1)
+
+			if readBody == "" {
+				w.Header().Set("Content-Type", problems.ContentType)
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{}`))
+
+				return
+			}
+
+			body = readBody
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(body))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, &reads
+}
+
+func Test_UpdateAlarmStatusAndGet(t *testing.T) {
+	t.Parallel()
+
+	const (
+		nodeID = uuid.UUID("7e0b2d1c-0a33-4fe4-4d5b-0e9fd2a493c4")
+		status = `{"nodeId": "7e0b2d1c-0a33-4fe4-4d5b-0e9fd2a493c4", "status": 4}`
+	)
+
+	tests := []struct {
+		name          string
+		writeBody     string
+		readBody      string
+		expectedReads int32
+	}{
+		{name: "from response", writeBody: status, readBody: "", expectedReads: 0},
+		{name: "empty response", writeBody: "", readBody: status, expectedReads: 1},
+		{name: "response without alarm status", writeBody: `{}`, readBody: status, expectedReads: 1},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			server, reads := alarmStatusServer(t, test.writeBody, test.readBody)
+			client := New(rest.WithBaseURL(server.URL))
+
+			alarmStatus, err := client.UpdateAlarmStatusAndGet(context.TODO(), nodeID, nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, nodeID, alarmStatus.NodeID)
+			assert.Equal(t, models.AlarmStatusDanger, alarmStatus.Status)
+			assert.Equal(t, test.expectedReads, atomic.LoadInt32(reads))
+		})
+	}
+}
+
+func Test_UpdateAlarmStatusAndGet_WriteFails(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"nodeId": "7e0b2d1c-0a33-4fe4-4d5b-0e9fd2a493c4", "status": 1}`))
+
+			return
+		}
+
+		w.Header().Set("Content-Type", problems.ContentType)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := New(rest.WithBaseURL(server.URL))
+
+	_, err := client.UpdateAlarmStatusAndGet(context.TODO(), uuid.EmptyUUID, &models.Measurement{
+		MeasurementID: uuid.New(),
+		ContentType:   models.ContentTypeDataPoint,
+	})
+
+	var readBackErr ReadBackError
+
+	assert.False(t, errors.As(err, &readBackErr), "the write failed")
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.ErrorContains(t, err, "request failed")
+}
+
+func Test_SetExternalAlarmStatusAndGet_ReadBackFails(t *testing.T) {
+	t.Parallel()
+
+	server, _ := alarmStatusServer(t, "", "")
+	client := New(rest.WithBaseURL(server.URL))
+
+	_, err := client.SetExternalAlarmStatusAndGet(context.TODO(), uuid.EmptyUUID, models.ExternalAlarmStatus{
+		Status: models.AlarmStatusDanger,
+		SetBy:  nil,
+	})
+
+	var readBackErr ReadBackError
+
+	require.True(t, errors.As(err, &readBackErr), "the write succeeded")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func Test_UpdateAlarmStatusAndGet_ReadBack(t *testing.T) {
+	t.Parallel()
+
+	const (
+		nodeID        = uuid.UUID("7e0b2d1c-0a33-4fe4-4d5b-0e9fd2a493c4")
+		measurementID = uuid.UUID("a5c1f0e2-3b4d-4c6e-8f70-9182a3b4c5d6")
+		previous      = `{"nodeId": "7e0b2d1c-0a33-4fe4-4d5b-0e9fd2a493c4", "status": 1, "updatedAt": 1000}`
+		triggered     = `{"nodeId": "7e0b2d1c-0a33-4fe4-4d5b-0e9fd2a493c4", "status": 4, "updatedAt": 1000,
+			"overallAlarm": {"status": 4, "triggeringMeasurement": "a5c1f0e2-3b4d-4c6e-8f70-9182a3b4c5d6"}}`
+		updated = `{"nodeId": "7e0b2d1c-0a33-4fe4-4d5b-0e9fd2a493c4", "status": 2, "updatedAt": 3000}`
+	)
+
+	// The measurement is backfilled, created before the alarm status was
+	// last updated.
+	measurement := &models.Measurement{
+		MeasurementID: measurementID,
+		CreatedAt:     time.UnixMilli(500),
+		ContentType:   models.ContentTypeDataPoint,
+	}
+
+	tests := []struct {
+		name           string
+		reads          []string
+		expectedStatus models.AlarmStatusType
+		expectedErr    error
+	}{
+		{name: "triggered by the measurement", reads: []string{previous, triggered}, expectedStatus: models.AlarmStatusDanger},
+		{name: "updated after the write", reads: []string{previous, updated}, expectedStatus: models.AlarmStatusGood},
+		{name: "backfilled and never reflected", reads: []string{previous}, expectedErr: ErrNotReadBack},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var reads int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					w.WriteHeader(http.StatusOK)
+
+					return
+				}
+
+				read := int(atomic.AddInt32(&reads, 1)) - 1
+				if read >= len(test.reads) {
+					read = len(test.reads) - 1
+				}
+
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(test.reads[read]))
+			}))
+			t.Cleanup(server.Close)
+
+			client := New(rest.WithBaseURL(server.URL))
+
+			alarmStatus, err := client.UpdateAlarmStatusAndGet(context.TODO(), nodeID, measurement)
+			if test.expectedErr != nil {
+				var readBackErr ReadBackError
+
+				require.True(t, errors.As(err, &readBackErr), "the write succeeded")
+				assert.ErrorIs(t, err, test.expectedErr)
+				assert.Equal(t, int32(1+readBackAttempts), atomic.LoadInt32(&reads), "read before the write and back")
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedStatus, alarmStatus.Status)
+		})
+	}
+}
+
+func Test_SetExternalAlarmStatusAndGet_ReadBack(t *testing.T) {
+	t.Parallel()
+
+	const (
+		previous = `{"nodeId": "7e0b2d1c-0a33-4fe4-4d5b-0e9fd2a493c4", "status": 1}`
+		external = `{"nodeId": "7e0b2d1c-0a33-4fe4-4d5b-0e9fd2a493c4", "status": 4, "externalAlarm": {"status": 4}}`
+	)
+
+	var reads int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := previous
+
+		if r.Method == http.MethodGet && atomic.AddInt32(&reads, 1) > 1 {
+			body = external
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		if r.Method == http.MethodGet {
+			w.Write([]byte(body))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := New(rest.WithBaseURL(server.URL))
+
+	alarmStatus, err := client.SetExternalAlarmStatusAndGet(context.TODO(), uuid.EmptyUUID, models.ExternalAlarmStatus{
+		Status: models.AlarmStatusDanger,
+		SetBy:  nil,
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, alarmStatus.External)
+	assert.Equal(t, models.AlarmStatusDanger, alarmStatus.External.Status)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&reads))
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+// GetThresholds fetches the thresholds of all nodes concurrently. Nodes which
+// failed are left out of the thresholds and have their error set in errs
+// instead, a failure for one node does not affect the others.
+func (c *Client) GetThresholds(
+	ctx context.Context,
+	nodeIDs []uuid.UUID,
+) (thresholds map[uuid.UUID]models.Threshold, errs map[uuid.UUID]error) {
+	return fanOut(ctx, c.options.batchConcurrency, nodeIDs, c.GetThreshold)
+}
+
+// GetAlarmStatuses fetches the alarm statuses of all nodes concurrently. Nodes
+// which failed are left out of the alarm statuses and have their error set in
+// errs instead, a failure for one node does not affect the others.
+func (c *Client) GetAlarmStatuses(
+	ctx context.Context,
+	nodeIDs []uuid.UUID,
+) (alarmStatuses map[uuid.UUID]models.AlarmStatus, errs map[uuid.UUID]error) {
+	return fanOut(ctx, c.options.batchConcurrency, nodeIDs, c.GetAlarmStatus)
+}
+
+func fanOut[T any](
+	ctx context.Context,
+	concurrency int,
+	nodeIDs []uuid.UUID,
+	get func(context.Context, uuid.UUID) (T, error),
+) (map[uuid.UUID]T, map[uuid.UUID]error) {
+	var (
+		lock      sync.Mutex
+		wg        sync.WaitGroup
+		semaphore = make(chan struct{}, concurrency)
+		results   = make(map[uuid.UUID]T, len(nodeIDs))
+		errs      = make(map[uuid.UUID]error)
+		seen      = make(map[uuid.UUID]struct{}, len(nodeIDs))
+	)
+
+	for _, nodeID := range nodeIDs {
+		if _, duplicate := seen[nodeID]; duplicate {
+			continue
+		}
+
+		seen[nodeID] = struct{}{}
+
+		select {
+		case <-ctx.Done():
+			lock.Lock()
+			errs[nodeID] = ctx.Err()
+			lock.Unlock()
+
+			continue
+		case semaphore <- struct{}{}:
+		}
+
+		wg.Add(1)
+
+		go func(nodeID uuid.UUID) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			result, err := get(ctx, nodeID)
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			if err != nil {
+				errs[nodeID] = err
+
+				return
+			}
+
+			results[nodeID] = result
+		}(nodeID)
+	}
+
+	wg.Wait()
+
+	return results, errs
+}
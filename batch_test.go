@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	rest "github.com/SKF/go-rest-utility/client"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+func Test_GetThresholds(t *testing.T) {
+	t.Parallel()
+
+	var (
+		found   = uuid.New()
+		missing = uuid.New()
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, missing.String()) {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		w.Write([]byte(`{"nodeId": "` + found.String() + `", "thresholdType": 1}`))
+	}))
+	defer server.Close()
+
+	client := New(rest.WithBaseURL(server.URL))
+
+	thresholds, errs := client.GetThresholds(context.TODO(), []uuid.UUID{found, missing, found})
+
+	require.Len(t, thresholds, 1)
+	assert.Equal(t, found, thresholds[found].NodeID)
+
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[missing], ErrNotFound)
+}
+
+func Test_GetAlarmStatuses_Concurrency(t *testing.T) {
+	t.Parallel()
+
+	var inFlight, maxInFlight int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			previous := atomic.LoadInt32(&maxInFlight)
+			if current <= previous || atomic.CompareAndSwapInt32(&maxInFlight, previous, current) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+
+		w.WriteHeader(http.StatusOK)
+
+		w.Write([]byte(`{"alarmStatus": 2}`))
+	}))
+	defer server.Close()
+
+	client := NewWithOptions([]rest.Option{rest.WithBaseURL(server.URL)}, WithBatchConcurrency(2))
+
+	nodeIDs := make([]uuid.UUID, 8)
+	for i := range nodeIDs {
+		nodeIDs[i] = uuid.New()
+	}
+
+	alarmStatuses, errs := client.GetAlarmStatuses(context.TODO(), nodeIDs)
+
+	assert.Empty(t, errs)
+	assert.Len(t, alarmStatuses, len(nodeIDs))
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+}
+
+func Test_GetAlarmStatuses_Canceled(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := New(rest.WithBaseURL(server.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	nodeIDs := []uuid.UUID{uuid.New(), uuid.New()}
+
+	alarmStatuses, errs := client.GetAlarmStatuses(ctx, nodeIDs)
+
+	assert.Empty(t, alarmStatuses)
+	require.Len(t, errs, len(nodeIDs))
+
+	for _, nodeID := range nodeIDs {
+		assert.ErrorIs(t, errs[nodeID], context.Canceled)
+	}
+}
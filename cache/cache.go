@@ -0,0 +1,386 @@
+// Package cache provides a read-through cache of thresholds and alarm
+// statuses in front of the client.
+package cache
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	pas "github.com/SKF/go-pas-client"
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+const (
+	defaultTTL          = 5 * time.Minute
+	defaultMaxEntries   = 10000
+	defaultFetchTimeout = 30 * time.Second
+)
+
+type Option func(*config)
+
+type config struct {
+	ttl          time.Duration
+	maxEntries   int
+	fetchTimeout time.Duration
+}
+
+// WithTTL sets how long values are cached, defaults to 5 minutes.
+func WithTTL(ttl time.Duration) Option {
+	return func(c *config) {
+		c.ttl = ttl
+	}
+}
+
+// WithMaxEntries limits the number of thresholds, and separately the number
+// of alarm statuses, which are cached. The least recently used values are
+// evicted first. Defaults to 10000.
+func WithMaxEntries(n int) Option {
+	return func(c *config) {
+		c.maxEntries = n
+	}
+}
+
+// WithFetchTimeout limits how long a fetch shared by concurrent misses may
+// take, defaults to 30 seconds.
+func WithFetchTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.fetchTimeout = timeout
+	}
+}
+
+// Client is the client the cache reads through and writes through, it's
+// implemented by pas.Client.
+type Client interface {
+	pas.API
+
+	GetThresholds(context.Context, []uuid.UUID) (map[uuid.UUID]models.Threshold, map[uuid.UUID]error)
+	GetAlarmStatuses(context.Context, []uuid.UUID) (map[uuid.UUID]models.AlarmStatus, map[uuid.UUID]error)
+	SetThresholdIfMatch(context.Context, uuid.UUID, models.Threshold, string) error
+	PatchThresholdIfMatch(context.Context, uuid.UUID, models.Patch, string) (models.Threshold, error)
+	UpdateThreshold(context.Context, uuid.UUID, func(*models.Threshold) error) (models.Threshold, error)
+	UpdateAlarmStatusAndGet(context.Context, uuid.UUID, *models.Measurement) (models.AlarmStatus, error)
+	SetExternalAlarmStatusAndGet(context.Context, uuid.UUID, models.ExternalAlarmStatus) (models.AlarmStatus, error)
+	WatchAlarmStatus(context.Context, []uuid.UUID, time.Duration) <-chan pas.AlarmStatusChange
+}
+
+var _ Client = (*pas.Client)(nil)
+
+// Cache is safe for concurrent use. Concurrent misses for the same node are
+// coalesced into a single request, which isn't canceled with the context of
+// any caller but is limited by the fetch timeout. Each caller stops waiting
+// for the request once its own context is done.
+// Writes through the cache invalidate the cached value of the node, and
+// events published by other writers can be fed to the cache using
+// HandleThreshold and HandleAlarmStatus. Failures are not cached.
+type Cache struct {
+	api           Client
+	thresholds    *lru[models.Threshold]
+	alarmStatuses *lru[models.AlarmStatus]
+	group         singleflight.Group
+	fetchTimeout  time.Duration
+}
+
+var _ Client = &Cache{api: nil, thresholds: nil, alarmStatuses: nil, group: singleflight.Group{}, fetchTimeout: 0}
+
+func New(api Client, opts ...Option) *Cache {
+	c := config{
+		ttl:          defaultTTL,
+		maxEntries:   defaultMaxEntries,
+		fetchTimeout: defaultFetchTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return &Cache{
+		api:           api,
+		thresholds:    newLRU(c.ttl, c.maxEntries, models.Threshold.Clone, thresholdUpdatedAt),
+		alarmStatuses: newLRU(c.ttl, c.maxEntries, models.AlarmStatus.Clone, alarmStatusUpdatedAt),
+		group:         singleflight.Group{},
+		fetchTimeout:  c.fetchTimeout,
+	}
+}
+
+func (c *Cache) GetThreshold(ctx context.Context, nodeID uuid.UUID) (models.Threshold, error) {
+	return get(ctx, c, c.thresholds, "threshold/", nodeID, c.api.GetThreshold)
+}
+
+func (c *Cache) GetAlarmStatus(ctx context.Context, nodeID uuid.UUID) (models.AlarmStatus, error) {
+	return get(ctx, c, c.alarmStatuses, "alarm-status/", nodeID, c.api.GetAlarmStatus)
+}
+
+// GetThresholds returns the cached thresholds and fetches the rest in a
+// single batch.
+func (c *Cache) GetThresholds(
+	ctx context.Context,
+	nodeIDs []uuid.UUID,
+) (map[uuid.UUID]models.Threshold, map[uuid.UUID]error) {
+	return getBatch(ctx, c.thresholds, nodeIDs, c.api.GetThresholds)
+}
+
+// GetAlarmStatuses returns the cached alarm statuses and fetches the rest in
+// a single batch.
+func (c *Cache) GetAlarmStatuses(
+	ctx context.Context,
+	nodeIDs []uuid.UUID,
+) (map[uuid.UUID]models.AlarmStatus, map[uuid.UUID]error) {
+	return getBatch(ctx, c.alarmStatuses, nodeIDs, c.api.GetAlarmStatuses)
+}
+
+func (c *Cache) SetThreshold(ctx context.Context, nodeID uuid.UUID, threshold models.Threshold) error {
+	defer c.thresholds.invalidate(nodeID)
+
+	return c.api.SetThreshold(ctx, nodeID, threshold)
+}
+
+func (c *Cache) PatchThreshold(ctx context.Context, nodeID uuid.UUID, patch models.Patch) (models.Threshold, error) {
+	defer c.thresholds.invalidate(nodeID)
+
+	return c.api.PatchThreshold(ctx, nodeID, patch)
+}
+
+func (c *Cache) SetThresholdIfMatch(
+	ctx context.Context,
+	nodeID uuid.UUID,
+	threshold models.Threshold,
+	etag string,
+) error {
+	defer c.thresholds.invalidate(nodeID)
+
+	return c.api.SetThresholdIfMatch(ctx, nodeID, threshold, etag)
+}
+
+func (c *Cache) PatchThresholdIfMatch(
+	ctx context.Context,
+	nodeID uuid.UUID,
+	patch models.Patch,
+	etag string,
+) (models.Threshold, error) {
+	defer c.thresholds.invalidate(nodeID)
+
+	return c.api.PatchThresholdIfMatch(ctx, nodeID, patch, etag)
+}
+
+func (c *Cache) UpdateThreshold(
+	ctx context.Context,
+	nodeID uuid.UUID,
+	mutate func(*models.Threshold) error,
+) (models.Threshold, error) {
+	defer c.thresholds.invalidate(nodeID)
+
+	return c.api.UpdateThreshold(ctx, nodeID, mutate)
+}
+
+func (c *Cache) SetExternalAlarmStatus(
+	ctx context.Context,
+	nodeID uuid.UUID,
+	status models.ExternalAlarmStatus,
+) error {
+	defer c.alarmStatuses.invalidate(nodeID)
+
+	return c.api.SetExternalAlarmStatus(ctx, nodeID, status)
+}
+
+func (c *Cache) UpdateAlarmStatus(ctx context.Context, nodeID uuid.UUID, measurement *models.Measurement) error {
+	defer c.alarmStatuses.invalidate(nodeID)
+
+	return c.api.UpdateAlarmStatus(ctx, nodeID, measurement)
+}
+
+func (c *Cache) UpdateAlarmStatusAndGet(
+	ctx context.Context,
+	nodeID uuid.UUID,
+	measurement *models.Measurement,
+) (models.AlarmStatus, error) {
+	defer c.alarmStatuses.invalidate(nodeID)
+
+	return c.api.UpdateAlarmStatusAndGet(ctx, nodeID, measurement)
+}
+
+func (c *Cache) SetExternalAlarmStatusAndGet(
+	ctx context.Context,
+	nodeID uuid.UUID,
+	status models.ExternalAlarmStatus,
+) (models.AlarmStatus, error) {
+	defer c.alarmStatuses.invalidate(nodeID)
+
+	return c.api.SetExternalAlarmStatusAndGet(ctx, nodeID, status)
+}
+
+// WatchAlarmStatus is passed through without caching.
+func (c *Cache) WatchAlarmStatus(
+	ctx context.Context,
+	nodeIDs []uuid.UUID,
+	interval time.Duration,
+) <-chan pas.AlarmStatusChange {
+	return c.api.WatchAlarmStatus(ctx, nodeIDs, interval)
+}
+
+// InvalidateThreshold removes the cached threshold of the node.
+func (c *Cache) InvalidateThreshold(nodeID uuid.UUID) {
+	c.thresholds.invalidate(nodeID)
+}
+
+// InvalidateAlarmStatus removes the cached alarm status of the node.
+func (c *Cache) InvalidateAlarmStatus(nodeID uuid.UUID) {
+	c.alarmStatuses.invalidate(nodeID)
+}
+
+// ApplyThreshold caches the threshold of the event and reports whether it was
+// applied, events older than an already applied event are ignored.
+func (c *Cache) ApplyThreshold(event models.ThresholdEvent) bool {
+	threshold := event.Threshold
+	threshold.NodeID = event.AggregateID
+
+	return c.thresholds.apply(event.AggregateID, threshold, models.Version{
+		SequenceID: event.SequenceID,
+		Timestamp:  event.Timestamp,
+	})
+}
+
+// ApplyAlarmStatus caches the alarm status of the event and reports whether
+// it was applied, events older than an already applied event are ignored.
+func (c *Cache) ApplyAlarmStatus(event models.AlarmStatusEvent) bool {
+	alarmStatus := event.AlarmStatus
+	alarmStatus.NodeID = event.AggregateID
+
+	return c.alarmStatuses.apply(event.AggregateID, alarmStatus, models.Version{
+		SequenceID: event.SequenceID,
+		Timestamp:  event.Timestamp,
+	})
+}
+
+// HandleThreshold applies the event, it can be registered as a handler using
+// events.Router.OnThreshold.
+func (c *Cache) HandleThreshold(_ context.Context, event models.ThresholdEvent) error {
+	c.ApplyThreshold(event)
+
+	return nil
+}
+
+// HandleAlarmStatus applies the event, it can be registered as a handler
+// using events.Router.OnAlarmStatus.
+func (c *Cache) HandleAlarmStatus(_ context.Context, event models.AlarmStatusEvent) error {
+	c.ApplyAlarmStatus(event)
+
+	return nil
+}
+
+// thresholdUpdatedAt returns the zero time, as thresholds read from the PAS
+// API carry no update time, only an ETag which isn't ordered.
+func thresholdUpdatedAt(models.Threshold) time.Time {
+	return time.Time{}
+}
+
+func alarmStatusUpdatedAt(alarmStatus models.AlarmStatus) time.Time {
+	return alarmStatus.UpdatedAt
+}
+
+func get[T any](
+	ctx context.Context,
+	c *Cache,
+	values *lru[T],
+	prefix string,
+	nodeID uuid.UUID,
+	fetch func(context.Context, uuid.UUID) (T, error),
+) (T, error) {
+	if value, found := values.get(nodeID); found {
+		return value, nil
+	}
+
+	fetched := c.group.DoChan(prefix+nodeID.String(), func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(detachedContext{parent: ctx}, c.fetchTimeout)
+		defer cancel()
+
+		token := values.startFetch(nodeID)
+
+		value, err := fetch(fetchCtx, nodeID)
+
+		values.finishFetch(nodeID, token, value, err == nil)
+
+		return value, err
+	})
+
+	var zero T
+
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case result := <-fetched:
+		if result.Err != nil {
+			return zero, result.Err
+		}
+
+		// The value is shared by all callers waiting for the fetch.
+		return values.clone(result.Val.(T)), nil
+	}
+}
+
+// detachedContext keeps the values of its parent, e.g. for tracing, but not
+// its deadline or cancellation.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (d detachedContext) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
+
+func getBatch[T any](
+	ctx context.Context,
+	values *lru[T],
+	nodeIDs []uuid.UUID,
+	fetch func(context.Context, []uuid.UUID) (map[uuid.UUID]T, map[uuid.UUID]error),
+) (map[uuid.UUID]T, map[uuid.UUID]error) {
+	var (
+		found  = make(map[uuid.UUID]T, len(nodeIDs))
+		misses = make([]uuid.UUID, 0, len(nodeIDs))
+	)
+
+	for _, nodeID := range nodeIDs {
+		if value, hit := values.get(nodeID); hit {
+			found[nodeID] = value
+		} else {
+			misses = append(misses, nodeID)
+		}
+	}
+
+	if len(misses) == 0 {
+		return found, map[uuid.UUID]error{}
+	}
+
+	tokens := make(map[uuid.UUID]fetchToken, len(misses))
+
+	for _, nodeID := range misses {
+		tokens[nodeID] = values.startFetch(nodeID)
+	}
+
+	fetched, errs := fetch(ctx, misses)
+
+	for _, nodeID := range misses {
+		value, ok := fetched[nodeID]
+		values.finishFetch(nodeID, tokens[nodeID], value, ok)
+
+		if ok {
+			found[nodeID] = value
+		}
+	}
+
+	return found, errs
+}
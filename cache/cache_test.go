@@ -0,0 +1,433 @@
+package cache_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pas "github.com/SKF/go-pas-client"
+	"github.com/SKF/go-pas-client/cache"
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-pas-client/pasmock"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+var (
+	nodeA = uuid.UUID("a0000000-0000-0000-0000-000000000000")
+	nodeB = uuid.UUID("b0000000-0000-0000-0000-000000000000")
+)
+
+func threshold(nodeID uuid.UUID, thresholdType models.ThresholdType) models.Threshold {
+	return models.Threshold{
+		NodeID:        nodeID,
+		ThresholdType: thresholdType,
+		BandAlarms:    []models.BandAlarm{},
+		HALAlarms:     []models.HALAlarm{},
+	}
+}
+
+// blockingAPI blocks GetThreshold until released, counting the calls.
+type blockingAPI struct {
+	*pasmock.Client
+
+	calls   int32
+	release chan struct{}
+}
+
+func (b *blockingAPI) GetThreshold(ctx context.Context, nodeID uuid.UUID) (models.Threshold, error) {
+	atomic.AddInt32(&b.calls, 1)
+
+	select {
+	case <-ctx.Done():
+		return models.Threshold{}, ctx.Err()
+	case <-b.release:
+	}
+
+	return b.Client.GetThreshold(ctx, nodeID)
+}
+
+func Test_Cache_GetThreshold(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx  = context.Background()
+		mock = pasmock.New().QueueGetThreshold(threshold(nodeA, models.ThresholdTypeOverallInWindow), nil)
+		c    = cache.New(mock)
+	)
+
+	first, err := c.GetThreshold(ctx, nodeA)
+	require.NoError(t, err)
+
+	first.ThresholdType = models.ThresholdTypeNone
+
+	second, err := c.GetThreshold(ctx, nodeA)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.ThresholdTypeOverallInWindow, second.ThresholdType, "cached values are copied")
+	mock.AssertNumberOfCalls(t, pasmock.MethodGetThreshold, 1)
+}
+
+func Test_Cache_FailuresAreNotCached(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx  = context.Background()
+		mock = pasmock.New().
+			QueueGetAlarmStatus(models.AlarmStatus{}, pas.ErrServer).
+			QueueGetAlarmStatus(models.AlarmStatus{Status: models.AlarmStatusGood}, nil)
+		c = cache.New(mock)
+	)
+
+	_, err := c.GetAlarmStatus(ctx, nodeA)
+	assert.ErrorIs(t, err, pas.ErrServer)
+
+	alarmStatus, err := c.GetAlarmStatus(ctx, nodeA)
+	require.NoError(t, err)
+	assert.Equal(t, models.AlarmStatusGood, alarmStatus.Status)
+
+	_, err = c.GetAlarmStatus(ctx, nodeA)
+	require.NoError(t, err)
+
+	mock.AssertNumberOfCalls(t, pasmock.MethodGetAlarmStatus, 2)
+}
+
+func Test_Cache_TTL(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx  = context.Background()
+		mock = pasmock.New().
+			QueueGetThreshold(threshold(nodeA, models.ThresholdTypeOverallInWindow), nil).
+			QueueGetThreshold(threshold(nodeA, models.ThresholdTypeOverallOutOfWindow), nil)
+		c = cache.New(mock, cache.WithTTL(20*time.Millisecond))
+	)
+
+	_, err := c.GetThreshold(ctx, nodeA)
+	require.NoError(t, err)
+
+	time.Sleep(30 * time.Millisecond)
+
+	current, err := c.GetThreshold(ctx, nodeA)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.ThresholdTypeOverallOutOfWindow, current.ThresholdType)
+	mock.AssertNumberOfCalls(t, pasmock.MethodGetThreshold, 2)
+}
+
+func Test_Cache_MaxEntries(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx  = context.Background()
+		mock = pasmock.New()
+		c    = cache.New(mock, cache.WithMaxEntries(1))
+	)
+
+	for _, nodeID := range []uuid.UUID{nodeA, nodeB, nodeA} {
+		mock.QueueGetThreshold(threshold(nodeID, models.ThresholdTypeOverallInWindow), nil)
+
+		_, err := c.GetThreshold(ctx, nodeID)
+		require.NoError(t, err)
+	}
+
+	mock.AssertNumberOfCalls(t, pasmock.MethodGetThreshold, 3)
+}
+
+func Test_Cache_InvalidatesOnWrite(t *testing.T) {
+	t.Parallel()
+
+	writes := map[string]func(context.Context, *pasmock.Client, *cache.Cache) error{
+		"set": func(ctx context.Context, mock *pasmock.Client, c *cache.Cache) error {
+			mock.QueueSetThreshold(nil)
+
+			return c.SetThreshold(ctx, nodeA, threshold(nodeA, models.ThresholdTypeNone))
+		},
+		"patch": func(ctx context.Context, mock *pasmock.Client, c *cache.Cache) error {
+			mock.QueuePatchThreshold(threshold(nodeA, models.ThresholdTypeNone), nil)
+
+			_, err := c.PatchThreshold(ctx, nodeA, models.Patch{})
+
+			return err
+		},
+		"set if match": func(ctx context.Context, mock *pasmock.Client, c *cache.Cache) error {
+			mock.QueueSetThresholdIfMatch(nil)
+
+			return c.SetThresholdIfMatch(ctx, nodeA, threshold(nodeA, models.ThresholdTypeNone), `"v1"`)
+		},
+		"patch if match": func(ctx context.Context, mock *pasmock.Client, c *cache.Cache) error {
+			mock.QueuePatchThresholdIfMatch(threshold(nodeA, models.ThresholdTypeNone), nil)
+
+			_, err := c.PatchThresholdIfMatch(ctx, nodeA, models.Patch{}, `"v1"`)
+
+			return err
+		},
+	}
+
+	for name, write := range writes {
+		write := write
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				ctx  = context.Background()
+				mock = pasmock.New().QueueGetThreshold(threshold(nodeA, models.ThresholdTypeOverallInWindow), nil)
+				c    = cache.New(mock)
+			)
+
+			_, err := c.GetThreshold(ctx, nodeA)
+			require.NoError(t, err)
+
+			require.NoError(t, write(ctx, mock, c))
+
+			mock.QueueGetThreshold(threshold(nodeA, models.ThresholdTypeNone), nil)
+
+			current, err := c.GetThreshold(ctx, nodeA)
+			require.NoError(t, err)
+
+			assert.Equal(t, models.ThresholdTypeNone, current.ThresholdType)
+			mock.AssertNumberOfCalls(t, pasmock.MethodGetThreshold, 2)
+		})
+	}
+}
+
+func Test_Cache_CoalescesMisses(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx = context.Background()
+		api = &blockingAPI{
+			Client:  pasmock.New().QueueGetThreshold(threshold(nodeA, models.ThresholdTypeOverallInWindow), nil),
+			release: make(chan struct{}),
+		}
+		c  = cache.New(api)
+		wg sync.WaitGroup
+	)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			current, err := c.GetThreshold(ctx, nodeA)
+			assert.NoError(t, err)
+			assert.Equal(t, models.ThresholdTypeOverallInWindow, current.ThresholdType)
+		}()
+	}
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&api.calls) == 1
+	}, time.Second, time.Millisecond)
+
+	// give the other goroutines a chance to join the pending fetch
+	time.Sleep(10 * time.Millisecond)
+	close(api.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&api.calls))
+}
+
+func Test_Cache_CoalescedMissOutlivesFirstCaller(t *testing.T) {
+	t.Parallel()
+
+	var (
+		api = &blockingAPI{
+			Client:  pasmock.New().QueueGetThreshold(threshold(nodeA, models.ThresholdTypeOverallInWindow), nil),
+			release: make(chan struct{}),
+		}
+		c           = cache.New(api)
+		ctx, cancel = context.WithCancel(context.Background())
+		first       = make(chan error)
+	)
+
+	go func() {
+		_, err := c.GetThreshold(ctx, nodeA)
+		first <- err
+	}()
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&api.calls) == 1
+	}, time.Second, time.Millisecond)
+
+	second := make(chan models.Threshold)
+
+	go func() {
+		current, err := c.GetThreshold(context.Background(), nodeA)
+		assert.NoError(t, err)
+		second <- current
+	}()
+
+	cancel()
+	assert.ErrorIs(t, <-first, context.Canceled)
+
+	// give the second goroutine a chance to join the pending fetch
+	time.Sleep(10 * time.Millisecond)
+	close(api.release)
+	assert.Equal(t, models.ThresholdTypeOverallInWindow, (<-second).ThresholdType)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&api.calls))
+}
+
+func Test_Cache_Events(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx  = context.Background()
+		mock = pasmock.New()
+		c    = cache.New(mock)
+	)
+
+	event := func(sequenceID string, thresholdType models.ThresholdType) models.ThresholdEvent {
+		return models.ThresholdEvent{
+			AggregateID: nodeA,
+			SequenceID:  sequenceID,
+			Threshold:   threshold(nodeA, thresholdType),
+		}
+	}
+
+	require.NoError(t, c.HandleThreshold(ctx, event("02", models.ThresholdTypeOverallInWindow)))
+	assert.False(t, c.ApplyThreshold(event("01", models.ThresholdTypeNone)), "older events are ignored")
+
+	current, err := c.GetThreshold(ctx, nodeA)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.ThresholdTypeOverallInWindow, current.ThresholdType)
+	mock.AssertNotCalled(t, pasmock.MethodGetThreshold, nodeA)
+
+	c.InvalidateThreshold(nodeA)
+	mock.QueueGetThreshold(threshold(nodeA, models.ThresholdTypeNone), nil)
+
+	current, err = c.GetThreshold(ctx, nodeA)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.ThresholdTypeNone, current.ThresholdType)
+}
+
+func Test_Cache_EventDuringFetch(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx = context.Background()
+		api = &blockingAPI{
+			Client:  pasmock.New().QueueGetThreshold(threshold(nodeA, models.ThresholdTypeOverallInWindow), nil),
+			release: make(chan struct{}),
+		}
+		c    = cache.New(api)
+		done = make(chan struct{})
+	)
+
+	go func() {
+		defer close(done)
+
+		_, err := c.GetThreshold(ctx, nodeA)
+		assert.NoError(t, err)
+	}()
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&api.calls) == 1
+	}, time.Second, time.Millisecond)
+
+	c.ApplyThreshold(models.ThresholdEvent{
+		AggregateID: nodeA,
+		SequenceID:  "01",
+		Threshold:   threshold(nodeA, models.ThresholdTypeOverallOutOfWindow),
+	})
+
+	close(api.release)
+	<-done
+
+	current, err := c.GetThreshold(ctx, nodeA)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.ThresholdTypeOverallOutOfWindow, current.ThresholdType, "the fetched value is outdated")
+}
+
+func Test_Cache_EventOlderThanFetched(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx       = context.Background()
+		updatedAt = time.Now().Add(-time.Hour).UTC()
+		mock      = pasmock.New().
+				QueueGetThreshold(threshold(nodeA, models.ThresholdTypeOverallInWindow), nil).
+				QueueGetAlarmStatus(models.AlarmStatus{NodeID: nodeA, Status: models.AlarmStatusAlert, UpdatedAt: updatedAt}, nil)
+		c = cache.New(mock)
+	)
+
+	_, err := c.GetThreshold(ctx, nodeA)
+	require.NoError(t, err)
+
+	_, err = c.GetAlarmStatus(ctx, nodeA)
+	require.NoError(t, err)
+
+	thresholdEvent := func(timestamp time.Time) models.ThresholdEvent {
+		return models.ThresholdEvent{
+			AggregateID: nodeA,
+			SequenceID:  "01",
+			Timestamp:   timestamp,
+			Threshold:   threshold(nodeA, models.ThresholdTypeNone),
+		}
+	}
+
+	alarmStatusEvent := func(timestamp time.Time) models.AlarmStatusEvent {
+		return models.AlarmStatusEvent{
+			AggregateID: nodeA,
+			SequenceID:  "01",
+			Timestamp:   timestamp,
+			AlarmStatus: models.AlarmStatus{NodeID: nodeA, Status: models.AlarmStatusGood, UpdatedAt: timestamp},
+		}
+	}
+
+	assert.False(t, c.ApplyThreshold(thresholdEvent(time.Now().Add(-time.Minute))), "published before the fetch")
+	assert.False(t, c.ApplyAlarmStatus(alarmStatusEvent(updatedAt.Add(-time.Minute))), "published before the update")
+	assert.False(t, c.ApplyAlarmStatus(alarmStatusEvent(updatedAt)), "the fetched update")
+
+	current, err := c.GetAlarmStatus(ctx, nodeA)
+	require.NoError(t, err)
+	assert.Equal(t, models.AlarmStatusAlert, current.Status)
+
+	assert.True(t, c.ApplyThreshold(thresholdEvent(time.Now().Add(time.Minute))))
+	assert.True(t, c.ApplyAlarmStatus(alarmStatusEvent(updatedAt.Add(time.Minute))))
+
+	current, err = c.GetAlarmStatus(ctx, nodeA)
+	require.NoError(t, err)
+	assert.Equal(t, models.AlarmStatusGood, current.Status)
+
+	mock.AssertNumberOfCalls(t, pasmock.MethodGetAlarmStatus, 1)
+}
+
+func Test_Cache_GetAlarmStatuses(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx  = context.Background()
+		mock = pasmock.New().
+			QueueGetAlarmStatus(models.AlarmStatus{Status: models.AlarmStatusGood}, nil).
+			QueueGetAlarmStatuses(map[uuid.UUID]models.AlarmStatus{
+				nodeB: {Status: models.AlarmStatusDanger},
+			}, map[uuid.UUID]error{})
+		c = cache.New(mock)
+	)
+
+	_, err := c.GetAlarmStatus(ctx, nodeA)
+	require.NoError(t, err)
+
+	alarmStatuses, errs := c.GetAlarmStatuses(ctx, []uuid.UUID{nodeA, nodeB})
+	assert.Empty(t, errs)
+
+	assert.Equal(t, models.AlarmStatusGood, alarmStatuses[nodeA].Status)
+	assert.Equal(t, models.AlarmStatusDanger, alarmStatuses[nodeB].Status)
+
+	calls := mock.CallsTo(pasmock.MethodGetAlarmStatuses)
+	require.Len(t, calls, 1)
+	assert.Equal(t, []uuid.UUID{nodeB}, calls[0].NodeIDs)
+
+	alarmStatuses, _ = c.GetAlarmStatuses(ctx, []uuid.UUID{nodeA, nodeB})
+	assert.Len(t, alarmStatuses, 2)
+	mock.AssertNumberOfCalls(t, pasmock.MethodGetAlarmStatuses, 1)
+}
@@ -0,0 +1,195 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+type item[T any] struct {
+	nodeID  uuid.UUID
+	value   T
+	version models.Version
+	expires time.Time
+}
+
+// lru holds the values of one kind, e.g. thresholds, evicting the least
+// recently used value once full.
+type lru[T any] struct {
+	mutex      sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	clone      func(T) T
+	updatedAt  func(T) time.Time
+	items      map[uuid.UUID]*list.Element
+	order      *list.List
+
+	// epoch is incremented whenever a value is invalidated or applied, and
+	// fetching holds the nodes being fetched. A fetched value is only stored
+	// if its node hasn't been invalidated since the fetch started, as it may
+	// be outdated otherwise.
+	epoch    uint64
+	fetching map[uuid.UUID]*fetchState
+}
+
+type fetchState struct {
+	active        int
+	invalidatedAt uint64
+}
+
+// newLRU creates an lru for values of one kind, updatedAt returns when a
+// value was last updated by the PAS service or the zero time if unknown.
+func newLRU[T any](ttl time.Duration, maxEntries int, clone func(T) T, updatedAt func(T) time.Time) *lru[T] {
+	return &lru[T]{
+		mutex:      sync.Mutex{},
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		clone:      clone,
+		updatedAt:  updatedAt,
+		items:      map[uuid.UUID]*list.Element{},
+		order:      list.New(),
+		epoch:      0,
+		fetching:   map[uuid.UUID]*fetchState{},
+	}
+}
+
+func (l *lru[T]) get(nodeID uuid.UUID) (T, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var zero T
+
+	element, found := l.items[nodeID]
+	if !found {
+		return zero, false
+	}
+
+	it := element.Value.(*item[T])
+
+	if time.Now().After(it.expires) {
+		l.removeElement(element)
+
+		return zero, false
+	}
+
+	l.order.MoveToFront(element)
+
+	return l.clone(it.value), true
+}
+
+// startFetch must be called before fetching a node, the fetched value is
+// then stored using finishFetch with the returned token.
+func (l *lru[T]) startFetch(nodeID uuid.UUID) fetchToken {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	state, found := l.fetching[nodeID]
+	if !found {
+		state = &fetchState{active: 0, invalidatedAt: 0}
+		l.fetching[nodeID] = state
+	}
+
+	state.active++
+
+	return fetchToken{epoch: l.epoch, startedAt: time.Now()}
+}
+
+// fetchToken is returned by startFetch.
+type fetchToken struct {
+	epoch     uint64
+	startedAt time.Time
+}
+
+// finishFetch stores the fetched value, versioned by when it was last updated
+// or otherwise by when the fetch started, as every event published before
+// then is reflected by the value. Events which aren't later than the value
+// are then refused by apply.
+func (l *lru[T]) finishFetch(nodeID uuid.UUID, token fetchToken, value T, fetched bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	state := l.fetching[nodeID]
+	invalidated := state.invalidatedAt > token.epoch
+
+	if state.active--; state.active == 0 {
+		delete(l.fetching, nodeID)
+	}
+
+	if fetched && !invalidated {
+		v := models.Version{SequenceID: "", Timestamp: token.startedAt}
+
+		if updatedAt := l.updatedAt(value); !updatedAt.IsZero() {
+			v.Timestamp = updatedAt
+		}
+
+		l.put(nodeID, value, v)
+	}
+}
+
+// apply stores a value received in an event, unless a later event has
+// already been applied.
+func (l *lru[T]) apply(nodeID uuid.UUID, value T, v models.Version) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if element, found := l.items[nodeID]; found {
+		current := element.Value.(*item[T])
+		if !current.version.IsZero() && !v.After(current.version) {
+			return false
+		}
+	}
+
+	l.markInvalidated(nodeID)
+	l.put(nodeID, value, v)
+
+	return true
+}
+
+func (l *lru[T]) invalidate(nodeID uuid.UUID) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.markInvalidated(nodeID)
+
+	if element, found := l.items[nodeID]; found {
+		l.removeElement(element)
+	}
+}
+
+func (l *lru[T]) markInvalidated(nodeID uuid.UUID) {
+	l.epoch++
+
+	if state, found := l.fetching[nodeID]; found {
+		state.invalidatedAt = l.epoch
+	}
+}
+
+func (l *lru[T]) put(nodeID uuid.UUID, value T, v models.Version) {
+	it := &item[T]{
+		nodeID:  nodeID,
+		value:   l.clone(value),
+		version: v,
+		expires: time.Now().Add(l.ttl),
+	}
+
+	if element, found := l.items[nodeID]; found {
+		element.Value = it
+		l.order.MoveToFront(element)
+
+		return
+	}
+
+	l.items[nodeID] = l.order.PushFront(it)
+
+	for l.maxEntries > 0 && l.order.Len() > l.maxEntries {
+		l.removeElement(l.order.Back())
+	}
+}
+
+func (l *lru[T]) removeElement(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.items, element.Value.(*item[T]).nodeID)
+}
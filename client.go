@@ -3,8 +3,12 @@ package client
 import (
 	"context"
 	"fmt"
+	"net/http"
+
+	"github.com/wI2L/jsondiff"
 
 	internal_models "github.com/SKF/go-pas-client/internal/models"
+	"github.com/SKF/go-pas-client/internal/responses"
 	"github.com/SKF/go-pas-client/models"
 	rest "github.com/SKF/go-rest-utility/client"
 	"github.com/SKF/go-utility/v2/stages"
@@ -22,9 +26,11 @@ type API interface {
 
 type Client struct {
 	*rest.Client
+
+	options options
 }
 
-var _ API = &Client{Client: nil}
+var _ API = &Client{Client: nil, options: options{}}
 
 func WithStage(stage string) rest.Option {
 	if stage == stages.StageProd {
@@ -35,85 +41,236 @@ func WithStage(stage string) rest.Option {
 }
 
 func New(opts ...rest.Option) *Client {
+	return NewWithOptions(opts)
+}
+
+// NewWithOptions returns a client configured by the rest options, like New,
+// and by the client options, e.g. WithBatchConcurrency.
+func NewWithOptions(restOpts []rest.Option, opts ...ClientOption) *Client {
 	restClient := rest.NewClient(
 		append([]rest.Option{
 			// Defaults to production stage if no option is supplied
 			WithStage(stages.StageProd),
 			rest.WithProblemDecoder(&ProblemDecoder{}),
-		}, opts...)...,
+		}, restOpts...)...,
 	)
 
-	return &Client{restClient}
+	wrapTransport(restClient)
+
+	clientOpts := defaultOptions()
+
+	for _, opt := range opts {
+		opt(clientOpts)
+	}
+
+	return &Client{
+		Client:  restClient,
+		options: *clientOpts,
+	}
 }
 
 func (c *Client) GetThreshold(ctx context.Context, nodeID uuid.UUID) (models.Threshold, error) {
-	request := rest.Get("v1/point-alarm-threshold/{nodeId}").
-		Assign("nodeId", nodeID).
-		SetHeader("Accept", "application/json")
-
-	var response internal_models.ModelsGetPointAlarmThresholdResponse
+	previous, conditional := c.options.thresholdValidators.load(nodeID)
+
+	request := request{
+		route:   RouteThresholdRead,
+		method:  http.MethodGet,
+		guarded: false,
+		build: func() *rest.Request {
+			r := rest.Get("v1/point-alarm-threshold/{nodeId}").
+				Assign("nodeId", nodeID).
+				SetHeader("Accept", "application/json")
+
+			if conditional {
+				r = previous.condition(r)
+			}
+
+			return r
+		},
+	}
 
-	if err := c.DoAndUnmarshal(ctx, request, &response); err != nil {
+	httpResponse, err := c.do(ctx, request)
+	if err != nil {
 		return models.Threshold{}, fmt.Errorf("getting threshold failed: %w", err)
 	}
 
-	threshold := models.Threshold{} //nolint:exhaustruct
+	if conditional && httpResponse.StatusCode == http.StatusNotModified {
+		closeBody(httpResponse)
+
+		return previous.value, nil
+	}
+
+	var response responses.Threshold
+
+	if err = unmarshal(httpResponse, &response); err != nil {
+		return models.Threshold{}, fmt.Errorf("getting threshold failed: %w", err)
+	}
 
-	if err := threshold.FromInternal(response); err != nil {
-		return models.Threshold{}, fmt.Errorf("converting threshold failed: %w", err)
+	threshold, err := response.ToThreshold()
+	if err != nil {
+		return models.Threshold{}, err
 	}
 
+	threshold.ETag = httpResponse.Header.Get("ETag")
+
+	c.options.thresholdValidators.store(nodeID, httpResponse.Header, threshold)
+
 	return threshold, nil
 }
 
 func (c *Client) SetThreshold(ctx context.Context, nodeID uuid.UUID, threshold models.Threshold) error {
-	request := rest.Put("v1/point-alarm-threshold/{nodeId}").
-		Assign("nodeId", nodeID).
-		WithJSONPayload(threshold.ToInternal()).
-		SetHeader("Accept", "application/json")
+	return c.setThreshold(ctx, nodeID, threshold, "")
+}
 
-	if _, err := c.Do(ctx, request); err != nil {
+// SetThresholdIfMatch sets the threshold like SetThreshold, but only if the
+// ETag of the current threshold matches, typically the ETag of the threshold
+// which was read before modifying it. Otherwise it fails with
+// ErrPreconditionFailed. An empty ETag makes the write unconditional.
+func (c *Client) SetThresholdIfMatch(
+	ctx context.Context,
+	nodeID uuid.UUID,
+	threshold models.Threshold,
+	etag string,
+) error {
+	return c.setThreshold(ctx, nodeID, threshold, etag)
+}
+
+func (c *Client) setThreshold(ctx context.Context, nodeID uuid.UUID, threshold models.Threshold, etag string) error {
+	payload := threshold.ToInternal()
+
+	request := request{
+		route:   RouteThresholdWrite,
+		method:  http.MethodPut,
+		guarded: false,
+		build: func() *rest.Request {
+			r := rest.Put("v1/point-alarm-threshold/{nodeId}").
+				Assign("nodeId", nodeID).
+				WithJSONPayload(payload).
+				SetHeader("Accept", "application/json")
+
+			if etag != "" {
+				r = r.SetHeader("If-Match", etag)
+			}
+
+			return r
+		},
+	}
+
+	httpResponse, err := c.do(ctx, request)
+	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
 	}
 
+	closeBody(httpResponse)
+
 	return nil
 }
 
 func (c *Client) PatchThreshold(ctx context.Context, nodeID uuid.UUID, patch models.Patch) (models.Threshold, error) {
-	request := rest.Patch("v1/point-alarm-threshold/{nodeId}").
-		Assign("nodeId", nodeID).
-		WithJSONPayload(patch).
-		SetHeader("Content-Type", "application/json-patch+json").
-		SetHeader("Accept", "application/json")
+	return c.patchThreshold(ctx, nodeID, patch, "")
+}
 
-	var response internal_models.ModelsGetPointAlarmThresholdResponse
+// PatchThresholdIfMatch patches the threshold like PatchThreshold, but only
+// if the ETag of the current threshold matches. Otherwise it fails with
+// ErrPreconditionFailed. An empty ETag makes the patch unconditional.
+func (c *Client) PatchThresholdIfMatch(
+	ctx context.Context,
+	nodeID uuid.UUID,
+	patch models.Patch,
+	etag string,
+) (models.Threshold, error) {
+	return c.patchThreshold(ctx, nodeID, patch, etag)
+}
+
+func (c *Client) patchThreshold(
+	ctx context.Context,
+	nodeID uuid.UUID,
+	patch models.Patch,
+	etag string,
+) (models.Threshold, error) {
+	request := request{
+		route:  RouteThresholdWrite,
+		method: http.MethodPatch,
+		// A patch beginning with a test operation, or conditional on the ETag,
+		// fails once it has been applied, which makes it safe to retry.
+		guarded: etag != "" || (len(patch) > 0 && patch[0].Type == jsondiff.OperationTest),
+		build: func() *rest.Request {
+			r := rest.Patch("v1/point-alarm-threshold/{nodeId}").
+				Assign("nodeId", nodeID).
+				WithJSONPayload(patch).
+				SetHeader("Content-Type", "application/json-patch+json").
+				SetHeader("Accept", "application/json")
+
+			if etag != "" {
+				r = r.SetHeader("If-Match", etag)
+			}
+
+			return r
+		},
+	}
 
-	if err := c.DoAndUnmarshal(ctx, request, &response); err != nil {
+	httpResponse, err := c.do(ctx, request)
+	if err != nil {
 		return models.Threshold{}, fmt.Errorf("patching threshold failed: %w", err)
 	}
 
-	threshold := models.Threshold{}
+	var response responses.Threshold
+
+	if err = unmarshal(httpResponse, &response); err != nil {
+		return models.Threshold{}, fmt.Errorf("patching threshold failed: %w", err)
+	}
 
-	if err := threshold.FromInternal(response); err != nil {
-		return models.Threshold{}, fmt.Errorf("converting threshold failed: %w", err)
+	threshold, err := response.ToThreshold()
+	if err != nil {
+		return models.Threshold{}, err
 	}
 
+	threshold.ETag = httpResponse.Header.Get("ETag")
+
 	return threshold, nil
 }
 
 func (c *Client) GetAlarmStatus(ctx context.Context, nodeID uuid.UUID) (alarmStatus models.AlarmStatus, err error) {
-	request := rest.Get("v1/alarm-status/{nodeId}").
-		Assign("nodeId", nodeID).
-		SetHeader("Accept", "application/json")
+	previous, conditional := c.options.alarmStatusValidators.load(nodeID)
+
+	request := request{
+		route:   RouteAlarmStatusRead,
+		method:  http.MethodGet,
+		guarded: false,
+		build: func() *rest.Request {
+			r := rest.Get("v1/alarm-status/{nodeId}").
+				Assign("nodeId", nodeID).
+				SetHeader("Accept", "application/json")
+
+			if conditional {
+				r = previous.condition(r)
+			}
+
+			return r
+		},
+	}
+
+	httpResponse, err := c.do(ctx, request)
+	if err != nil {
+		return models.AlarmStatus{}, fmt.Errorf("getting alarm status failed: %w", err)
+	}
+
+	if conditional && httpResponse.StatusCode == http.StatusNotModified {
+		closeBody(httpResponse)
+
+		return previous.value, nil
+	}
 
 	var response internal_models.ModelsGetAlarmStatusResponse
 
-	if err = c.DoAndUnmarshal(ctx, request, &response); err != nil {
+	if err = unmarshal(httpResponse, &response); err != nil {
 		return models.AlarmStatus{}, fmt.Errorf("getting alarm status failed: %w", err)
 	}
 
 	alarmStatus.FromInternal(response)
 
+	c.options.alarmStatusValidators.store(nodeID, httpResponse.Header, alarmStatus)
+
 	return
 }
 
@@ -122,15 +279,12 @@ func (c *Client) UpdateAlarmStatus(
 	nodeID uuid.UUID,
 	measurement *models.Measurement,
 ) (err error) {
-	request := rest.Put("v1/alarm-status/{nodeId}").
-		Assign("nodeId", nodeID).
-		SetHeader("Accept", "application/json")
-
-	if measurement != nil {
-		request = request.WithJSONPayload(measurement.ToInternal())
+	httpResponse, err := c.do(ctx, updateAlarmStatusRequest(nodeID, measurement))
+	if err != nil {
+		return
 	}
 
-	_, err = c.Do(ctx, request)
+	closeBody(httpResponse)
 
 	return
 }
@@ -140,14 +294,47 @@ func (c *Client) SetExternalAlarmStatus(
 	nodeID uuid.UUID,
 	status models.ExternalAlarmStatus,
 ) (err error) {
-	payload := status.ToSetRequest()
-
-	request := rest.Put("v1/alarm-status/{nodeId}/status/external").
-		Assign("nodeId", nodeID).
-		WithJSONPayload(payload).
-		SetHeader("Accept", "application/json")
+	httpResponse, err := c.do(ctx, setExternalAlarmStatusRequest(nodeID, status))
+	if err != nil {
+		return
+	}
 
-	_, err = c.Do(ctx, request)
+	closeBody(httpResponse)
 
 	return
 }
+
+func updateAlarmStatusRequest(nodeID uuid.UUID, measurement *models.Measurement) request {
+	return request{
+		route:   RouteMeasurement,
+		method:  http.MethodPut,
+		guarded: false,
+		build: func() *rest.Request {
+			r := rest.Put("v1/alarm-status/{nodeId}").
+				Assign("nodeId", nodeID).
+				SetHeader("Accept", "application/json")
+
+			if measurement != nil {
+				r = r.WithJSONPayload(measurement.ToInternal())
+			}
+
+			return r
+		},
+	}
+}
+
+func setExternalAlarmStatusRequest(nodeID uuid.UUID, status models.ExternalAlarmStatus) request {
+	payload := status.ToSetRequest()
+
+	return request{
+		route:   RouteExternalAlarmStatus,
+		method:  http.MethodPut,
+		guarded: false,
+		build: func() *rest.Request {
+			return rest.Put("v1/alarm-status/{nodeId}/status/external").
+				Assign("nodeId", nodeID).
+				WithJSONPayload(payload).
+				SetHeader("Accept", "application/json")
+		},
+	}
+}
@@ -48,6 +48,16 @@ func Test_BaseURL(t *testing.T) {
 	assert.Equal(t, "api.point-alarm-status.sandbox.iot.enlight.skf.com", c.Client.BaseURL.Host)
 }
 
+func Test_New_Options(t *testing.T) {
+	t.Parallel()
+
+	c := NewWithOptions([]rest.Option{WithStage("sandbox")}, WithBatchConcurrency(3))
+
+	require.NotNil(t, c.Client.BaseURL)
+	assert.Equal(t, "api.point-alarm-status.sandbox.iot.enlight.skf.com", c.Client.BaseURL.Host)
+	assert.Equal(t, 3, c.options.batchConcurrency)
+}
+
 func Test_GetThreshold(t *testing.T) {
 	t.Parallel()
 
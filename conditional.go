@@ -0,0 +1,136 @@
+package client
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+
+	"github.com/SKF/go-pas-client/models"
+	rest "github.com/SKF/go-rest-utility/client"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+// WithConditionalRequests makes GetThreshold and GetAlarmStatus remember the
+// ETag and Last-Modified of the last value read for each node, and send them
+// as If-None-Match and If-Modified-Since. The remembered value is returned if
+// the PAS API responds with 304 Not Modified, saving the transfer of the body.
+//
+// The values of at most maxEntries nodes are kept in memory, separately for
+// thresholds and alarm statuses, the least recently read are forgotten first.
+func WithConditionalRequests(maxEntries int) ClientOption {
+	return func(o *options) {
+		o.thresholdValidators = newValidatorCache(maxEntries, models.Threshold.Clone)
+		o.alarmStatusValidators = newValidatorCache(maxEntries, models.AlarmStatus.Clone)
+	}
+}
+
+// validated is a value together with the validators the PAS API responded
+// with when the value was read.
+type validated[T any] struct {
+	nodeID       uuid.UUID
+	etag         string
+	lastModified string
+	value        T
+}
+
+// condition makes the request conditional on the value having been modified.
+func (v validated[T]) condition(r *rest.Request) *rest.Request {
+	if v.etag != "" {
+		r = r.SetHeader("If-None-Match", v.etag)
+	}
+
+	if v.lastModified != "" {
+		r = r.SetHeader("If-Modified-Since", v.lastModified)
+	}
+
+	return r
+}
+
+type validatorCache[T any] struct {
+	mutex      sync.Mutex
+	maxEntries int
+	clone      func(T) T
+	values     map[uuid.UUID]*list.Element
+	order      *list.List
+}
+
+func newValidatorCache[T any](maxEntries int, clone func(T) T) *validatorCache[T] {
+	if maxEntries < 1 {
+		maxEntries = 1
+	}
+
+	return &validatorCache[T]{
+		mutex:      sync.Mutex{},
+		maxEntries: maxEntries,
+		clone:      clone,
+		values:     map[uuid.UUID]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+// load returns the last value read of the node, it's safe to call on a nil
+// cache, i.e. when conditional requests are disabled.
+func (c *validatorCache[T]) load(nodeID uuid.UUID) (validated[T], bool) {
+	if c == nil {
+		return validated[T]{}, false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, found := c.values[nodeID]
+	if !found {
+		return validated[T]{}, false
+	}
+
+	c.order.MoveToFront(element)
+
+	v := *element.Value.(*validated[T])
+	v.value = c.clone(v.value)
+
+	return v, true
+}
+
+// store remembers the value of the node, unless the PAS API didn't respond
+// with any validators.
+func (c *validatorCache[T]) store(nodeID uuid.UUID, header http.Header, value T) {
+	if c == nil {
+		return
+	}
+
+	v := &validated[T]{
+		nodeID:       nodeID,
+		etag:         header.Get("ETag"),
+		lastModified: header.Get("Last-Modified"),
+		value:        c.clone(value),
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, found := c.values[nodeID]
+
+	if v.etag == "" && v.lastModified == "" {
+		if found {
+			c.order.Remove(element)
+			delete(c.values, nodeID)
+		}
+
+		return
+	}
+
+	if found {
+		element.Value = v
+		c.order.MoveToFront(element)
+
+		return
+	}
+
+	c.values[nodeID] = c.order.PushFront(v)
+
+	if c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.values, oldest.Value.(*validated[T]).nodeID)
+	}
+}
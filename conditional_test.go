@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SKF/go-pas-client/models"
+	rest "github.com/SKF/go-rest-utility/client"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+// versionedServer serves a threshold with the ETag "v<version>", honoring
+// If-None-Match and If-Match, and counts the not modified responses.
+func versionedServer(t *testing.T, version *int32) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var notModified int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := fmt.Sprintf(`"v%d"`, atomic.LoadInt32(version))
+
+		switch {
+		case r.Method == http.MethodGet && r.Header.Get("If-None-Match") == etag:
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		case r.Method != http.MethodGet && r.Header.Get("If-Match") != "" && r.Header.Get("If-Match") != etag:
+			w.WriteHeader(http.StatusPreconditionFailed)
+
+			return
+		case r.Method != http.MethodGet:
+			atomic.AddInt32(version, 1)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{}`))
+
+			return
+		}
+
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"thresholdType": 1}`))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, &notModified
+}
+
+func Test_ConditionalRequests(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx                 = context.Background()
+		version             = int32(1)
+		server, notModified = versionedServer(t, &version)
+		client              = NewWithOptions([]rest.Option{rest.WithBaseURL(server.URL)}, WithConditionalRequests(10))
+	)
+
+	first, err := client.GetThreshold(ctx, uuid.EmptyUUID)
+	require.NoError(t, err)
+
+	assert.Equal(t, `"v1"`, first.ETag)
+	assert.Equal(t, models.ThresholdTypeOverallInWindow, first.ThresholdType)
+
+	second, err := client.GetThreshold(ctx, uuid.EmptyUUID)
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second, "the remembered threshold is returned when not modified")
+	assert.Equal(t, int32(1), atomic.LoadInt32(notModified))
+
+	atomic.StoreInt32(&version, 2)
+
+	third, err := client.GetThreshold(ctx, uuid.EmptyUUID)
+	require.NoError(t, err)
+
+	assert.Equal(t, `"v2"`, third.ETag)
+}
+
+func Test_ThresholdIfMatch(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx       = context.Background()
+		version   = int32(1)
+		server, _ = versionedServer(t, &version)
+		client    = New(rest.WithBaseURL(server.URL))
+	)
+
+	threshold, err := client.GetThreshold(ctx, uuid.EmptyUUID)
+	require.NoError(t, err)
+
+	require.NoError(t, client.SetThresholdIfMatch(ctx, uuid.EmptyUUID, threshold, threshold.ETag))
+
+	err = client.SetThresholdIfMatch(ctx, uuid.EmptyUUID, threshold, threshold.ETag)
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+
+	_, err = client.PatchThresholdIfMatch(ctx, uuid.EmptyUUID, models.Patch{}, threshold.ETag)
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+
+	require.NoError(t, client.SetThreshold(ctx, uuid.EmptyUUID, threshold), "writes are unconditional by default")
+}
+
+func Test_ValidatorCache_MaxEntries(t *testing.T) {
+	t.Parallel()
+
+	var (
+		cache  = newValidatorCache(2, models.Threshold.Clone)
+		header = http.Header{"Etag": []string{`"v1"`}}
+		nodeA  = uuid.New()
+		nodeB  = uuid.New()
+		nodeC  = uuid.New()
+	)
+
+	cache.store(nodeA, header, models.Threshold{})
+	cache.store(nodeB, header, models.Threshold{})
+
+	_, found := cache.load(nodeA)
+	require.True(t, found)
+
+	cache.store(nodeC, header, models.Threshold{})
+
+	_, found = cache.load(nodeB)
+	assert.False(t, found, "the least recently read node is forgotten")
+
+	_, found = cache.load(nodeA)
+	assert.True(t, found)
+
+	_, found = cache.load(nodeC)
+	assert.True(t, found)
+
+	cache.store(nodeC, http.Header{}, models.Threshold{})
+
+	_, found = cache.load(nodeC)
+	assert.False(t, found, "values without validators are forgotten")
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	rest "github.com/SKF/go-rest-utility/client"
+	"github.com/SKF/go-rest-utility/problems"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+// Sentinel errors which can be used together with errors.Is to check what
+// kind of error the PAS API responded with.
+var (
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrServer       = errors.New("server error")
+
+	// ErrPreconditionFailed is returned when a write made conditional on an
+	// ETag fails, as the threshold has been modified since it was read.
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// IsPermanent reports whether the PAS API refused the request in a way which
+// sending it again won't change, e.g. because it's invalid or the caller isn't
+// allowed to make it. The ingest and outbox packages don't retry such failures.
+func IsPermanent(err error) bool {
+	for _, permanent := range []error{
+		ErrValidation,
+		ErrUnauthorized,
+		ErrForbidden,
+		ErrNotFound,
+		ErrConflict,
+	} {
+		if errors.Is(err, permanent) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ProblemError is returned when the PAS API responds with a problem which is
+// neither a validation problem nor a server problem, e.g. a not found problem.
+// RetryAfter is set if the response carried a Retry-After header, e.g. when
+// being rate limited.
+type ProblemError struct {
+	problems.BasicProblem
+	RetryAfter time.Duration
+}
+
+func (e ProblemError) Unwrap() error {
+	return e.BasicProblem
+}
+
+func (e ProblemError) Is(target error) bool {
+	return isStatus(target, e.ProblemStatus())
+}
+
+// ValidationError is returned when the PAS API rejects a request as invalid
+// (400) or conflicting (409), the reasons are available in Reasons.
+type ValidationError struct {
+	problems.ValidationProblem
+}
+
+func (e ValidationError) Unwrap() error {
+	return e.ValidationProblem
+}
+
+func (e ValidationError) Is(target error) bool {
+	return isStatus(target, e.ProblemStatus())
+}
+
+// ServerError is returned when the PAS API fails to process a request, the
+// CorrelationID can be used to trace the failure in the service logs.
+// RetryAfter is set if the response carried a Retry-After header.
+type ServerError struct {
+	problems.BasicProblem
+	RetryAfter time.Duration
+}
+
+func (e ServerError) Unwrap() error {
+	return e.BasicProblem
+}
+
+func (e ServerError) Is(target error) bool {
+	return isStatus(target, e.ProblemStatus())
+}
+
+// ConflictError is returned by UpdateThreshold when the threshold kept being
+// modified concurrently and all retries have been exhausted, it wraps the
+// last conflict returned by the PAS API.
+type ConflictError struct {
+	NodeID   uuid.UUID
+	Attempts int
+	Err      error
+}
+
+func (e ConflictError) Error() string {
+	return fmt.Sprintf("updating threshold of node %s failed after %d attempts: %s", e.NodeID, e.Attempts, e.Err)
+}
+
+func (e ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// httpError is returned when the PAS API responds with an error status code
+// without a problem body.
+type httpError struct {
+	rest.HTTPError
+}
+
+func (e httpError) Unwrap() error {
+	return e.HTTPError
+}
+
+func (e httpError) Is(target error) bool {
+	return isStatus(target, e.StatusCode)
+}
+
+func translateError(err error) error {
+	var restErr rest.HTTPError
+
+	if errors.As(err, &restErr) {
+		return httpError{restErr}
+	}
+
+	return err
+}
+
+func isStatus(target error, status int) bool {
+	switch {
+	case status == http.StatusBadRequest, status == http.StatusUnprocessableEntity:
+		return target == ErrValidation
+	case status == http.StatusUnauthorized:
+		return target == ErrUnauthorized
+	case status == http.StatusForbidden:
+		return target == ErrForbidden
+	case status == http.StatusNotFound:
+		return target == ErrNotFound
+	case status == http.StatusConflict:
+		return target == ErrConflict
+	case status == http.StatusPreconditionFailed:
+		return target == ErrPreconditionFailed
+	case status >= http.StatusInternalServerError:
+		return target == ErrServer
+	default:
+		return false
+	}
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	rest "github.com/SKF/go-rest-utility/client"
+	"github.com/SKF/go-rest-utility/problems"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+func Test_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		status      int
+		contentType string
+		expected    error
+		permanent   bool
+	}{
+		{status: http.StatusNotFound, contentType: problems.ContentType, expected: ErrNotFound, permanent: true},
+		{status: http.StatusNotFound, contentType: "text/plain", expected: ErrNotFound, permanent: true},
+		{status: http.StatusConflict, contentType: problems.ContentType, expected: ErrConflict, permanent: true},
+		{status: http.StatusPreconditionFailed, contentType: problems.ContentType, expected: ErrPreconditionFailed},
+		{status: http.StatusPreconditionFailed, contentType: "text/plain", expected: ErrPreconditionFailed},
+		{status: http.StatusBadRequest, contentType: "text/plain", expected: ErrValidation, permanent: true},
+		{status: http.StatusForbidden, contentType: "text/plain", expected: ErrForbidden, permanent: true},
+		{status: http.StatusBadGateway, contentType: "text/plain", expected: ErrServer},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(http.StatusText(test.status), func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", test.contentType)
+				w.WriteHeader(test.status)
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			client := New(rest.WithBaseURL(server.URL))
+
+			_, err := client.GetThreshold(context.TODO(), uuid.EmptyUUID)
+
+			assert.ErrorIs(t, err, test.expected)
+			assert.Equal(t, test.permanent, IsPermanent(err))
+
+			if test.contentType != problems.ContentType {
+				assert.ErrorIs(t, err, rest.HTTPError{StatusCode: test.status})
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+// Package evaluate computes alarm statuses locally from a threshold and a
+// measurement, mirroring the evaluation done by the PAS service.
+package evaluate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/SKF/go-pas-client/models"
+)
+
+var (
+	ErrMissingFullScale          = errors.New("threshold is relative to full scale but no full scale is set")
+	ErrUnknownBandAlarmThreshold = errors.New("unknown band alarm threshold type")
+)
+
+const percent = 100
+
+// Option supplies values to Evaluate which are calculated from spectrum data
+// by the PAS service.
+type Option func(*options)
+
+type options struct {
+	bandOveralls map[string]float64
+	halIndexes   map[string]float64
+}
+
+// WithBandOveralls sets the calculated overall of each band, keyed by the
+// label of the band alarm.
+func WithBandOveralls(bandOveralls map[string]float64) Option {
+	return func(o *options) {
+		o.bandOveralls = bandOveralls
+	}
+}
+
+// WithHALIndexes sets the HAL index of each HAL alarm, keyed by the label of
+// the HAL alarm.
+func WithHALIndexes(halIndexes map[string]float64) Option {
+	return func(o *options) {
+		o.halIndexes = halIndexes
+	}
+}
+
+// Evaluate returns the alarm status the PAS service would compute when the
+// given measurement is applied to the given threshold.
+//
+// The measurement doesn't carry the spectrum itself, so the calculated
+// overalls of band alarms and the HAL indexes of HAL alarms are given with
+// WithBandOveralls and WithHALIndexes. Band and HAL alarms without a value get
+// the status AlarmStatusNoData.
+func Evaluate(threshold models.Threshold, measurement models.Measurement, opts ...Option) (models.AlarmStatus, error) {
+	o := &options{bandOveralls: nil, halIndexes: nil}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	status := models.AlarmStatus{ //nolint:exhaustruct
+		Status:    models.AlarmStatusNotConfigured,
+		UpdatedAt: measurement.CreatedAt,
+	}
+
+	if overall := threshold.Overall; overall != nil && isOverallThresholdType(threshold.ThresholdType) {
+		status.Overall = &models.GenericAlarmStatus{
+			TriggeringMeasurement: measurement.MeasurementID,
+			Status:                models.AlarmStatusNoData,
+		}
+
+		if measurement.DataPoint != nil {
+			status.Overall.Status = Overall(threshold.ThresholdType, *overall, measurement.DataPoint.Coordinate.Y)
+		}
+	}
+
+	if rateOfChange := threshold.RateOfChange; rateOfChange != nil {
+		status.RateOfChange = &models.GenericAlarmStatus{
+			TriggeringMeasurement: measurement.MeasurementID,
+			Status:                models.AlarmStatusNoData,
+		}
+
+		if measurement.RateOfChange != nil {
+			status.RateOfChange.Status = RateOfChange(*rateOfChange, *measurement.RateOfChange)
+		}
+	}
+
+	if inspection := threshold.Inspection; inspection != nil && threshold.ThresholdType == models.ThresholdTypeInspection {
+		status.Inspection = &models.GenericAlarmStatus{
+			TriggeringMeasurement: measurement.MeasurementID,
+			Status:                Inspection(*inspection, measurement.QuestionAnswers),
+		}
+	}
+
+	status.Band = make([]models.BandAlarmStatus, 0, len(threshold.BandAlarms))
+
+	for _, bandAlarm := range threshold.BandAlarms {
+		if bandAlarm.OverallThreshold == nil {
+			continue
+		}
+
+		bandStatus := models.BandAlarmStatus{ //nolint:exhaustruct
+			GenericAlarmStatus: models.GenericAlarmStatus{
+				TriggeringMeasurement: measurement.MeasurementID,
+				Status:                models.AlarmStatusNoData,
+			},
+			Label:        bandAlarm.Label,
+			MinFrequency: bandAlarm.MinFrequency,
+			MaxFrequency: bandAlarm.MaxFrequency,
+		}
+
+		if value, found := o.bandOveralls[bandAlarm.Label]; found {
+			bandAlarmStatus, err := BandAlarm(bandAlarm, threshold.FullScale, value)
+			if err != nil {
+				return models.AlarmStatus{}, fmt.Errorf("evaluating band alarm %q failed: %w", bandAlarm.Label, err)
+			}
+
+			bandStatus.Status = bandAlarmStatus
+			bandStatus.CalculatedOverall = &models.BandAlarmStatusCalculatedOverall{
+				Unit:  bandAlarm.OverallThreshold.Unit,
+				Value: value,
+			}
+		}
+
+		status.Band = append(status.Band, bandStatus)
+	}
+
+	status.HAL = make([]models.HALAlarmStatus, 0, len(threshold.HALAlarms))
+
+	for _, halAlarm := range threshold.HALAlarms {
+		halStatus := models.HALAlarmStatus{ //nolint:exhaustruct
+			GenericAlarmStatus: models.GenericAlarmStatus{
+				TriggeringMeasurement: measurement.MeasurementID,
+				Status:                models.AlarmStatusNoData,
+			},
+			Label: halAlarm.Label,
+		}
+
+		if halAlarm.Bearing != nil {
+			bearing := *halAlarm.Bearing
+			halStatus.Bearing = &bearing
+		}
+
+		if index, found := o.halIndexes[halAlarm.Label]; found {
+			halStatus.Status = HALAlarm(halAlarm, index)
+			halStatus.HALIndex = &index
+		}
+
+		status.HAL = append(status.HAL, halStatus)
+	}
+
+	status.Status = Worst(status)
+
+	return status, nil
+}
+
+// Worst returns the most severe status among all alarms in the alarm status,
+// or AlarmStatusNotConfigured if no alarm is present.
+func Worst(status models.AlarmStatus) models.AlarmStatusType {
+	worst := models.AlarmStatusNotConfigured
+
+	for _, generic := range []*models.GenericAlarmStatus{status.Overall, status.RateOfChange, status.Inspection} {
+		if generic != nil {
+			worst = mostSevere(worst, generic.Status)
+		}
+	}
+
+	if status.External != nil {
+		worst = mostSevere(worst, status.External.Status)
+	}
+
+	for _, band := range status.Band {
+		worst = mostSevere(worst, band.Status)
+	}
+
+	for _, hal := range status.HAL {
+		worst = mostSevere(worst, hal.Status)
+	}
+
+	return worst
+}
+
+// Overall evaluates an overall value against an in window or out of window
+// overall threshold.
+func Overall(thresholdType models.ThresholdType, overall models.Overall, value float64) models.AlarmStatusType {
+	if thresholdType == models.ThresholdTypeOverallInWindow {
+		return inWindow(value, overall.OuterHigh, overall.InnerHigh, overall.InnerLow, overall.OuterLow)
+	}
+
+	return outOfWindow(value, overall.OuterHigh, overall.InnerHigh, overall.InnerLow, overall.OuterLow)
+}
+
+// RateOfChange evaluates a rate of change value, rate of change thresholds
+// are always out of window.
+func RateOfChange(rateOfChange models.RateOfChange, value float64) models.AlarmStatusType {
+	return outOfWindow(value, rateOfChange.OuterHigh, rateOfChange.InnerHigh, rateOfChange.InnerLow, rateOfChange.OuterLow)
+}
+
+// Inspection returns the most severe status of the inspection choices
+// matching the given answers, or AlarmStatusNoData if no answer matches.
+func Inspection(inspection models.Inspection, answers []string) models.AlarmStatusType {
+	status := models.AlarmStatusNoData
+
+	for _, answer := range answers {
+		for _, choice := range inspection.Choices {
+			if choice.Answer == answer {
+				status = mostSevere(status, choice.Status)
+			}
+		}
+	}
+
+	return status
+}
+
+// BandAlarm evaluates the calculated overall of a band against the upper
+// alert and danger levels of the band. Levels relative to full scale are
+// interpreted as a percentage of the full scale of the threshold.
+func BandAlarm(bandAlarm models.BandAlarm, fullScale *float64, value float64) (models.AlarmStatusType, error) {
+	if bandAlarm.OverallThreshold == nil {
+		return models.AlarmStatusNotConfigured, nil
+	}
+
+	danger, err := resolveBandAlarmThreshold(bandAlarm.OverallThreshold.UpperDanger, fullScale)
+	if err != nil {
+		return models.AlarmStatusNotConfigured, fmt.Errorf("upper danger: %w", err)
+	}
+
+	alert, err := resolveBandAlarmThreshold(bandAlarm.OverallThreshold.UpperAlert, fullScale)
+	if err != nil {
+		return models.AlarmStatusNotConfigured, fmt.Errorf("upper alert: %w", err)
+	}
+
+	switch {
+	case atOrAbove(value, danger):
+		return models.AlarmStatusDanger, nil
+	case atOrAbove(value, alert):
+		return models.AlarmStatusAlert, nil
+	default:
+		return models.AlarmStatusGood, nil
+	}
+}
+
+// HALAlarm evaluates a HAL index against the upper alert and danger levels of
+// the HAL alarm.
+func HALAlarm(halAlarm models.HALAlarm, index float64) models.AlarmStatusType {
+	switch {
+	case atOrAbove(index, halAlarm.UpperDanger):
+		return models.AlarmStatusDanger
+	case atOrAbove(index, halAlarm.UpperAlert):
+		return models.AlarmStatusAlert
+	default:
+		return models.AlarmStatusGood
+	}
+}
+
+func resolveBandAlarmThreshold(threshold *models.BandAlarmThreshold, fullScale *float64) (*float64, error) {
+	if threshold == nil {
+		return nil, nil
+	}
+
+	switch threshold.ValueType {
+	case models.BandAlarmThresholdTypeAbsolute:
+		value := threshold.Value
+
+		return &value, nil
+	case models.BandAlarmThresholdTypeRelativeFullscale:
+		if fullScale == nil {
+			return nil, ErrMissingFullScale
+		}
+
+		value := threshold.Value / percent * *fullScale
+
+		return &value, nil
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnknownBandAlarmThreshold, threshold.ValueType)
+	}
+}
+
+func isOverallThresholdType(thresholdType models.ThresholdType) bool {
+	return thresholdType == models.ThresholdTypeOverallInWindow ||
+		thresholdType == models.ThresholdTypeOverallOutOfWindow
+}
+
+func outOfWindow(value float64, outerHigh, innerHigh, innerLow, outerLow *float64) models.AlarmStatusType {
+	switch {
+	case atOrAbove(value, outerHigh) || atOrBelow(value, outerLow):
+		return models.AlarmStatusDanger
+	case atOrAbove(value, innerHigh) || atOrBelow(value, innerLow):
+		return models.AlarmStatusAlert
+	default:
+		return models.AlarmStatusGood
+	}
+}
+
+func inWindow(value float64, outerHigh, innerHigh, innerLow, outerLow *float64) models.AlarmStatusType {
+	switch {
+	case within(value, innerLow, innerHigh):
+		return models.AlarmStatusDanger
+	case within(value, outerLow, outerHigh):
+		return models.AlarmStatusAlert
+	default:
+		return models.AlarmStatusGood
+	}
+}
+
+func within(value float64, low, high *float64) bool {
+	if low == nil && high == nil {
+		return false
+	}
+
+	return (low == nil || value >= *low) && (high == nil || value <= *high)
+}
+
+func atOrAbove(value float64, limit *float64) bool {
+	return limit != nil && value >= *limit
+}
+
+func atOrBelow(value float64, limit *float64) bool {
+	return limit != nil && value <= *limit
+}
+
+func mostSevere(a, b models.AlarmStatusType) models.AlarmStatusType {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,341 @@
+package evaluate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+func f64p(f float64) *float64 {
+	return &f
+}
+
+func Test_Overall(t *testing.T) {
+	t.Parallel()
+
+	overall := models.Overall{
+		Unit:      "C",
+		OuterHigh: f64p(70),
+		InnerHigh: f64p(50),
+		InnerLow:  f64p(20),
+		OuterLow:  f64p(10),
+	}
+
+	tests := []struct {
+		thresholdType models.ThresholdType
+		given         float64
+		expected      models.AlarmStatusType
+	}{
+		{models.ThresholdTypeOverallOutOfWindow, 80, models.AlarmStatusDanger},
+		{models.ThresholdTypeOverallOutOfWindow, 70, models.AlarmStatusDanger},
+		{models.ThresholdTypeOverallOutOfWindow, 60, models.AlarmStatusAlert},
+		{models.ThresholdTypeOverallOutOfWindow, 30, models.AlarmStatusGood},
+		{models.ThresholdTypeOverallOutOfWindow, 15, models.AlarmStatusAlert},
+		{models.ThresholdTypeOverallOutOfWindow, 5, models.AlarmStatusDanger},
+		{models.ThresholdTypeOverallInWindow, 80, models.AlarmStatusGood},
+		{models.ThresholdTypeOverallInWindow, 60, models.AlarmStatusAlert},
+		{models.ThresholdTypeOverallInWindow, 30, models.AlarmStatusDanger},
+		{models.ThresholdTypeOverallInWindow, 15, models.AlarmStatusAlert},
+		{models.ThresholdTypeOverallInWindow, 5, models.AlarmStatusGood},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run("", func(t *testing.T) {
+			actual := Overall(test.thresholdType, overall, test.given)
+
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func Test_Overall_PartialThreshold(t *testing.T) {
+	t.Parallel()
+
+	overall := models.Overall{
+		Unit:      "C",
+		OuterHigh: f64p(70),
+		InnerHigh: f64p(50),
+	}
+
+	assert.Equal(t, models.AlarmStatusGood, Overall(models.ThresholdTypeOverallOutOfWindow, overall, -100))
+	assert.Equal(t, models.AlarmStatusAlert, Overall(models.ThresholdTypeOverallOutOfWindow, overall, 60))
+}
+
+func Test_Inspection(t *testing.T) {
+	t.Parallel()
+
+	inspection := models.Inspection{
+		Choices: []models.InspectionChoice{
+			{Answer: "ok", Status: models.AlarmStatusGood},
+			{Answer: "noisy", Status: models.AlarmStatusAlert},
+			{Answer: "smoke", Status: models.AlarmStatusDanger},
+		},
+	}
+
+	assert.Equal(t, models.AlarmStatusNoData, Inspection(inspection, nil))
+	assert.Equal(t, models.AlarmStatusNoData, Inspection(inspection, []string{"unknown"}))
+	assert.Equal(t, models.AlarmStatusGood, Inspection(inspection, []string{"ok"}))
+	assert.Equal(t, models.AlarmStatusDanger, Inspection(inspection, []string{"noisy", "smoke"}))
+}
+
+func Test_BandAlarm(t *testing.T) {
+	t.Parallel()
+
+	bandAlarm := models.BandAlarm{
+		Label: "BPFO",
+		OverallThreshold: &models.BandAlarmOverallThreshold{
+			Unit: "gE",
+			UpperAlert: &models.BandAlarmThreshold{
+				ValueType: models.BandAlarmThresholdTypeRelativeFullscale,
+				Value:     50,
+			},
+			UpperDanger: &models.BandAlarmThreshold{
+				ValueType: models.BandAlarmThresholdTypeAbsolute,
+				Value:     8,
+			},
+		},
+	}
+
+	tests := []struct {
+		given    float64
+		expected models.AlarmStatusType
+	}{
+		{given: 1, expected: models.AlarmStatusGood},
+		{given: 5, expected: models.AlarmStatusAlert},
+		{given: 9, expected: models.AlarmStatusDanger},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run("", func(t *testing.T) {
+			actual, err := BandAlarm(bandAlarm, f64p(10), test.given)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func Test_BandAlarm_MissingFullScale(t *testing.T) {
+	t.Parallel()
+
+	bandAlarm := models.BandAlarm{
+		Label: "BPFO",
+		OverallThreshold: &models.BandAlarmOverallThreshold{
+			UpperAlert: &models.BandAlarmThreshold{
+				ValueType: models.BandAlarmThresholdTypeRelativeFullscale,
+				Value:     50,
+			},
+		},
+	}
+
+	_, err := BandAlarm(bandAlarm, nil, 1)
+
+	assert.ErrorIs(t, err, ErrMissingFullScale)
+}
+
+func Test_Evaluate(t *testing.T) {
+	t.Parallel()
+
+	var (
+		measurementID = uuid.UUID("2c6a5d2b-9d32-4dd9-9b8e-4b5e0a3c2d11")
+		createdAt     = time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+	)
+
+	threshold := models.Threshold{
+		ThresholdType: models.ThresholdTypeOverallOutOfWindow,
+		Overall: &models.Overall{
+			Unit:      "C",
+			OuterHigh: f64p(70),
+			InnerHigh: f64p(50),
+		},
+		RateOfChange: &models.RateOfChange{
+			Unit:      "C",
+			OuterHigh: f64p(10),
+		},
+		BandAlarms: []models.BandAlarm{
+			{
+				Label: "BPFO",
+				OverallThreshold: &models.BandAlarmOverallThreshold{
+					Unit: "gE",
+					UpperAlert: &models.BandAlarmThreshold{
+						ValueType: models.BandAlarmThresholdTypeAbsolute,
+						Value:     1,
+					},
+				},
+			},
+			{
+				Label: "BPFI",
+				OverallThreshold: &models.BandAlarmOverallThreshold{
+					Unit: "gE",
+					UpperAlert: &models.BandAlarmThreshold{
+						ValueType: models.BandAlarmThresholdTypeAbsolute,
+						Value:     1,
+					},
+				},
+			},
+		},
+	}
+
+	measurement := models.Measurement{
+		MeasurementID: measurementID,
+		CreatedAt:     createdAt,
+		ContentType:   models.ContentTypeDataPoint,
+		DataPoint: &models.DataPoint{
+			Coordinate: models.Coordinate{X: float64(createdAt.UnixMilli()), Y: 60},
+			XUnit:      "ms",
+			YUnit:      "C",
+		},
+	}
+
+	actual, err := Evaluate(threshold, measurement, WithBandOveralls(map[string]float64{"BPFO": 0.5}))
+	require.NoError(t, err)
+
+	expected := models.AlarmStatus{
+		Status:    models.AlarmStatusAlert,
+		UpdatedAt: createdAt,
+		Overall: &models.GenericAlarmStatus{
+			TriggeringMeasurement: measurementID,
+			Status:                models.AlarmStatusAlert,
+		},
+		RateOfChange: &models.GenericAlarmStatus{
+			TriggeringMeasurement: measurementID,
+			Status:                models.AlarmStatusNoData,
+		},
+		Band: []models.BandAlarmStatus{
+			{
+				GenericAlarmStatus: models.GenericAlarmStatus{
+					TriggeringMeasurement: measurementID,
+					Status:                models.AlarmStatusGood,
+				},
+				Label: "BPFO",
+				CalculatedOverall: &models.BandAlarmStatusCalculatedOverall{
+					Unit:  "gE",
+					Value: 0.5,
+				},
+			},
+			{
+				GenericAlarmStatus: models.GenericAlarmStatus{
+					TriggeringMeasurement: measurementID,
+					Status:                models.AlarmStatusNoData,
+				},
+				Label: "BPFI",
+			},
+		},
+		HAL: []models.HALAlarmStatus{},
+	}
+
+	assert.Equal(t, expected, actual)
+}
+
+func Test_Evaluate_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	actual, err := Evaluate(models.Threshold{}, models.Measurement{})
+	require.NoError(t, err)
+
+	assert.Equal(t, models.AlarmStatusNotConfigured, actual.Status)
+}
+
+func Test_Evaluate_Inspection(t *testing.T) {
+	t.Parallel()
+
+	threshold := models.Threshold{
+		ThresholdType: models.ThresholdTypeInspection,
+		Inspection: &models.Inspection{
+			Choices: []models.InspectionChoice{
+				{Answer: "ok", Status: models.AlarmStatusGood},
+				{Answer: "smoke", Status: models.AlarmStatusDanger},
+			},
+		},
+	}
+
+	measurement := models.Measurement{
+		ContentType:     models.ContentTypeQuestionAnswers,
+		QuestionAnswers: []string{"smoke"},
+	}
+
+	actual, err := Evaluate(threshold, measurement)
+	require.NoError(t, err)
+
+	require.NotNil(t, actual.Inspection)
+	assert.Equal(t, models.AlarmStatusDanger, actual.Inspection.Status)
+	assert.Equal(t, models.AlarmStatusDanger, actual.Status)
+}
+
+func Test_HALAlarm(t *testing.T) {
+	t.Parallel()
+
+	halAlarm := models.HALAlarm{
+		Label:        "outer ring",
+		HALAlarmType: models.HALAlarmTypeGlobal,
+		UpperAlert:   f64p(1),
+		UpperDanger:  f64p(2),
+	}
+
+	assert.Equal(t, models.AlarmStatusGood, HALAlarm(halAlarm, 0.5))
+	assert.Equal(t, models.AlarmStatusAlert, HALAlarm(halAlarm, 1))
+	assert.Equal(t, models.AlarmStatusDanger, HALAlarm(halAlarm, 2.5))
+}
+
+func Test_Evaluate_HALAlarms(t *testing.T) {
+	t.Parallel()
+
+	measurementID := uuid.UUID("2c6a5d2b-9d32-4dd9-9b8e-4b5e0a3c2d11")
+
+	threshold := models.Threshold{
+		ThresholdType: models.ThresholdTypeNone,
+		HALAlarms: []models.HALAlarm{
+			{
+				Label:        "outer ring",
+				Bearing:      &models.Bearing{Manufacturer: "SKF", ModelNumber: "6205"},
+				HALAlarmType: models.HALAlarmTypeFaultFrequency,
+				UpperAlert:   f64p(1),
+				UpperDanger:  f64p(2),
+			},
+			{
+				Label:        "global",
+				HALAlarmType: models.HALAlarmTypeGlobal,
+				UpperAlert:   f64p(1),
+			},
+		},
+	}
+
+	measurement := models.Measurement{
+		MeasurementID: measurementID,
+		ContentType:   models.ContentTypeSpectrum,
+	}
+
+	actual, err := Evaluate(threshold, measurement, WithHALIndexes(map[string]float64{"outer ring": 2.5}))
+	require.NoError(t, err)
+
+	expected := []models.HALAlarmStatus{
+		{
+			GenericAlarmStatus: models.GenericAlarmStatus{
+				TriggeringMeasurement: measurementID,
+				Status:                models.AlarmStatusDanger,
+			},
+			Label:    "outer ring",
+			Bearing:  &models.Bearing{Manufacturer: "SKF", ModelNumber: "6205"},
+			HALIndex: f64p(2.5),
+		},
+		{
+			GenericAlarmStatus: models.GenericAlarmStatus{
+				TriggeringMeasurement: measurementID,
+				Status:                models.AlarmStatusNoData,
+			},
+			Label: "global",
+		},
+	}
+
+	assert.Equal(t, expected, actual.HAL)
+	assert.Equal(t, models.AlarmStatusDanger, actual.Status)
+}
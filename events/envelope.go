@@ -0,0 +1,75 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/SKF/go-pas-client/models"
+)
+
+type (
+	// snsNotification is the envelope of a message published on an SNS topic,
+	// as received by an SNS subscriber or an SQS queue without raw delivery.
+	snsNotification struct {
+		Message           string                  `json:"Message"`
+		MessageAttributes map[string]snsAttribute `json:"MessageAttributes"`
+	}
+
+	snsAttribute struct {
+		Value string `json:"Value"`
+	}
+
+	// sqsMessage is a message received from an SQS queue, as delivered to a
+	// Lambda function.
+	sqsMessage struct {
+		Body              string                  `json:"body"`
+		MessageAttributes map[string]sqsAttribute `json:"messageAttributes"`
+	}
+
+	sqsAttribute struct {
+		StringValue *string `json:"stringValue"`
+	}
+)
+
+// RouteSNS routes an event wrapped in an SNS notification.
+func (r *Router) RouteSNS(ctx context.Context, notification []byte) error {
+	var envelope snsNotification
+
+	if err := json.Unmarshal(notification, &envelope); err != nil {
+		return fmt.Errorf("%w: SNS notification: %v", ErrDecodingFailed, err)
+	}
+
+	attributes := make(map[string]string, len(envelope.MessageAttributes))
+
+	for name, attribute := range envelope.MessageAttributes {
+		attributes[name] = attribute.Value
+	}
+
+	return r.Route(ctx, []byte(envelope.Message), attributes)
+}
+
+// RouteSQS routes an event received from an SQS queue. Both queues with raw
+// message delivery enabled and queues receiving SNS notifications are
+// supported.
+func (r *Router) RouteSQS(ctx context.Context, message []byte) error {
+	var envelope sqsMessage
+
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		return fmt.Errorf("%w: SQS message: %v", ErrDecodingFailed, err)
+	}
+
+	attributes := make(map[string]string, len(envelope.MessageAttributes))
+
+	for name, attribute := range envelope.MessageAttributes {
+		if attribute.StringValue != nil {
+			attributes[name] = *attribute.StringValue
+		}
+	}
+
+	if _, found := attributes[models.EventAttributeEventType]; !found {
+		return r.RouteSNS(ctx, []byte(envelope.Body))
+	}
+
+	return r.Route(ctx, []byte(envelope.Body), attributes)
+}
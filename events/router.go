@@ -0,0 +1,202 @@
+// Package events routes events published by the PAS service to typed
+// handlers.
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/SKF/go-eventsource/v2/eventsource"
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+var (
+	ErrUnknownEventType  = errors.New("unknown event type")
+	ErrDecodingFailed    = errors.New("decoding event failed")
+	ErrAggregateMismatch = errors.New("aggregate attribute doesn't match the event")
+)
+
+type (
+	ThresholdHandler   func(context.Context, models.ThresholdEvent) error
+	AlarmStatusHandler func(context.Context, models.AlarmStatusEvent) error
+
+	// UnknownHandler is invoked for events of any other type than the ones
+	// supported by the router.
+	UnknownHandler func(ctx context.Context, eventType string, data []byte, attributes map[string]string) error
+)
+
+// Errors is returned by the router when one or more handlers failed, it
+// matches any of the contained errors using errors.Is and errors.As.
+type Errors []error
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e Errors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Router decodes events and invokes the handlers registered for their type.
+// Events of unknown types are passed to the unknown handler, if no unknown
+// handler is registered ErrUnknownEventType is returned.
+type Router struct {
+	thresholdHandlers   []ThresholdHandler
+	alarmStatusHandlers []AlarmStatusHandler
+	unknownHandler      UnknownHandler
+}
+
+func NewRouter() *Router {
+	return &Router{
+		thresholdHandlers:   []ThresholdHandler{},
+		alarmStatusHandlers: []AlarmStatusHandler{},
+		unknownHandler:      nil,
+	}
+}
+
+func (r *Router) OnThreshold(handler ThresholdHandler) *Router {
+	r.thresholdHandlers = append(r.thresholdHandlers, handler)
+
+	return r
+}
+
+func (r *Router) OnAlarmStatus(handler AlarmStatusHandler) *Router {
+	r.alarmStatusHandlers = append(r.alarmStatusHandlers, handler)
+
+	return r
+}
+
+func (r *Router) OnUnknown(handler UnknownHandler) *Router {
+	r.unknownHandler = handler
+
+	return r
+}
+
+// Route decodes the event using the event type found in the attributes and
+// invokes all handlers registered for the type. All handlers are invoked
+// even if one fails, the failures are returned as Errors.
+//
+// The aggregate of the event is taken from the aggregate attribute, and from
+// the payload only when the attribute is missing. An event with an aggregate
+// in its payload which differs from the attribute is rejected with
+// ErrAggregateMismatch.
+func (r *Router) Route(ctx context.Context, data []byte, attributes map[string]string) error {
+	eventType := attributes[models.EventAttributeEventType]
+
+	switch eventType {
+	case models.EventTypeThreshold:
+		var event models.ThresholdEvent
+
+		if err := event.FromInternal(data); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrDecodingFailed, eventType, err)
+		}
+
+		aggregateID, err := resolveAggregate(attributes, event.AggregateID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", eventType, err)
+		}
+
+		event.AggregateID, event.Threshold.NodeID = aggregateID, aggregateID
+
+		errs := Errors{}
+
+		for _, handler := range r.thresholdHandlers {
+			if err := handler(ctx, event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		return errs.orNil()
+	case models.EventTypeAlarmStatus:
+		var event models.AlarmStatusEvent
+
+		if err := event.FromInternal(data); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrDecodingFailed, eventType, err)
+		}
+
+		aggregateID, err := resolveAggregate(attributes, event.AggregateID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", eventType, err)
+		}
+
+		event.AggregateID, event.AlarmStatus.NodeID = aggregateID, aggregateID
+
+		errs := Errors{}
+
+		for _, handler := range r.alarmStatusHandlers {
+			if err := handler(ctx, event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		return errs.orNil()
+	default:
+		if r.unknownHandler == nil {
+			return fmt.Errorf("%w: %q", ErrUnknownEventType, eventType)
+		}
+
+		return r.unknownHandler(ctx, eventType, data, attributes)
+	}
+}
+
+// RouteRecord routes an event record read from an event store.
+func (r *Router) RouteRecord(ctx context.Context, record eventsource.Record) error {
+	return r.Route(ctx, record.Data, map[string]string{
+		models.EventAttributeEventType: record.Type,
+		models.EventAttributeAggregate: record.AggregateID,
+	})
+}
+
+// resolveAggregate returns the aggregate of the attributes, or the aggregate
+// decoded from the payload if the attribute is missing.
+func resolveAggregate(attributes map[string]string, decoded uuid.UUID) (uuid.UUID, error) {
+	attribute, found := attributes[models.EventAttributeAggregate]
+	if !found || attribute == "" {
+		return decoded, nil
+	}
+
+	aggregateID := uuid.UUID(attribute)
+
+	if err := aggregateID.Validate(); err != nil {
+		return "", fmt.Errorf("%w: aggregate attribute: %v", ErrDecodingFailed, err)
+	}
+
+	if decoded != "" && !strings.EqualFold(decoded.String(), aggregateID.String()) {
+		return "", fmt.Errorf("%w: %s in attribute, %s in payload", ErrAggregateMismatch, aggregateID, decoded)
+	}
+
+	return aggregateID, nil
+}
+
+func (e Errors) orNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
@@ -0,0 +1,225 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SKF/go-eventsource/v2/eventsource"
+	internal_events "github.com/SKF/go-pas-client/internal/events"
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+const nodeID = uuid.UUID("5ad5b0a4-7fe0-4b8c-9d28-2a8b6a0c2f5e")
+
+func alarmStatusEvent(t *testing.T) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(internal_events.PointAlarmStatusEvent{
+		BaseEvent:   &eventsource.BaseEvent{AggregateID: nodeID.String()},
+		AlarmStatus: int32(models.AlarmStatusDanger),
+	})
+	require.NoError(t, err)
+
+	return data
+}
+
+func thresholdEvent(t *testing.T) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(internal_events.SetPointAlarmThresholdEvent{
+		BaseEvent: &eventsource.BaseEvent{AggregateID: nodeID.String()},
+		Type:      int32(models.ThresholdTypeInspection),
+	})
+	require.NoError(t, err)
+
+	return data
+}
+
+func Test_Route(t *testing.T) {
+	t.Parallel()
+
+	var (
+		thresholds    []models.ThresholdEvent
+		alarmStatuses []models.AlarmStatusEvent
+	)
+
+	router := NewRouter().
+		OnThreshold(func(_ context.Context, event models.ThresholdEvent) error {
+			thresholds = append(thresholds, event)
+
+			return nil
+		}).
+		OnAlarmStatus(func(_ context.Context, event models.AlarmStatusEvent) error {
+			alarmStatuses = append(alarmStatuses, event)
+
+			return nil
+		})
+
+	err := router.Route(context.TODO(), thresholdEvent(t), map[string]string{
+		models.EventAttributeEventType: models.EventTypeThreshold,
+	})
+	require.NoError(t, err)
+
+	err = router.RouteRecord(context.TODO(), eventsource.Record{
+		AggregateID: nodeID.String(),
+		Type:        models.EventTypeAlarmStatus,
+		Data:        alarmStatusEvent(t),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, thresholds, 1)
+	assert.Equal(t, nodeID, thresholds[0].AggregateID)
+	assert.Equal(t, models.ThresholdTypeInspection, thresholds[0].Threshold.ThresholdType)
+
+	require.Len(t, alarmStatuses, 1)
+	assert.Equal(t, models.AlarmStatusDanger, alarmStatuses[0].AlarmStatus.Status)
+}
+
+func Test_Route_Aggregate(t *testing.T) {
+	t.Parallel()
+
+	var routed []models.AlarmStatusEvent
+
+	router := NewRouter().OnAlarmStatus(func(_ context.Context, event models.AlarmStatusEvent) error {
+		routed = append(routed, event)
+
+		return nil
+	})
+
+	withoutAggregate, err := json.Marshal(internal_events.PointAlarmStatusEvent{
+		BaseEvent:   &eventsource.BaseEvent{},
+		AlarmStatus: int32(models.AlarmStatusGood),
+	})
+	require.NoError(t, err)
+
+	err = router.Route(context.TODO(), withoutAggregate, map[string]string{
+		models.EventAttributeEventType: models.EventTypeAlarmStatus,
+		models.EventAttributeAggregate: nodeID.String(),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, routed, 1)
+	assert.Equal(t, nodeID, routed[0].AggregateID)
+	assert.Equal(t, nodeID, routed[0].AlarmStatus.NodeID)
+
+	err = router.Route(context.TODO(), alarmStatusEvent(t), map[string]string{
+		models.EventAttributeEventType: models.EventTypeAlarmStatus,
+		models.EventAttributeAggregate: uuid.New().String(),
+	})
+	assert.ErrorIs(t, err, ErrAggregateMismatch)
+
+	err = router.Route(context.TODO(), alarmStatusEvent(t), map[string]string{
+		models.EventAttributeEventType: models.EventTypeAlarmStatus,
+		models.EventAttributeAggregate: "not a uuid",
+	})
+	assert.ErrorIs(t, err, ErrDecodingFailed)
+
+	assert.Len(t, routed, 1)
+}
+
+func Test_Route_Errors(t *testing.T) {
+	t.Parallel()
+
+	var (
+		errFirst  = errors.New("first")
+		errSecond = errors.New("second")
+		invoked   = 0
+	)
+
+	router := NewRouter().
+		OnAlarmStatus(func(context.Context, models.AlarmStatusEvent) error {
+			invoked++
+
+			return errFirst
+		}).
+		OnAlarmStatus(func(context.Context, models.AlarmStatusEvent) error {
+			invoked++
+
+			return errSecond
+		})
+
+	err := router.Route(context.TODO(), alarmStatusEvent(t), map[string]string{
+		models.EventAttributeEventType: models.EventTypeAlarmStatus,
+	})
+
+	assert.Equal(t, 2, invoked)
+	assert.ErrorIs(t, err, errFirst)
+	assert.ErrorIs(t, err, errSecond)
+
+	err = router.Route(context.TODO(), []byte(`{`), map[string]string{
+		models.EventAttributeEventType: models.EventTypeAlarmStatus,
+	})
+	assert.ErrorIs(t, err, ErrDecodingFailed)
+
+	err = router.Route(context.TODO(), []byte(`{}`), map[string]string{
+		models.EventAttributeEventType: "SomeOtherEvent",
+	})
+	assert.ErrorIs(t, err, ErrUnknownEventType)
+}
+
+func Test_Route_Unknown(t *testing.T) {
+	t.Parallel()
+
+	var actual string
+
+	router := NewRouter().OnUnknown(func(_ context.Context, eventType string, _ []byte, _ map[string]string) error {
+		actual = eventType
+
+		return nil
+	})
+
+	err := router.Route(context.TODO(), []byte(`{}`), map[string]string{
+		models.EventAttributeEventType: "SomeOtherEvent",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "SomeOtherEvent", actual)
+}
+
+func Test_RouteEnvelopes(t *testing.T) {
+	t.Parallel()
+
+	notification, err := json.Marshal(map[string]interface{}{
+		"Type":    "Notification",
+		"Message": string(alarmStatusEvent(t)),
+		"MessageAttributes": map[string]interface{}{
+			models.EventAttributeEventType: map[string]string{"Type": "String", "Value": models.EventTypeAlarmStatus},
+		},
+	})
+	require.NoError(t, err)
+
+	rawMessage, err := json.Marshal(map[string]interface{}{
+		"body": string(alarmStatusEvent(t)),
+		"messageAttributes": map[string]interface{}{
+			models.EventAttributeEventType: map[string]string{"dataType": "String", "stringValue": models.EventTypeAlarmStatus},
+		},
+	})
+	require.NoError(t, err)
+
+	notificationMessage, err := json.Marshal(map[string]interface{}{
+		"body": string(notification),
+	})
+	require.NoError(t, err)
+
+	routed := 0
+
+	router := NewRouter().OnAlarmStatus(func(_ context.Context, event models.AlarmStatusEvent) error {
+		routed++
+
+		assert.Equal(t, nodeID, event.AggregateID)
+
+		return nil
+	})
+
+	require.NoError(t, router.RouteSNS(context.TODO(), notification))
+	require.NoError(t, router.RouteSQS(context.TODO(), rawMessage))
+	require.NoError(t, router.RouteSQS(context.TODO(), notificationMessage))
+
+	assert.Equal(t, 3, routed)
+}
@@ -59,7 +59,7 @@ func main() {
 	fmt.Println("The threshold:")
 	dbg(threshold)
 
-	threshold, err = a.patchThreshold(ctx)
+	threshold, err = a.patchThreshold(ctx, threshold)
 	if err != nil {
 		panic(err)
 	}
@@ -101,33 +101,27 @@ func dbg(data interface{}) {
 }
 
 func (a *api) setThreshold(ctx context.Context) error {
-	var (
-		outerHigh = 70.0
-		innerHigh = 50.0
-		innerLow  = 20.0
-		outerLow  = 10.0
-	)
+	threshold, err := models.NewThreshold().
+		OverallOutOfWindow("C", 10, 20, 50, 70).
+		Build()
+	if err != nil {
+		return err
+	}
 
-	return a.client.SetThreshold(ctx, a.nodeID, models.Threshold{
-		ThresholdType: models.ThresholdTypeOverallOutOfWindow,
-		Overall: &models.Overall{
-			Unit:      "C",
-			OuterHigh: &outerHigh,
-			InnerHigh: &innerHigh,
-			InnerLow:  &innerLow,
-			OuterLow:  &outerLow,
-		},
-	})
+	return a.client.SetThreshold(ctx, a.nodeID, threshold)
 }
 
 func (a *api) getThreshold(ctx context.Context) (models.Threshold, error) {
 	return a.client.GetThreshold(ctx, a.nodeID)
 }
 
-func (a *api) patchThreshold(ctx context.Context) (models.Threshold, error) {
-	patch := models.Patch{
-		{Type: "test", Path: "/overall/outerHigh", Value: 70},
-		{Type: "replace", Path: "/overall/outerHigh", Value: 80},
+func (a *api) patchThreshold(ctx context.Context, current models.Threshold) (models.Threshold, error) {
+	patch, err := models.NewPatch(current).
+		Guarded().
+		ReplaceOverallOuterHigh(80).
+		Build()
+	if err != nil {
+		return models.Threshold{}, err
 	}
 
 	return a.client.PatchThreshold(ctx, a.nodeID, patch)
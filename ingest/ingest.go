@@ -0,0 +1,513 @@
+// Package ingest sends measurements to the PAS service with bounded
+// parallelism across nodes, while keeping the measurements of each node in
+// order.
+package ingest
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SKF/go-rest-utility/client/retry"
+
+	pas "github.com/SKF/go-pas-client"
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+const (
+	defaultConcurrency = 10
+	defaultMaxPending  = 1000
+	defaultBackoffBase = 100 * time.Millisecond
+	defaultBackoffCap  = 10 * time.Second
+	defaultMaxRetries  = 5
+	defaultStalePolicy = StaleDrop
+	defaultIdleTimeout = 10 * time.Minute
+)
+
+var (
+	// ErrStale is returned, or reported, for measurements created before the
+	// last measurement sent for the same node.
+	ErrStale = errors.New("measurement is older than the last one sent")
+	// ErrClosed is returned when submitting to a closed pipeline, and reported
+	// for measurements which couldn't be sent before the pipeline was closed.
+	ErrClosed = errors.New("pipeline is closed")
+)
+
+// Sender is used to send the measurements, it's implemented by the client.
+type Sender interface {
+	UpdateAlarmStatus(ctx context.Context, nodeID uuid.UUID, measurement *models.Measurement) error
+}
+
+var _ Sender = pas.API(nil)
+
+// Measurement is a measurement to be sent for a node.
+type Measurement struct {
+	NodeID      uuid.UUID
+	Measurement models.Measurement
+}
+
+// Result is reported for each measurement once it has been sent, or once it
+// has failed to be sent in which case Err is set.
+type Result struct {
+	Measurement
+	// Attempts is the number of times the measurement was sent, 0 for
+	// measurements which were dropped.
+	Attempts int
+	Err      error
+}
+
+// StalePolicy decides what happens to measurements created before the last
+// measurement sent for the same node.
+type StalePolicy int
+
+const (
+	// StaleDrop drops stale measurements, reporting them with ErrStale.
+	StaleDrop StalePolicy = iota
+	// StaleReject makes Submit return ErrStale for stale measurements.
+	StaleReject
+)
+
+type Option func(*Pipeline)
+
+// WithConcurrency limits the number of nodes which measurements are sent for
+// concurrently, defaults to 10.
+func WithConcurrency(n int) Option {
+	return func(p *Pipeline) {
+		if n < 1 {
+			n = 1
+		}
+
+		p.concurrency = n
+	}
+}
+
+// WithMaxPending limits the number of measurements which are queued or being
+// sent, Submit blocks once the limit is reached. Defaults to 1000.
+func WithMaxPending(n int) Option {
+	return func(p *Pipeline) {
+		if n < 1 {
+			n = 1
+		}
+
+		p.maxPending = n
+	}
+}
+
+// WithBackoff sets the backoff between retries of a failed measurement, the
+// measurement fails once the backoff provider returns an error. Defaults to an
+// exponential backoff with jitter, retrying up to 5 times.
+func WithBackoff(backoff retry.BackoffProvider) Option {
+	return func(p *Pipeline) {
+		p.backoff = backoff
+	}
+}
+
+// WithStalePolicy sets what happens to stale measurements, defaults to
+// StaleDrop.
+func WithStalePolicy(policy StalePolicy) Option {
+	return func(p *Pipeline) {
+		p.stalePolicy = policy
+	}
+}
+
+// WithIdleTimeout sets how long a node is remembered once all of its
+// measurements have been sent, defaults to 10 minutes. Measurements of a node
+// which has been forgotten are never stale.
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(p *Pipeline) {
+		if timeout < 0 {
+			timeout = 0
+		}
+
+		p.idleTimeout = timeout
+	}
+}
+
+// WithResultHandler sets a function which is called with the result of each
+// measurement. It's called from the goroutines sending the measurements and
+// should not block.
+func WithResultHandler(handler func(Result)) Option {
+	return func(p *Pipeline) {
+		p.onResult = handler
+	}
+}
+
+type node struct {
+	queue    []models.Measurement
+	inFlight bool
+	// last is the creation time of the latest measurement sent for the node.
+	last time.Time
+	// idleSince is when the last measurement of the node was sent, it's zero
+	// while the node has measurements queued or in flight.
+	idleSince time.Time
+}
+
+// idleNode is a node which became idle, the node is forgotten once it has
+// been idle for the idle timeout unless it has been active since.
+type idleNode struct {
+	nodeID uuid.UUID
+	since  time.Time
+}
+
+// Pipeline is safe for concurrent use. Measurements of the same node are sent
+// one at a time ordered by CreatedAt, a measurement being retried holds back
+// the later measurements of its node.
+type Pipeline struct {
+	sender      Sender
+	concurrency int
+	maxPending  int
+	backoff     retry.BackoffProvider
+	stalePolicy StalePolicy
+	onResult    func(Result)
+	idleTimeout time.Duration
+
+	mutex sync.Mutex
+	nodes map[uuid.UUID]*node
+	// idle holds the nodes in the order they became idle.
+	idle    []idleNode
+	ready   []uuid.UUID
+	pending int
+	closed  bool
+	// changed is closed and replaced whenever the state of the pipeline
+	// changes, waking up anyone waiting for it.
+	changed chan struct{}
+
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+}
+
+// New starts a pipeline sending measurements using the sender, Close must be
+// called to stop it.
+func New(sender Sender, opts ...Option) *Pipeline {
+	p := &Pipeline{
+		sender:      sender,
+		concurrency: defaultConcurrency,
+		maxPending:  defaultMaxPending,
+		backoff: &retry.ExponentialJitterBackoff{
+			Base:         defaultBackoffBase,
+			Cap:          defaultBackoffCap,
+			MaxAttempts:  defaultMaxRetries,
+			JitterSource: rand.Reader,
+		},
+		stalePolicy: defaultStalePolicy,
+		onResult:    nil,
+		idleTimeout: defaultIdleTimeout,
+		mutex:       sync.Mutex{},
+		nodes:       map[uuid.UUID]*node{},
+		idle:        nil,
+		ready:       nil,
+		pending:     0,
+		closed:      false,
+		changed:     make(chan struct{}),
+		cancel:      nil,
+		workers:     sync.WaitGroup{},
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	for i := 0; i < p.concurrency; i++ {
+		p.workers.Add(1)
+
+		go func() {
+			defer p.workers.Done()
+
+			p.work(ctx)
+		}()
+	}
+
+	return p
+}
+
+// Submit queues the measurement to be sent, blocking while the pipeline is
+// full. A stale measurement is either dropped or rejected with ErrStale,
+// depending on the stale policy.
+func (p *Pipeline) Submit(ctx context.Context, m Measurement) error {
+	dropped, err := p.submit(ctx, m)
+	if dropped {
+		p.report(Result{Measurement: m, Attempts: 0, Err: ErrStale})
+	}
+
+	return err
+}
+
+// submit queues the measurement and reports whether it was dropped.
+func (p *Pipeline) submit(ctx context.Context, m Measurement) (bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for !p.closed && p.pending >= p.maxPending {
+		if err := p.wait(ctx); err != nil {
+			return false, err
+		}
+	}
+
+	if p.closed {
+		return false, ErrClosed
+	}
+
+	p.forgetIdle(time.Now())
+
+	n, found := p.nodes[m.NodeID]
+	if !found {
+		n = &node{queue: nil, inFlight: false, last: time.Time{}, idleSince: time.Time{}}
+		p.nodes[m.NodeID] = n
+	}
+
+	if m.Measurement.CreatedAt.Before(n.last) {
+		if p.stalePolicy == StaleReject {
+			return false, ErrStale
+		}
+
+		return true, nil
+	}
+
+	n.idleSince = time.Time{}
+
+	// Insert after any measurement created at the same time, to keep the
+	// order of submission.
+	i := sort.Search(len(n.queue), func(i int) bool {
+		return n.queue[i].CreatedAt.After(m.Measurement.CreatedAt)
+	})
+
+	n.queue = append(n.queue, models.Measurement{})
+	copy(n.queue[i+1:], n.queue[i:])
+	n.queue[i] = m.Measurement
+
+	if len(n.queue) == 1 && !n.inFlight {
+		p.ready = append(p.ready, m.NodeID)
+	}
+
+	p.pending++
+	p.broadcast()
+
+	return false, nil
+}
+
+// Consume submits the measurements received on the channel until it's closed
+// or the context is canceled. Rejected measurements are reported to the
+// result handler.
+func (p *Pipeline) Consume(ctx context.Context, measurements <-chan Measurement) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case m, ok := <-measurements:
+			if !ok {
+				return nil
+			}
+
+			err := p.Submit(ctx, m)
+
+			switch {
+			case errors.Is(err, ErrStale):
+				p.report(Result{Measurement: m, Attempts: 0, Err: err})
+			case err != nil:
+				return err
+			}
+		}
+	}
+}
+
+// Flush blocks until all measurements submitted so far, and any submitted
+// meanwhile, have been sent or failed.
+func (p *Pipeline) Flush(ctx context.Context) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for p.pending > 0 {
+		if err := p.wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close stops accepting measurements and drains the pipeline. If the context
+// is canceled before the pipeline is drained, the measurements being sent are
+// canceled and the remaining ones are reported with ErrClosed.
+func (p *Pipeline) Close(ctx context.Context) error {
+	p.mutex.Lock()
+	p.closed = true
+	p.broadcast()
+	p.mutex.Unlock()
+
+	err := p.Flush(ctx)
+
+	p.cancel()
+	p.workers.Wait()
+
+	for _, result := range p.discard() {
+		p.report(result)
+	}
+
+	return err
+}
+
+// discard empties the queues once the workers have stopped, returning the
+// results of the discarded measurements.
+func (p *Pipeline) discard() []Result {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	results := make([]Result, 0, p.pending)
+
+	for nodeID, n := range p.nodes {
+		for _, measurement := range n.queue {
+			results = append(results, Result{
+				Measurement: Measurement{NodeID: nodeID, Measurement: measurement},
+				Attempts:    0,
+				Err:         ErrClosed,
+			})
+		}
+
+		n.queue = nil
+	}
+
+	p.ready = nil
+	p.pending = 0
+	p.broadcast()
+
+	return results
+}
+
+func (p *Pipeline) work(ctx context.Context) {
+	for {
+		m, ok := p.next(ctx)
+		if !ok {
+			return
+		}
+
+		attempts, err := p.send(ctx, m)
+
+		p.done(m.NodeID)
+		p.report(Result{Measurement: m, Attempts: attempts, Err: err})
+	}
+}
+
+// next takes the earliest measurement of the next ready node, and returns
+// false once the context is canceled.
+func (p *Pipeline) next(ctx context.Context) (Measurement, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for len(p.ready) == 0 || ctx.Err() != nil {
+		if err := p.wait(ctx); err != nil {
+			return Measurement{}, false
+		}
+	}
+
+	nodeID := p.ready[0]
+	p.ready = p.ready[1:]
+
+	n := p.nodes[nodeID]
+	measurement := n.queue[0]
+
+	n.queue = n.queue[1:]
+	n.inFlight = true
+	n.last = measurement.CreatedAt
+
+	return Measurement{NodeID: nodeID, Measurement: measurement}, true
+}
+
+func (p *Pipeline) done(nodeID uuid.UUID) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var (
+		now = time.Now()
+		n   = p.nodes[nodeID]
+	)
+
+	n.inFlight = false
+
+	if len(n.queue) > 0 {
+		p.ready = append(p.ready, nodeID)
+	} else {
+		n.idleSince = now
+		p.idle = append(p.idle, idleNode{nodeID: nodeID, since: now})
+	}
+
+	p.forgetIdle(now)
+
+	p.pending--
+	p.broadcast()
+}
+
+// forgetIdle removes the nodes which have been idle for the idle timeout, it
+// must be called with the mutex held.
+func (p *Pipeline) forgetIdle(now time.Time) {
+	for len(p.idle) > 0 && now.Sub(p.idle[0].since) >= p.idleTimeout {
+		idle := p.idle[0]
+		p.idle = p.idle[1:]
+
+		if n, found := p.nodes[idle.nodeID]; found && n.idleSince.Equal(idle.since) {
+			delete(p.nodes, idle.nodeID)
+		}
+	}
+}
+
+// send sends the measurement, retrying failures which aren't caused by the
+// measurement itself.
+func (p *Pipeline) send(ctx context.Context, m Measurement) (int, error) {
+	for attempt := 1; ; attempt++ {
+		measurement := m.Measurement
+
+		err := p.sender.UpdateAlarmStatus(ctx, m.NodeID, &measurement)
+		if err == nil || pas.IsPermanent(err) || ctx.Err() != nil {
+			return attempt, err
+		}
+
+		backoff, backoffErr := p.backoff.BackoffByAttempt(attempt)
+		if backoffErr != nil {
+			return attempt, fmt.Errorf("sending measurement failed after %d attempts: %w", attempt, err)
+		}
+
+		timer := time.NewTimer(backoff)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return attempt, err
+		case <-timer.C:
+		}
+	}
+}
+
+func (p *Pipeline) report(result Result) {
+	if p.onResult != nil {
+		p.onResult(result)
+	}
+}
+
+// wait blocks until the state of the pipeline changes or the context is
+// canceled, it must be called with the mutex held.
+func (p *Pipeline) wait(ctx context.Context) error {
+	changed := p.changed
+
+	p.mutex.Unlock()
+	defer p.mutex.Lock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-changed:
+		return nil
+	}
+}
+
+func (p *Pipeline) broadcast() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
@@ -0,0 +1,339 @@
+package ingest_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SKF/go-rest-utility/client/retry"
+
+	pas "github.com/SKF/go-pas-client"
+	"github.com/SKF/go-pas-client/ingest"
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+var (
+	nodeA = uuid.UUID("a0000000-0000-0000-0000-000000000000")
+	nodeB = uuid.UUID("b0000000-0000-0000-0000-000000000000")
+	epoch = time.Date(2022, time.March, 4, 12, 0, 0, 0, time.UTC)
+)
+
+type sent struct {
+	nodeID    uuid.UUID
+	createdAt time.Time
+}
+
+type fakeSender struct {
+	mutex sync.Mutex
+	sent  []sent
+	// fail returns the error to fail the measurement with, if any.
+	fail  func(nodeID uuid.UUID, measurement *models.Measurement) error
+	block chan struct{}
+}
+
+func (s *fakeSender) UpdateAlarmStatus(ctx context.Context, nodeID uuid.UUID, measurement *models.Measurement) error {
+	if s.block != nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.block:
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.fail != nil {
+		if err := s.fail(nodeID, measurement); err != nil {
+			return err
+		}
+	}
+
+	s.sent = append(s.sent, sent{nodeID: nodeID, createdAt: measurement.CreatedAt})
+
+	return nil
+}
+
+func (s *fakeSender) sentTo(nodeID uuid.UUID) []time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	createdAt := []time.Time{}
+
+	for _, sent := range s.sent {
+		if sent.nodeID == nodeID {
+			createdAt = append(createdAt, sent.createdAt)
+		}
+	}
+
+	return createdAt
+}
+
+type results struct {
+	mutex   sync.Mutex
+	results []ingest.Result
+}
+
+func (r *results) handle(result ingest.Result) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.results = append(r.results, result)
+}
+
+func (r *results) get() []ingest.Result {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]ingest.Result{}, r.results...)
+}
+
+func measurement(nodeID uuid.UUID, minutes int) ingest.Measurement {
+	return ingest.Measurement{
+		NodeID: nodeID,
+		Measurement: models.Measurement{
+			MeasurementID: uuid.New(),
+			CreatedAt:     epoch.Add(time.Duration(minutes) * time.Minute),
+			ContentType:   models.ContentTypeDataPoint,
+		},
+	}
+}
+
+func Test_Pipeline_OrdersPerNode(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx      = context.Background()
+		sender   = &fakeSender{block: make(chan struct{})}
+		received results
+		pipeline = ingest.New(sender, ingest.WithConcurrency(2), ingest.WithResultHandler(received.handle))
+	)
+
+	for _, m := range []ingest.Measurement{
+		measurement(nodeA, 1),
+		measurement(nodeB, 1),
+		measurement(nodeA, 3),
+		measurement(nodeA, 2),
+	} {
+		require.NoError(t, pipeline.Submit(ctx, m))
+	}
+
+	close(sender.block)
+
+	require.NoError(t, pipeline.Close(ctx))
+
+	assert.Equal(t, []time.Time{
+		epoch.Add(time.Minute),
+		epoch.Add(2 * time.Minute),
+		epoch.Add(3 * time.Minute),
+	}, sender.sentTo(nodeA))
+
+	assert.Len(t, sender.sentTo(nodeB), 1)
+
+	for _, result := range received.get() {
+		assert.NoError(t, result.Err)
+		assert.Equal(t, 1, result.Attempts)
+	}
+
+	assert.Len(t, received.get(), 4)
+}
+
+func Test_Pipeline_Stale(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		policy      ingest.StalePolicy
+		expectedErr error
+	}{
+		"drop":   {policy: ingest.StaleDrop, expectedErr: nil},
+		"reject": {policy: ingest.StaleReject, expectedErr: ingest.ErrStale},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				ctx      = context.Background()
+				sender   = &fakeSender{}
+				received results
+				pipeline = ingest.New(sender, ingest.WithStalePolicy(test.policy), ingest.WithResultHandler(received.handle))
+			)
+
+			require.NoError(t, pipeline.Submit(ctx, measurement(nodeA, 2)))
+			require.NoError(t, pipeline.Flush(ctx))
+
+			err := pipeline.Submit(ctx, measurement(nodeA, 1))
+			assert.Equal(t, test.expectedErr, err)
+
+			require.NoError(t, pipeline.Submit(ctx, measurement(nodeA, 2)), "measurements at the same time aren't stale")
+			require.NoError(t, pipeline.Close(ctx))
+
+			assert.Len(t, sender.sentTo(nodeA), 2)
+
+			var stale int
+
+			for _, result := range received.get() {
+				if errors.Is(result.Err, ingest.ErrStale) {
+					stale++
+				}
+			}
+
+			if test.policy == ingest.StaleDrop {
+				assert.Equal(t, 1, stale)
+			} else {
+				assert.Equal(t, 0, stale)
+			}
+		})
+	}
+}
+
+func Test_Pipeline_IdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx      = context.Background()
+		sender   = &fakeSender{}
+		pipeline = ingest.New(sender, ingest.WithStalePolicy(ingest.StaleReject), ingest.WithIdleTimeout(10*time.Millisecond))
+	)
+
+	require.NoError(t, pipeline.Submit(ctx, measurement(nodeA, 2)))
+	require.NoError(t, pipeline.Flush(ctx))
+
+	time.Sleep(20 * time.Millisecond)
+
+	require.NoError(t, pipeline.Submit(ctx, measurement(nodeA, 1)), "the idle node has been forgotten")
+	require.NoError(t, pipeline.Close(ctx))
+
+	assert.Len(t, sender.sentTo(nodeA), 2)
+}
+
+func Test_Pipeline_Retries(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx      = context.Background()
+		failures = map[uuid.UUID]int{nodeA: 2}
+		sender   = &fakeSender{
+			fail: func(nodeID uuid.UUID, _ *models.Measurement) error {
+				switch {
+				case nodeID == nodeB:
+					return pas.ErrValidation
+				case failures[nodeID] > 0:
+					failures[nodeID]--
+
+					return pas.ErrServer
+				default:
+					return nil
+				}
+			},
+		}
+		received results
+		pipeline = ingest.New(
+			sender,
+			ingest.WithBackoff(&retry.ExponentialJitterBackoff{MaxAttempts: 3}),
+			ingest.WithResultHandler(received.handle),
+		)
+	)
+
+	require.NoError(t, pipeline.Submit(ctx, measurement(nodeA, 1)))
+	require.NoError(t, pipeline.Submit(ctx, measurement(nodeB, 1)))
+	require.NoError(t, pipeline.Close(ctx))
+
+	byNode := map[uuid.UUID]ingest.Result{}
+
+	for _, result := range received.get() {
+		byNode[result.NodeID] = result
+	}
+
+	assert.NoError(t, byNode[nodeA].Err)
+	assert.Equal(t, 3, byNode[nodeA].Attempts)
+
+	assert.ErrorIs(t, byNode[nodeB].Err, pas.ErrValidation)
+	assert.Equal(t, 1, byNode[nodeB].Attempts, "rejected measurements are not retried")
+}
+
+func Test_Pipeline_BackPressure(t *testing.T) {
+	t.Parallel()
+
+	var (
+		sender   = &fakeSender{block: make(chan struct{})}
+		pipeline = ingest.New(sender, ingest.WithMaxPending(2))
+	)
+
+	require.NoError(t, pipeline.Submit(context.Background(), measurement(nodeA, 1)))
+	require.NoError(t, pipeline.Submit(context.Background(), measurement(nodeA, 2)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, pipeline.Submit(ctx, measurement(nodeA, 3)), context.DeadlineExceeded)
+
+	close(sender.block)
+
+	require.NoError(t, pipeline.Submit(context.Background(), measurement(nodeA, 3)))
+	require.NoError(t, pipeline.Close(context.Background()))
+
+	assert.Len(t, sender.sentTo(nodeA), 3)
+	assert.ErrorIs(t, pipeline.Submit(context.Background(), measurement(nodeA, 4)), ingest.ErrClosed)
+}
+
+func Test_Pipeline_CloseTimeout(t *testing.T) {
+	t.Parallel()
+
+	var (
+		sender   = &fakeSender{block: make(chan struct{})}
+		received results
+		pipeline = ingest.New(sender, ingest.WithResultHandler(received.handle))
+	)
+
+	require.NoError(t, pipeline.Submit(context.Background(), measurement(nodeA, 1)))
+	require.NoError(t, pipeline.Submit(context.Background(), measurement(nodeA, 2)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, pipeline.Close(ctx), context.DeadlineExceeded)
+
+	errs := []error{}
+
+	for _, result := range received.get() {
+		errs = append(errs, result.Err)
+	}
+
+	require.Len(t, errs, 2)
+	assert.ErrorIs(t, errs[0], context.Canceled, "the measurement being sent is canceled")
+	assert.ErrorIs(t, errs[1], ingest.ErrClosed)
+}
+
+func Test_Pipeline_Consume(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx          = context.Background()
+		sender       = &fakeSender{}
+		pipeline     = ingest.New(sender)
+		measurements = make(chan ingest.Measurement)
+	)
+
+	go func() {
+		defer close(measurements)
+
+		for i := 1; i <= 5; i++ {
+			measurements <- measurement(nodeB, i)
+		}
+	}()
+
+	require.NoError(t, pipeline.Consume(ctx, measurements))
+	require.NoError(t, pipeline.Close(ctx))
+
+	assert.Len(t, sender.sentTo(nodeB), 5)
+}
@@ -17,6 +17,13 @@ type SetPointAlarmThresholdEvent struct {
 	HalAlarms    [][]byte `json:"thresholdHalAlarms,omitempty"`
 	Overall      []byte   `json:"thresholdOverall"`
 	RateOfChange []byte   `json:"thresholdRateOfChange,omitempty"`
+	Origin       *Origin  `json:"origin,omitempty"`
+}
+
+type Origin struct {
+	ID       string `json:"id,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	Type     string `json:"type,omitempty"`
 }
 
 type (
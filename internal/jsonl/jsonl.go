@@ -0,0 +1,62 @@
+// Package jsonl holds the file handling shared by the stores which append
+// JSON lines to a single file.
+package jsonl
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+// TruncatePartialLine removes anything after the last newline of the file at
+// path, which is left behind if a write was interrupted.
+func TruncatePartialLine(path string) error {
+	buf, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("reading file failed: %w", err)
+	}
+
+	if length := bytes.LastIndexByte(buf, '\n') + 1; length < len(buf) {
+		if err = os.Truncate(path, int64(length)); err != nil {
+			return fmt.Errorf("truncating file failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Replace atomically replaces the file at path with buf, by writing it to a
+// temporary file which is synced and then renamed.
+func Replace(path string, buf []byte, perm os.FileMode) error {
+	tmp := path + ".tmp"
+
+	if err := writeFileSync(tmp, buf, perm); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replacing file failed: %w", err)
+	}
+
+	return nil
+}
+
+func writeFileSync(path string, buf []byte, perm os.FileMode) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("creating file failed: %w", err)
+	}
+	defer file.Close()
+
+	if _, err = file.Write(buf); err != nil {
+		return fmt.Errorf("writing file failed: %w", err)
+	}
+
+	if err = file.Sync(); err != nil {
+		return fmt.Errorf("syncing file failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,297 @@
+// Package jsonpatch applies RFC 6902 JSON Patch documents to JSON documents.
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/wI2L/jsondiff"
+)
+
+var (
+	ErrTestFailed       = errors.New("test operation failed")
+	ErrInvalidPointer   = errors.New("invalid JSON pointer")
+	ErrPathNotFound     = errors.New("path not found")
+	ErrUnknownOperation = errors.New("unknown operation")
+)
+
+// Apply applies the patch to the JSON document and returns the patched document.
+func Apply(document []byte, patch []jsondiff.Operation) ([]byte, error) {
+	var doc interface{}
+
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return nil, fmt.Errorf("decoding document failed: %w", err)
+	}
+
+	for i, operation := range patch {
+		var err error
+
+		if doc, err = applyOperation(doc, operation); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, operation.Type, operation.Path, err)
+		}
+	}
+
+	return json.Marshal(doc)
+}
+
+func applyOperation(doc interface{}, operation jsondiff.Operation) (interface{}, error) {
+	path, err := Parse(operation.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation.Type {
+	case jsondiff.OperationAdd:
+		value, err := normalize(operation.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		return add(doc, path, value)
+	case jsondiff.OperationRemove:
+		doc, _, err = remove(doc, path)
+
+		return doc, err
+	case jsondiff.OperationReplace:
+		value, err := normalize(operation.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		if doc, _, err = remove(doc, path); err != nil {
+			return nil, err
+		}
+
+		return add(doc, path, value)
+	case jsondiff.OperationMove, jsondiff.OperationCopy:
+		from, err := Parse(operation.From)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		if operation.Type == jsondiff.OperationMove {
+			if doc, _, err = remove(doc, from); err != nil {
+				return nil, err
+			}
+		}
+
+		return add(doc, path, value)
+	case jsondiff.OperationTest:
+		expected, err := normalize(operation.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		actual, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !reflect.DeepEqual(expected, actual) {
+			return nil, ErrTestFailed
+		}
+
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownOperation, operation.Type)
+	}
+}
+
+// Get returns the value referenced by the pointer in the JSON document.
+func Get(document []byte, pointer string) (interface{}, error) {
+	var doc interface{}
+
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return nil, fmt.Errorf("decoding document failed: %w", err)
+	}
+
+	path, err := Parse(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	return get(doc, path)
+}
+
+// Parse splits a JSON pointer into its unescaped reference tokens.
+func Parse(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPointer, pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+
+	return tokens, nil
+}
+
+// normalize converts a value into the generic representation produced by
+// encoding/json so that it can be inserted into and compared with the document.
+func normalize(value interface{}) (interface{}, error) {
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("encoding value failed: %w", err)
+	}
+
+	var normalized interface{}
+
+	if err = json.Unmarshal(buf, &normalized); err != nil {
+		return nil, fmt.Errorf("decoding value failed: %w", err)
+	}
+
+	return normalized, nil
+}
+
+func get(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, found := node[token]
+			if !found {
+				return nil, ErrPathNotFound
+			}
+
+			doc = value
+		case []interface{}:
+			idx, err := index(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+
+			doc = node[idx]
+		default:
+			return nil, ErrPathNotFound
+		}
+	}
+
+	return doc, nil
+}
+
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+
+		return doc, nil
+	case []interface{}:
+		idx := len(node)
+
+		if last != "-" {
+			if idx, err = index(last, len(node)); err != nil {
+				return nil, err
+			}
+		}
+
+		node = append(node, nil)
+		copy(node[idx+1:], node[idx:])
+		node[idx] = value
+
+		return replaceParent(doc, path[:len(path)-1], node)
+	default:
+		return nil, ErrPathNotFound
+	}
+}
+
+func remove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		value, found := node[last]
+		if !found {
+			return nil, nil, ErrPathNotFound
+		}
+
+		delete(node, last)
+
+		return doc, value, nil
+	case []interface{}:
+		idx, err := index(last, len(node)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		value := node[idx]
+		node = append(node[:idx:idx], node[idx+1:]...)
+
+		doc, err = replaceParent(doc, path[:len(path)-1], node)
+
+		return doc, value, err
+	default:
+		return nil, nil, ErrPathNotFound
+	}
+}
+
+// replaceParent stores a resized array back into the document, since
+// appending to or removing from a slice might reallocate it.
+func replaceParent(doc interface{}, path []string, array []interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return array, nil
+	}
+
+	grandParent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	last := path[len(path)-1]
+
+	switch node := grandParent.(type) {
+	case map[string]interface{}:
+		node[last] = array
+	case []interface{}:
+		idx, err := index(last, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+
+		node[idx] = array
+	}
+
+	return doc, nil
+}
+
+func index(token string, upper int) (int, error) {
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 || idx > upper || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrPathNotFound, token)
+	}
+
+	return idx, nil
+}
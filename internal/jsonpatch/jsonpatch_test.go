@@ -0,0 +1,124 @@
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wI2L/jsondiff"
+)
+
+func Test_Apply(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		given    string
+		patch    []jsondiff.Operation
+		expected string
+	}{
+		{
+			given:    `{"a": 1}`,
+			patch:    []jsondiff.Operation{{Type: "replace", Path: "/a", Value: 2}},
+			expected: `{"a": 2}`,
+		},
+		{
+			given:    `{"a": 1}`,
+			patch:    []jsondiff.Operation{{Type: "add", Path: "/b", Value: map[string]int{"c": 3}}},
+			expected: `{"a": 1, "b": {"c": 3}}`,
+		},
+		{
+			given:    `{"a": 1, "b": 2}`,
+			patch:    []jsondiff.Operation{{Type: "remove", Path: "/b"}},
+			expected: `{"a": 1}`,
+		},
+		{
+			given:    `{"a": [1, 3]}`,
+			patch:    []jsondiff.Operation{{Type: "add", Path: "/a/1", Value: 2}, {Type: "add", Path: "/a/-", Value: 4}},
+			expected: `{"a": [1, 2, 3, 4]}`,
+		},
+		{
+			given:    `{"a": [1, 2, 3]}`,
+			patch:    []jsondiff.Operation{{Type: "remove", Path: "/a/0"}},
+			expected: `{"a": [2, 3]}`,
+		},
+		{
+			given:    `{"a": {"b": 1}}`,
+			patch:    []jsondiff.Operation{{Type: "move", From: "/a/b", Path: "/c"}},
+			expected: `{"a": {}, "c": 1}`,
+		},
+		{
+			given:    `{"a/b": 1}`,
+			patch:    []jsondiff.Operation{{Type: "copy", From: "/a~1b", Path: "/c"}},
+			expected: `{"a/b": 1, "c": 1}`,
+		},
+		{
+			given:    `{"a": 1.5}`,
+			patch:    []jsondiff.Operation{{Type: "test", Path: "/a", Value: 1.5}},
+			expected: `{"a": 1.5}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run("", func(t *testing.T) {
+			actual, err := Apply([]byte(test.given), test.patch)
+			require.NoError(t, err)
+
+			assert.JSONEq(t, test.expected, string(actual))
+		})
+	}
+}
+
+func Test_Apply_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		patch    []jsondiff.Operation
+		expected error
+	}{
+		{
+			patch:    []jsondiff.Operation{{Type: "test", Path: "/a", Value: 2}},
+			expected: ErrTestFailed,
+		},
+		{
+			patch:    []jsondiff.Operation{{Type: "remove", Path: "/b"}},
+			expected: ErrPathNotFound,
+		},
+		{
+			patch:    []jsondiff.Operation{{Type: "replace", Path: "/a/0", Value: 1}},
+			expected: ErrPathNotFound,
+		},
+		{
+			patch:    []jsondiff.Operation{{Type: "replace", Path: "a", Value: 1}},
+			expected: ErrInvalidPointer,
+		},
+		{
+			patch:    []jsondiff.Operation{{Type: "boop", Path: "/a"}},
+			expected: ErrUnknownOperation,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run("", func(t *testing.T) {
+			_, err := Apply([]byte(`{"a": 1}`), test.patch)
+
+			assert.ErrorIs(t, err, test.expected)
+		})
+	}
+}
+
+func Test_Get(t *testing.T) {
+	t.Parallel()
+
+	document := []byte(`{"a": [{"b": 1}]}`)
+
+	actual, err := Get(document, "/a/0/b")
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), actual)
+
+	_, err = Get(document, "/a/1")
+	assert.ErrorIs(t, err, ErrPathNotFound)
+}
@@ -0,0 +1,32 @@
+// Package responses holds representations of responses of the PAS API which
+// aren't covered by the generated models.
+package responses
+
+import (
+	"fmt"
+
+	internal_models "github.com/SKF/go-pas-client/internal/models"
+	"github.com/SKF/go-pas-client/models"
+)
+
+// Threshold is a threshold as returned by the PAS API, which also carries the
+// origin of the threshold.
+type Threshold struct {
+	internal_models.ModelsGetPointAlarmThresholdResponse
+	Origin *internal_models.ModelsOrigin `json:"origin,omitempty"`
+}
+
+func (r Threshold) ToThreshold() (models.Threshold, error) {
+	threshold := models.Threshold{} //nolint:exhaustruct
+
+	if err := threshold.FromInternal(r.ModelsGetPointAlarmThresholdResponse); err != nil {
+		return models.Threshold{}, fmt.Errorf("converting threshold failed: %w", err)
+	}
+
+	if r.Origin != nil {
+		threshold.Origin = new(models.Origin)
+		threshold.Origin.FromInternal(r.Origin)
+	}
+
+	return threshold, nil
+}
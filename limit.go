@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Route groups the requests of the client into budgets which can be limited
+// separately using WithRouteRateLimit and WithRouteMaxInFlight.
+type Route string
+
+const (
+	// RouteThresholdRead is used by GetThreshold.
+	RouteThresholdRead Route = "threshold-read"
+	// RouteThresholdWrite is used by SetThreshold and PatchThreshold.
+	RouteThresholdWrite Route = "threshold-write"
+	// RouteAlarmStatusRead is used by GetAlarmStatus.
+	RouteAlarmStatusRead Route = "alarm-status-read"
+	// RouteExternalAlarmStatus is used by SetExternalAlarmStatus.
+	RouteExternalAlarmStatus Route = "external-alarm-status"
+	// RouteMeasurement is used by UpdateAlarmStatus to ingest measurements.
+	RouteMeasurement Route = "measurement"
+)
+
+// LimitWait is passed to the observer of WithLimitObserver each time a
+// request has waited for the limits of the client.
+type LimitWait struct {
+	Route Route
+	// Waited is the time spent waiting for the rate limits and for a request
+	// in flight to finish.
+	Waited time.Duration
+	// Err is set if the context was canceled, or its deadline would pass,
+	// before the request could be made.
+	Err error
+}
+
+// WithRateLimit limits the requests of all routes to rps requests per second,
+// allowing bursts of up to burst requests. Requests block until allowed or
+// until the deadline of their context would pass. With rps zero only the
+// initial burst is allowed, requests after that fail.
+func WithRateLimit(rps float64, burst int) ClientOption {
+	return func(o *options) {
+		o.limits.limiter("").setRate(rps, burst)
+	}
+}
+
+// WithMaxInFlight limits the number of concurrent requests of all routes.
+func WithMaxInFlight(n int) ClientOption {
+	return func(o *options) {
+		o.limits.limiter("").setMaxInFlight(n)
+	}
+}
+
+// WithRouteRateLimit limits the requests of the route to rps requests per
+// second, in addition to any limit set by WithRateLimit.
+func WithRouteRateLimit(route Route, rps float64, burst int) ClientOption {
+	return func(o *options) {
+		o.limits.limiter(route).setRate(rps, burst)
+	}
+}
+
+// WithRouteMaxInFlight limits the number of concurrent requests of the route,
+// in addition to any limit set by WithMaxInFlight.
+func WithRouteMaxInFlight(route Route, n int) ClientOption {
+	return func(o *options) {
+		o.limits.limiter(route).setMaxInFlight(n)
+	}
+}
+
+// WithLimitObserver sets a function which is called with the time each
+// request spent waiting for the limits, e.g. to record it as a metric. It's
+// only called for requests which are limited.
+func WithLimitObserver(observer func(LimitWait)) ClientOption {
+	return func(o *options) {
+		o.limits.observer = observer
+	}
+}
+
+// limits holds the limiters of the client, the limiter of all routes is
+// stored using the empty route.
+type limits struct {
+	limiters map[Route]*limiter
+	observer func(LimitWait)
+}
+
+func (l *limits) limiter(route Route) *limiter {
+	if l.limiters == nil {
+		l.limiters = map[Route]*limiter{}
+	}
+
+	if _, found := l.limiters[route]; !found {
+		l.limiters[route] = &limiter{rate: nil, inFlight: nil}
+	}
+
+	return l.limiters[route]
+}
+
+// acquire waits for the limits of the route followed by the limits of all
+// routes, the returned function must be called once the request is done.
+func (l *limits) acquire(ctx context.Context, route Route) (func(), error) {
+	var (
+		start    = time.Now()
+		keys     = []Route{route, ""}
+		releases = make([]func(), 0, len(keys))
+		err      error
+	)
+
+	release := func() {
+		for _, r := range releases {
+			r()
+		}
+	}
+
+	for _, key := range keys {
+		limiter, found := l.limiters[key]
+		if !found {
+			continue
+		}
+
+		var limiterRelease func()
+
+		if limiterRelease, err = limiter.acquire(ctx); err != nil {
+			break
+		}
+
+		releases = append(releases, limiterRelease)
+	}
+
+	if len(releases) > 0 || err != nil {
+		l.observe(LimitWait{Route: route, Waited: time.Since(start), Err: err})
+	}
+
+	if err != nil {
+		release()
+
+		return nil, err
+	}
+
+	return release, nil
+}
+
+func (l *limits) observe(wait LimitWait) {
+	if l.observer != nil {
+		l.observer(wait)
+	}
+}
+
+type limiter struct {
+	rate     *rate.Limiter
+	inFlight chan struct{}
+}
+
+func (l *limiter) setRate(rps float64, burst int) {
+	if burst < 1 {
+		burst = 1
+	}
+
+	l.rate = rate.NewLimiter(rate.Limit(rps), burst)
+}
+
+func (l *limiter) setMaxInFlight(n int) {
+	if n < 1 {
+		n = 1
+	}
+
+	l.inFlight = make(chan struct{}, n)
+}
+
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	if l.rate != nil {
+		if err := l.rate.Wait(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			// The rate limiter fails early if the deadline would pass while
+			// waiting, any other failure is due to the limit itself.
+			if _, hasDeadline := ctx.Deadline(); hasDeadline && l.rate.Limit() > 0 {
+				return nil, fmt.Errorf("waiting for rate limit failed: %w: %v", context.DeadlineExceeded, err)
+			}
+
+			return nil, fmt.Errorf("waiting for rate limit failed: %w", err)
+		}
+	}
+
+	if l.inFlight == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case l.inFlight <- struct{}{}:
+		return func() { <-l.inFlight }, nil
+	}
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SKF/go-pas-client/models"
+	rest "github.com/SKF/go-rest-utility/client"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+func okServer(t *testing.T, delay time.Duration) (*httptest.Server, *int32, *int32) {
+	t.Helper()
+
+	var calls, inFlight, maxInFlight int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			previous := atomic.LoadInt32(&maxInFlight)
+			if current <= previous || atomic.CompareAndSwapInt32(&maxInFlight, previous, current) {
+				break
+			}
+		}
+
+		time.Sleep(delay)
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status": 2}`))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, &calls, &maxInFlight
+}
+
+func Test_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mutex        sync.Mutex
+		waits        []LimitWait
+		server, _, _ = okServer(t, 0)
+		client       = NewWithOptions(
+			[]rest.Option{rest.WithBaseURL(server.URL)},
+			WithRateLimit(20, 1),
+			WithLimitObserver(func(wait LimitWait) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				waits = append(waits, wait)
+			}),
+		)
+	)
+
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		_, err := client.GetAlarmStatus(context.Background(), uuid.New())
+		require.NoError(t, err)
+	}
+
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	require.Len(t, waits, 3)
+	assert.Equal(t, RouteAlarmStatusRead, waits[0].Route)
+	assert.Greater(t, waits[2].Waited, time.Duration(0))
+	assert.NoError(t, waits[2].Err)
+}
+
+func Test_RateLimit_Deadline(t *testing.T) {
+	t.Parallel()
+
+	var (
+		waits            []LimitWait
+		server, calls, _ = okServer(t, 0)
+		client           = NewWithOptions(
+			[]rest.Option{rest.WithBaseURL(server.URL)},
+			WithRouteRateLimit(RouteMeasurement, 0.1, 1),
+			WithLimitObserver(func(wait LimitWait) {
+				waits = append(waits, wait)
+			}),
+		)
+	)
+
+	require.NoError(t, client.UpdateAlarmStatus(context.Background(), uuid.New(), nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+
+	err := client.UpdateAlarmStatus(ctx, uuid.New(), nil)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second, "fails without waiting for the deadline")
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+	require.Len(t, waits, 2)
+	assert.ErrorIs(t, waits[1].Err, context.DeadlineExceeded)
+
+	// other routes are not limited by the route limit
+	_, err = client.GetAlarmStatus(ctx, uuid.New())
+	assert.NoError(t, err)
+	assert.Len(t, waits, 2)
+}
+
+func Test_RateLimit_Zero(t *testing.T) {
+	t.Parallel()
+
+	var (
+		server, calls, _ = okServer(t, 0)
+		client           = NewWithOptions([]rest.Option{rest.WithBaseURL(server.URL)}, WithRateLimit(0, 1))
+	)
+
+	_, err := client.GetAlarmStatus(context.Background(), uuid.New())
+	require.NoError(t, err)
+
+	_, err = client.GetAlarmStatus(context.Background(), uuid.New())
+	require.Error(t, err)
+
+	assert.NotErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func Test_MaxInFlight(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		option   ClientOption
+		expected int32
+	}{
+		"all routes": {
+			option:   WithMaxInFlight(2),
+			expected: 2,
+		},
+		"route": {
+			option:   WithRouteMaxInFlight(RouteExternalAlarmStatus, 1),
+			expected: 1,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				server, calls, maxInFlight = okServer(t, 20*time.Millisecond)
+				client                     = NewWithOptions([]rest.Option{rest.WithBaseURL(server.URL)}, test.option)
+				wg                         sync.WaitGroup
+			)
+
+			for i := 0; i < 6; i++ {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					err := client.SetExternalAlarmStatus(context.Background(), uuid.New(), models.ExternalAlarmStatus{
+						Status: models.AlarmStatusGood,
+					})
+					assert.NoError(t, err)
+				}()
+			}
+
+			wg.Wait()
+
+			assert.Equal(t, int32(6), atomic.LoadInt32(calls))
+			assert.Equal(t, test.expected, atomic.LoadInt32(maxInFlight))
+		})
+	}
+}
@@ -12,24 +12,25 @@ import (
 
 type (
 	AlarmStatus struct {
-		Status       AlarmStatusType
-		UpdatedAt    time.Time
-		Overall      *GenericAlarmStatus
-		RateOfChange *GenericAlarmStatus
-		Inspection   *GenericAlarmStatus
-		Band         []BandAlarmStatus
-		HAL          []HALAlarmStatus
-		External     *ExternalAlarmStatus
+		NodeID       uuid.UUID            `json:"nodeId,omitempty" yaml:"nodeId,omitempty"`
+		Status       AlarmStatusType      `json:"status" yaml:"status"`
+		UpdatedAt    time.Time            `json:"updatedAt" yaml:"updatedAt"`
+		Overall      *GenericAlarmStatus  `json:"overall,omitempty" yaml:"overall,omitempty"`
+		RateOfChange *GenericAlarmStatus  `json:"rateOfChange,omitempty" yaml:"rateOfChange,omitempty"`
+		Inspection   *GenericAlarmStatus  `json:"inspection,omitempty" yaml:"inspection,omitempty"`
+		Band         []BandAlarmStatus    `json:"band" yaml:"band"`
+		HAL          []HALAlarmStatus     `json:"hal" yaml:"hal"`
+		External     *ExternalAlarmStatus `json:"external,omitempty" yaml:"external,omitempty"`
 	}
 
 	GenericAlarmStatus struct {
-		TriggeringMeasurement uuid.UUID
-		Status                AlarmStatusType
+		TriggeringMeasurement uuid.UUID       `json:"triggeringMeasurement,omitempty" yaml:"triggeringMeasurement,omitempty"`
+		Status                AlarmStatusType `json:"status" yaml:"status"`
 	}
 
 	ExternalAlarmStatus struct {
-		Status AlarmStatusType
-		SetBy  *uuid.UUID
+		Status AlarmStatusType `json:"status" yaml:"status"`
+		SetBy  *uuid.UUID      `json:"setBy,omitempty" yaml:"setBy,omitempty"`
 	}
 )
 
@@ -38,6 +39,10 @@ func (a *AlarmStatus) FromInternal(internal models.ModelsGetAlarmStatusResponse)
 		return
 	}
 
+	if internal.NodeID != nil {
+		a.NodeID = uuid.UUID(internal.NodeID.String())
+	}
+
 	if internal.Status != nil {
 		a.Status = AlarmStatusType(*internal.Status)
 	}
@@ -77,6 +82,53 @@ func (a *AlarmStatus) FromInternal(internal models.ModelsGetAlarmStatusResponse)
 	}
 }
 
+func (a AlarmStatus) ToInternal() models.ModelsGetAlarmStatusResponse {
+	status := int32(a.Status)
+
+	internal := models.ModelsGetAlarmStatusResponse{
+		NodeID:            nil,
+		Status:            &status,
+		UpdatedAt:         a.UpdatedAt.UnixMilli(),
+		OverallAlarm:      nil,
+		RateOfChangeAlarm: nil,
+		InspectionAlarm:   nil,
+		ExternalAlarm:     nil,
+		BandAlarms:        make([]*models.ModelsGetAlarmStatusResponseBandAlarm, len(a.Band)),
+		HalAlarms:         make([]*models.ModelsGetAlarmStatusResponseHALAlarm, len(a.HAL)),
+	}
+
+	if a.NodeID != "" {
+		nodeID := strfmt.UUID(a.NodeID.String())
+		internal.NodeID = &nodeID
+	}
+
+	if a.Overall != nil {
+		internal.OverallAlarm = a.Overall.ToInternal()
+	}
+
+	if a.RateOfChange != nil {
+		internal.RateOfChangeAlarm = a.RateOfChange.ToInternal()
+	}
+
+	if a.Inspection != nil {
+		internal.InspectionAlarm = a.Inspection.ToInternal()
+	}
+
+	if a.External != nil {
+		internal.ExternalAlarm = a.External.ToInternal()
+	}
+
+	for i, status := range a.Band {
+		internal.BandAlarms[i] = status.ToInternal()
+	}
+
+	for i, status := range a.HAL {
+		internal.HalAlarms[i] = status.ToInternal()
+	}
+
+	return internal
+}
+
 func (g *GenericAlarmStatus) FromInternal(internal *models.ModelsGetAlarmStatusResponseGeneric) {
 	if g == nil || internal == nil {
 		return
@@ -89,6 +141,15 @@ func (g *GenericAlarmStatus) FromInternal(internal *models.ModelsGetAlarmStatusR
 	g.TriggeringMeasurement = uuid.UUID(internal.TriggeringMeasurement.String())
 }
 
+func (g GenericAlarmStatus) ToInternal() *models.ModelsGetAlarmStatusResponseGeneric {
+	status := int32(g.Status)
+
+	return &models.ModelsGetAlarmStatusResponseGeneric{
+		Status:                &status,
+		TriggeringMeasurement: strfmt.UUID(g.TriggeringMeasurement.String()),
+	}
+}
+
 func (g *GenericAlarmStatus) FromEvent(internal *events.GenericAlarm) {
 	if g == nil || internal == nil {
 		return
@@ -98,6 +159,13 @@ func (g *GenericAlarmStatus) FromEvent(internal *events.GenericAlarm) {
 	g.TriggeringMeasurement = internal.TriggeringMeasurement
 }
 
+func (g GenericAlarmStatus) ToEvent() *events.GenericAlarm {
+	return &events.GenericAlarm{
+		TriggeringMeasurement: g.TriggeringMeasurement,
+		Status:                int32(g.Status),
+	}
+}
+
 func (e *ExternalAlarmStatus) FromInternal(internal *models.ModelsGetAlarmStatusResponseExternal) {
 	if e == nil || internal == nil {
 		return
@@ -113,6 +181,23 @@ func (e *ExternalAlarmStatus) FromInternal(internal *models.ModelsGetAlarmStatus
 	}
 }
 
+func (e ExternalAlarmStatus) ToInternal() *models.ModelsGetAlarmStatusResponseExternal {
+	status := int32(e.Status)
+
+	internal := &models.ModelsGetAlarmStatusResponseExternal{
+		Status: &status,
+		SetBy:  nil,
+	}
+
+	if e.SetBy != nil {
+		setBy := strfmt.UUID(e.SetBy.String())
+
+		internal.SetBy = &setBy
+	}
+
+	return internal
+}
+
 func (e *ExternalAlarmStatus) FromEvent(internal *events.ExternalAlarm) {
 	if e == nil || internal == nil {
 		return
@@ -122,6 +207,13 @@ func (e *ExternalAlarmStatus) FromEvent(internal *events.ExternalAlarm) {
 	e.SetBy = internal.SetBy
 }
 
+func (e ExternalAlarmStatus) ToEvent() *events.ExternalAlarm {
+	return &events.ExternalAlarm{
+		Status: int32(e.Status),
+		SetBy:  e.SetBy,
+	}
+}
+
 func (e *ExternalAlarmStatus) ToSetRequest() models.ModelsSetExternalAlarmStatusRequest {
 	if e == nil {
 		return models.ModelsSetExternalAlarmStatusRequest{}
@@ -142,3 +234,73 @@ func (e *ExternalAlarmStatus) ToSetRequest() models.ModelsSetExternalAlarmStatus
 
 	return request
 }
+
+// Clone returns a deep copy of the alarm status.
+func (a AlarmStatus) Clone() AlarmStatus {
+	alarmStatus := a
+	alarmStatus.Overall = cloneGenericAlarmStatus(a.Overall)
+	alarmStatus.RateOfChange = cloneGenericAlarmStatus(a.RateOfChange)
+	alarmStatus.Inspection = cloneGenericAlarmStatus(a.Inspection)
+
+	if a.External != nil {
+		external := *a.External
+
+		if a.External.SetBy != nil {
+			setBy := *a.External.SetBy
+			external.SetBy = &setBy
+		}
+
+		alarmStatus.External = &external
+	}
+
+	if a.Band != nil {
+		alarmStatus.Band = make([]BandAlarmStatus, len(a.Band))
+
+		for i, band := range a.Band {
+			alarmStatus.Band[i] = band
+
+			if band.CalculatedOverall != nil {
+				calculatedOverall := *band.CalculatedOverall
+				alarmStatus.Band[i].CalculatedOverall = &calculatedOverall
+			}
+		}
+	}
+
+	if a.HAL != nil {
+		alarmStatus.HAL = make([]HALAlarmStatus, len(a.HAL))
+
+		for i, hal := range a.HAL {
+			alarmStatus.HAL[i] = hal
+			alarmStatus.HAL[i].HALIndex = cloneFloat64(hal.HALIndex)
+			alarmStatus.HAL[i].FaultFrequency = cloneFloat64(hal.FaultFrequency)
+			alarmStatus.HAL[i].RPMFactor = cloneFloat64(hal.RPMFactor)
+
+			if hal.Bearing != nil {
+				bearing := *hal.Bearing
+				alarmStatus.HAL[i].Bearing = &bearing
+			}
+
+			if hal.NumberOfHarmonicsUsed != nil {
+				numberOfHarmonicsUsed := *hal.NumberOfHarmonicsUsed
+				alarmStatus.HAL[i].NumberOfHarmonicsUsed = &numberOfHarmonicsUsed
+			}
+
+			if hal.ErrorDescription != nil {
+				errorDescription := *hal.ErrorDescription
+				alarmStatus.HAL[i].ErrorDescription = &errorDescription
+			}
+		}
+	}
+
+	return alarmStatus
+}
+
+func cloneGenericAlarmStatus(g *GenericAlarmStatus) *GenericAlarmStatus {
+	if g == nil {
+		return nil
+	}
+
+	v := *g
+
+	return &v
+}
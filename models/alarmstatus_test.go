@@ -36,6 +36,18 @@ func Test_AlarmStatus_FromInternal(t *testing.T) {
 				HAL:       []HALAlarmStatus{},
 			},
 		},
+		{
+			given: models.ModelsGetAlarmStatusResponse{
+				NodeID:    &triggeringMeasurement,
+				UpdatedAt: now.UnixMilli(),
+			},
+			expected: &AlarmStatus{
+				NodeID:    uuid.EmptyUUID,
+				UpdatedAt: now,
+				Band:      []BandAlarmStatus{},
+				HAL:       []HALAlarmStatus{},
+			},
+		},
 		{
 			given: models.ModelsGetAlarmStatusResponse{
 				UpdatedAt: now.UnixMilli(),
@@ -778,3 +790,123 @@ func Test_ExternalAlarmStatus_ToSetRequest_IsNil(t *testing.T) {
 		_ = status.ToSetRequest()
 	})
 }
+
+func Test_AlarmStatus_ToInternal(t *testing.T) {
+	t.Parallel()
+
+	var (
+		now   = time.UnixMilli(time.Now().UTC().UnixMilli()).UTC()
+		setBy = uuid.EmptyUUID
+	)
+
+	tests := []AlarmStatus{
+		{
+			UpdatedAt: now,
+			Band:      []BandAlarmStatus{},
+			HAL:       []HALAlarmStatus{},
+		},
+		{
+			Status:    AlarmStatusDanger,
+			UpdatedAt: now,
+			Overall: &GenericAlarmStatus{
+				TriggeringMeasurement: uuid.EmptyUUID,
+				Status:                AlarmStatusAlert,
+			},
+			RateOfChange: &GenericAlarmStatus{
+				TriggeringMeasurement: uuid.EmptyUUID,
+				Status:                AlarmStatusGood,
+			},
+			Inspection: &GenericAlarmStatus{
+				TriggeringMeasurement: uuid.EmptyUUID,
+				Status:                AlarmStatusNoData,
+			},
+			External: &ExternalAlarmStatus{
+				Status: AlarmStatusDanger,
+				SetBy:  &setBy,
+			},
+			Band: []BandAlarmStatus{
+				{
+					GenericAlarmStatus: GenericAlarmStatus{
+						TriggeringMeasurement: uuid.EmptyUUID,
+						Status:                AlarmStatusGood,
+					},
+					Label: "BPFO",
+					MinFrequency: BandAlarmFrequency{
+						ValueType: BandAlarmFrequencyFixed,
+						Value:     10,
+					},
+					MaxFrequency: BandAlarmFrequency{
+						ValueType: BandAlarmFrequencySpeedMultiple,
+						Value:     2,
+					},
+					CalculatedOverall: &BandAlarmStatusCalculatedOverall{
+						Unit:  "gE",
+						Value: 0.5,
+					},
+				},
+			},
+			HAL: []HALAlarmStatus{
+				{
+					GenericAlarmStatus: GenericAlarmStatus{
+						TriggeringMeasurement: uuid.EmptyUUID,
+						Status:                AlarmStatusAlert,
+					},
+					Label: "HAL",
+					Bearing: &Bearing{
+						Manufacturer: "SKF",
+						ModelNumber:  "6205",
+					},
+					HALIndex:              f64p(3),
+					FaultFrequency:        f64p(120),
+					RPMFactor:             f64p(1),
+					NumberOfHarmonicsUsed: i64p(4),
+					ErrorDescription:      stringp("none"),
+				},
+			},
+		},
+	}
+
+	for _, expected := range tests {
+		expected := expected
+
+		t.Run("", func(t *testing.T) {
+			actual := new(AlarmStatus)
+			actual.FromInternal(expected.ToInternal())
+
+			assert.Equal(t, &expected, actual)
+		})
+	}
+}
+
+func Test_AlarmStatusClone(t *testing.T) {
+	t.Parallel()
+
+	setBy := uuid.New()
+
+	given := AlarmStatus{
+		Status:   AlarmStatusAlert,
+		Overall:  &GenericAlarmStatus{Status: AlarmStatusAlert},
+		External: &ExternalAlarmStatus{Status: AlarmStatusGood, SetBy: &setBy},
+		Band: []BandAlarmStatus{
+			{Label: "BPFO", CalculatedOverall: &BandAlarmStatusCalculatedOverall{Unit: "gE", Value: 1}},
+		},
+		HAL: []HALAlarmStatus{
+			{Label: "global", HALIndex: f64p(1), Bearing: &Bearing{Manufacturer: "SKF"}},
+		},
+	}
+
+	actual := given.Clone()
+	assert.Equal(t, given, actual)
+
+	actual.Overall.Status = AlarmStatusDanger
+	*actual.External.SetBy = uuid.New()
+	actual.Band[0].CalculatedOverall.Value = 2
+	*actual.HAL[0].HALIndex = 2
+	actual.HAL[0].Bearing.Manufacturer = "other"
+
+	assert.Equal(t, AlarmStatusAlert, given.Overall.Status)
+	assert.Equal(t, setBy, *given.External.SetBy)
+	assert.Equal(t, 1.0, given.Band[0].CalculatedOverall.Value)
+	assert.Equal(t, 1.0, *given.HAL[0].HALIndex)
+	assert.Equal(t, "SKF", given.HAL[0].Bearing.Manufacturer)
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"fmt"
 
+	"github.com/go-openapi/strfmt"
 	"google.golang.org/protobuf/proto" //nolint:gci
 
 	"github.com/SKF/go-pas-client/internal/events"
@@ -13,26 +14,26 @@ import (
 
 type (
 	BandAlarm struct {
-		Label            string
-		MinFrequency     BandAlarmFrequency
-		MaxFrequency     BandAlarmFrequency
-		OverallThreshold *BandAlarmOverallThreshold
+		Label            string                     `json:"label" yaml:"label"`
+		MinFrequency     BandAlarmFrequency         `json:"minFrequency" yaml:"minFrequency"`
+		MaxFrequency     BandAlarmFrequency         `json:"maxFrequency" yaml:"maxFrequency"`
+		OverallThreshold *BandAlarmOverallThreshold `json:"overallThreshold,omitempty" yaml:"overallThreshold,omitempty"`
 	}
 
 	BandAlarmFrequency struct {
-		ValueType BandAlarmFrequencyValueType
-		Value     float64
+		ValueType BandAlarmFrequencyValueType `json:"valueType" yaml:"valueType"`
+		Value     float64                     `json:"value" yaml:"value"`
 	}
 
 	BandAlarmThreshold struct {
-		ValueType BandAlarmThresholdType
-		Value     float64
+		ValueType BandAlarmThresholdType `json:"valueType" yaml:"valueType"`
+		Value     float64                `json:"value" yaml:"value"`
 	}
 
 	BandAlarmOverallThreshold struct {
-		Unit        string
-		UpperAlert  *BandAlarmThreshold
-		UpperDanger *BandAlarmThreshold
+		Unit        string              `json:"unit" yaml:"unit"`
+		UpperAlert  *BandAlarmThreshold `json:"upperAlert,omitempty" yaml:"upperAlert,omitempty"`
+		UpperDanger *BandAlarmThreshold `json:"upperDanger,omitempty" yaml:"upperDanger,omitempty"`
 	}
 )
 
@@ -126,6 +127,15 @@ func (f BandAlarmFrequency) ToInternal() *models.ModelsBandAlarmFrequency {
 	}
 }
 
+func (f BandAlarmFrequency) ToInternalAlarmStatus() *models.ModelsGetAlarmStatusResponseFrequency {
+	valueType := int32(f.ValueType)
+
+	return &models.ModelsGetAlarmStatusResponseFrequency{
+		ValueType: &valueType,
+		Value:     &f.Value,
+	}
+}
+
 func (f *BandAlarmFrequency) FromProto(internal *pas.Frequency) {
 	if f == nil || internal == nil {
 		return
@@ -229,16 +239,16 @@ func (t *BandAlarmThreshold) FromProto(internal *pas.ThresholdValue) {
 
 type (
 	BandAlarmStatus struct {
-		GenericAlarmStatus
-		Label             string
-		MinFrequency      BandAlarmFrequency
-		MaxFrequency      BandAlarmFrequency
-		CalculatedOverall *BandAlarmStatusCalculatedOverall
+		GenericAlarmStatus `yaml:",inline"`
+		Label              string                            `json:"label" yaml:"label"`
+		MinFrequency       BandAlarmFrequency                `json:"minFrequency" yaml:"minFrequency"`
+		MaxFrequency       BandAlarmFrequency                `json:"maxFrequency" yaml:"maxFrequency"`
+		CalculatedOverall  *BandAlarmStatusCalculatedOverall `json:"calculatedOverall,omitempty" yaml:"calculatedOverall,omitempty"`
 	}
 
 	BandAlarmStatusCalculatedOverall struct {
-		Unit  string
-		Value float64
+		Unit  string  `json:"unit" yaml:"unit"`
+		Value float64 `json:"value" yaml:"value"`
 	}
 )
 
@@ -275,6 +285,30 @@ func (b *BandAlarmStatus) FromInternal(internal *models.ModelsGetAlarmStatusResp
 	}
 }
 
+func (b BandAlarmStatus) ToInternal() *models.ModelsGetAlarmStatusResponseBandAlarm {
+	status := int32(b.Status)
+
+	internal := &models.ModelsGetAlarmStatusResponseBandAlarm{
+		Label:                 b.Label,
+		Status:                &status,
+		TriggeringMeasurement: strfmt.UUID(b.TriggeringMeasurement.String()),
+		MinFrequency:          b.MinFrequency.ToInternalAlarmStatus(),
+		MaxFrequency:          b.MaxFrequency.ToInternalAlarmStatus(),
+		CalculatedOverall:     nil,
+	}
+
+	if b.CalculatedOverall != nil {
+		value := b.CalculatedOverall.Value
+
+		internal.CalculatedOverall = &models.ModelsBandCalculatedOverall{
+			Unit:  b.CalculatedOverall.Unit,
+			Value: &value,
+		}
+	}
+
+	return internal
+}
+
 func (b *BandAlarmStatus) FromEvent(internal events.BandAlarmStatus) {
 	if b == nil {
 		return
@@ -301,3 +335,81 @@ func (b *BandAlarmStatus) FromEvent(internal events.BandAlarmStatus) {
 		}
 	}
 }
+
+func (b BandAlarm) ToProto() ([]byte, error) {
+	internal := pas.BandAlarm{
+		Label:            b.Label,
+		MinFrequency:     b.MinFrequency.ToProto(),
+		MaxFrequency:     b.MaxFrequency.ToProto(),
+		OverallThreshold: nil,
+	}
+
+	if b.OverallThreshold != nil {
+		internal.OverallThreshold = b.OverallThreshold.ToProto()
+	}
+
+	buf, err := proto.Marshal(&internal)
+	if err != nil {
+		return nil, fmt.Errorf("encoding band alarm failed: %w", err)
+	}
+
+	return buf, nil
+}
+
+func (f BandAlarmFrequency) ToProto() *pas.Frequency {
+	return &pas.Frequency{
+		ValueType: pas.Frequency_FrequencyType(f.ValueType),
+		Value:     &pas.DoubleObject{Value: f.Value},
+	}
+}
+
+func (b BandAlarmOverallThreshold) ToProto() *pas.BandAlarmOverallThreshold {
+	internal := &pas.BandAlarmOverallThreshold{
+		Unit:        b.Unit,
+		UpperAlert:  nil,
+		UpperDanger: nil,
+	}
+
+	if b.UpperAlert != nil {
+		internal.UpperAlert = b.UpperAlert.ToProto()
+	}
+
+	if b.UpperDanger != nil {
+		internal.UpperDanger = b.UpperDanger.ToProto()
+	}
+
+	return internal
+}
+
+func (t BandAlarmThreshold) ToProto() *pas.ThresholdValue {
+	return &pas.ThresholdValue{
+		ValueType: pas.ThresholdValue_ThresholdValueType(t.ValueType),
+		Value:     &pas.DoubleObject{Value: t.Value},
+	}
+}
+
+func (b BandAlarmStatus) ToEvent() events.BandAlarmStatus {
+	internal := events.BandAlarmStatus{
+		Label:                 b.Label,
+		Status:                int32(b.Status),
+		TriggeringMeasurement: b.TriggeringMeasurement,
+		MinFrequency: events.Frequency{
+			ValueType: int32(b.MinFrequency.ValueType),
+			Value:     b.MinFrequency.Value,
+		},
+		MaxFrequency: events.Frequency{
+			ValueType: int32(b.MaxFrequency.ValueType),
+			Value:     b.MaxFrequency.Value,
+		},
+		CalculatedOverall: nil,
+	}
+
+	if b.CalculatedOverall != nil {
+		internal.CalculatedOverall = &events.CalculatedOverall{
+			Unit:  b.CalculatedOverall.Unit,
+			Value: b.CalculatedOverall.Value,
+		}
+	}
+
+	return internal
+}
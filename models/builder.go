@@ -0,0 +1,129 @@
+package models
+
+import (
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+// ThresholdBuilder builds a Threshold without having to deal with pointers,
+// the result is validated when calling Build.
+type ThresholdBuilder struct {
+	threshold Threshold
+}
+
+func NewThreshold() *ThresholdBuilder {
+	return &ThresholdBuilder{
+		threshold: Threshold{
+			NodeID:        uuid.EmptyUUID,
+			ThresholdType: ThresholdTypeNone,
+			Overall:       nil,
+			RateOfChange:  nil,
+			Inspection:    nil,
+			FullScale:     nil,
+			BandAlarms:    []BandAlarm{},
+			HALAlarms:     []HALAlarm{},
+			Origin:        nil,
+			ETag:          "",
+		},
+	}
+}
+
+func (b *ThresholdBuilder) NodeID(nodeID uuid.UUID) *ThresholdBuilder {
+	b.threshold.NodeID = nodeID
+
+	return b
+}
+
+func (b *ThresholdBuilder) OverallInWindow(unit string, outerLow, innerLow, innerHigh, outerHigh float64) *ThresholdBuilder {
+	b.threshold.ThresholdType = ThresholdTypeOverallInWindow
+	b.threshold.Overall = &Overall{
+		Unit:      unit,
+		OuterLow:  &outerLow,
+		InnerLow:  &innerLow,
+		InnerHigh: &innerHigh,
+		OuterHigh: &outerHigh,
+	}
+
+	return b
+}
+
+func (b *ThresholdBuilder) OverallOutOfWindow(unit string, outerLow, innerLow, innerHigh, outerHigh float64) *ThresholdBuilder {
+	b.threshold.ThresholdType = ThresholdTypeOverallOutOfWindow
+	b.threshold.Overall = &Overall{
+		Unit:      unit,
+		OuterLow:  &outerLow,
+		InnerLow:  &innerLow,
+		InnerHigh: &innerHigh,
+		OuterHigh: &outerHigh,
+	}
+
+	return b
+}
+
+func (b *ThresholdBuilder) RateOfChange(unit string, outerLow, innerLow, innerHigh, outerHigh float64) *ThresholdBuilder {
+	b.threshold.RateOfChange = &RateOfChange{
+		Unit:      unit,
+		OuterLow:  &outerLow,
+		InnerLow:  &innerLow,
+		InnerHigh: &innerHigh,
+		OuterHigh: &outerHigh,
+	}
+
+	return b
+}
+
+func (b *ThresholdBuilder) Inspection(choices ...InspectionChoice) *ThresholdBuilder {
+	b.threshold.ThresholdType = ThresholdTypeInspection
+	b.threshold.Inspection = &Inspection{
+		Choices: append([]InspectionChoice{}, choices...),
+	}
+
+	return b
+}
+
+func (b *ThresholdBuilder) FullScale(fullScale float64) *ThresholdBuilder {
+	b.threshold.FullScale = &fullScale
+
+	return b
+}
+
+func (b *ThresholdBuilder) Origin(origin Origin) *ThresholdBuilder {
+	b.threshold.Origin = &origin
+
+	return b
+}
+
+func (b *ThresholdBuilder) WithBandAlarm(bandAlarm BandAlarm) *ThresholdBuilder {
+	b.threshold.BandAlarms = append(b.threshold.BandAlarms, bandAlarm)
+
+	return b
+}
+
+func (b *ThresholdBuilder) WithHALAlarm(
+	label string,
+	halAlarmType HALAlarmType,
+	bearing *Bearing,
+	upperAlert, upperDanger float64,
+) *ThresholdBuilder {
+	b.threshold.HALAlarms = append(b.threshold.HALAlarms, HALAlarm{
+		Label:        label,
+		HALAlarmType: halAlarmType,
+		Bearing:      bearing,
+		UpperAlert:   &upperAlert,
+		UpperDanger:  &upperDanger,
+	})
+
+	return b
+}
+
+// Build validates and returns the threshold, see Threshold.Validate.
+func (b *ThresholdBuilder) Build() (Threshold, error) {
+	threshold := b.threshold
+	threshold.BandAlarms = append([]BandAlarm{}, b.threshold.BandAlarms...)
+	threshold.HALAlarms = append([]HALAlarm{}, b.threshold.HALAlarms...)
+
+	if err := threshold.Validate(); err != nil {
+		return Threshold{}, err
+	}
+
+	return threshold, nil
+}
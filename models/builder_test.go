@@ -0,0 +1,48 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ThresholdBuilder(t *testing.T) {
+	t.Parallel()
+
+	actual, err := NewThreshold().
+		OverallOutOfWindow("C", 10, 20, 50, 70).
+		FullScale(10).
+		WithBandAlarm(BandAlarm{
+			Label:        "BPFO",
+			MinFrequency: BandAlarmFrequency{ValueType: BandAlarmFrequencyFixed, Value: 100},
+			MaxFrequency: BandAlarmFrequency{ValueType: BandAlarmFrequencyFixed, Value: 200},
+			OverallThreshold: &BandAlarmOverallThreshold{
+				Unit:       "gE",
+				UpperAlert: &BandAlarmThreshold{ValueType: BandAlarmThresholdTypeRelativeFullscale, Value: 50},
+			},
+		}).
+		WithHALAlarm("global", HALAlarmTypeGlobal, nil, 1, 2).
+		Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, ThresholdTypeOverallOutOfWindow, actual.ThresholdType)
+	assert.Equal(t, &Overall{Unit: "C", OuterLow: f64p(10), InnerLow: f64p(20), InnerHigh: f64p(50), OuterHigh: f64p(70)}, actual.Overall)
+	assert.Equal(t, f64p(10), actual.FullScale)
+	assert.Len(t, actual.BandAlarms, 1)
+	assert.Equal(t, []HALAlarm{{Label: "global", HALAlarmType: HALAlarmTypeGlobal, UpperAlert: f64p(1), UpperDanger: f64p(2)}}, actual.HALAlarms)
+}
+
+func Test_ThresholdBuilder_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewThreshold().
+		OverallOutOfWindow("C", 10, 60, 50, 70).
+		Build()
+
+	var validationErr ValidationError
+
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []ValidationReason{{Name: "overall.innerHigh", Reason: "must be greater than innerLow"}}, validationErr.Reasons)
+}
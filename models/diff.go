@@ -0,0 +1,49 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/wI2L/jsondiff"
+)
+
+type diffOptions struct {
+	guarded bool
+}
+
+type DiffOption func(*diffOptions)
+
+// WithGuards prefixes every replace and remove operation with a test
+// operation on the old value, making the patch fail with a conflict instead
+// of overwriting concurrent modifications.
+func WithGuards() DiffOption {
+	return func(o *diffOptions) {
+		o.guarded = true
+	}
+}
+
+// Diff returns the patch which transforms threshold from into threshold to,
+// using the same representation as the PAS API.
+func Diff(from, to Threshold, opts ...DiffOption) (Patch, error) {
+	options := diffOptions{guarded: false}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	compareOpts := []jsondiff.Option{}
+
+	if options.guarded {
+		compareOpts = append(compareOpts, jsondiff.Invertible())
+	}
+
+	patch, err := jsondiff.Compare(from.ToInternal(), to.ToInternal(), compareOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("comparing thresholds failed: %w", err)
+	}
+
+	if patch == nil {
+		return Patch{}, nil
+	}
+
+	return patch, nil
+}
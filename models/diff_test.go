@@ -0,0 +1,97 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wI2L/jsondiff"
+
+	"github.com/SKF/go-pas-client/internal/jsonpatch"
+)
+
+func Test_Diff(t *testing.T) {
+	t.Parallel()
+
+	old := Threshold{
+		ThresholdType: ThresholdTypeOverallOutOfWindow,
+		Overall:       &Overall{Unit: "C", OuterHigh: f64p(70), InnerHigh: f64p(50)},
+		HALAlarms: []HALAlarm{
+			{Label: "global", HALAlarmType: HALAlarmTypeGlobal, UpperAlert: f64p(1)},
+		},
+	}
+
+	to := Threshold{
+		ThresholdType: ThresholdTypeOverallOutOfWindow,
+		Overall:       &Overall{Unit: "C", OuterHigh: f64p(80)},
+		HALAlarms: []HALAlarm{
+			{Label: "global", HALAlarmType: HALAlarmTypeGlobal, UpperAlert: f64p(1)},
+		},
+	}
+
+	tests := []struct {
+		opts     []DiffOption
+		expected Patch
+	}{
+		{
+			expected: Patch{
+				{Type: jsondiff.OperationRemove, Path: "/overall/innerHigh"},
+				{Type: jsondiff.OperationReplace, Path: "/overall/outerHigh", Value: float64(80)},
+			},
+		},
+		{
+			opts: []DiffOption{WithGuards()},
+			expected: Patch{
+				{Type: jsondiff.OperationTest, Path: "/overall/innerHigh", Value: float64(50)},
+				{Type: jsondiff.OperationRemove, Path: "/overall/innerHigh"},
+				{Type: jsondiff.OperationTest, Path: "/overall/outerHigh", Value: float64(70)},
+				{Type: jsondiff.OperationReplace, Path: "/overall/outerHigh", Value: float64(80)},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run("", func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := Diff(old, to, test.opts...)
+			require.NoError(t, err)
+
+			require.Len(t, actual, len(test.expected))
+
+			for i, operation := range test.expected {
+				assert.Equal(t, operation.Type, actual[i].Type)
+				assert.Equal(t, operation.Path, actual[i].Path)
+				assert.Equal(t, operation.Value, actual[i].Value)
+			}
+
+			document, err := json.Marshal(old.ToInternal())
+			require.NoError(t, err)
+
+			patched, err := jsonpatch.Apply(document, actual)
+			require.NoError(t, err)
+
+			expected, err := json.Marshal(to.ToInternal())
+			require.NoError(t, err)
+
+			assert.JSONEq(t, string(expected), string(patched))
+		})
+	}
+}
+
+func Test_Diff_Unchanged(t *testing.T) {
+	t.Parallel()
+
+	threshold := Threshold{
+		ThresholdType: ThresholdTypeOverallInWindow,
+		Overall:       &Overall{Unit: "C", OuterHigh: f64p(70)},
+	}
+
+	actual, err := Diff(threshold, threshold, WithGuards())
+	require.NoError(t, err)
+
+	assert.Empty(t, actual)
+}
@@ -3,7 +3,11 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/SKF/go-eventsource/v2/eventsource"
 	"github.com/SKF/go-pas-client/internal/events"
 	"github.com/SKF/go-utility/v2/uuid"
 )
@@ -18,9 +22,13 @@ const (
 	EventTypeAlarmStatus = "PointAlarmStatusEvent"
 )
 
+// ThresholdEvent is published when the threshold of a node is set, the
+// aggregate is the node the threshold belongs to.
 type ThresholdEvent struct {
 	AggregateID uuid.UUID
 	UserID      uuid.UUID
+	SequenceID  string
+	Timestamp   time.Time
 	Threshold   Threshold
 }
 
@@ -35,11 +43,16 @@ func (t *ThresholdEvent) FromInternal(buf []byte) error {
 		return fmt.Errorf("failed to decode event: %w", err)
 	}
 
-	t.AggregateID = uuid.UUID(internal.BaseEvent.AggregateID)
-	t.UserID = uuid.UUID(internal.BaseEvent.UserID)
+	t.AggregateID, t.UserID, t.SequenceID, t.Timestamp = fromBaseEvent(internal.BaseEvent)
+	t.Threshold.NodeID = t.AggregateID
 	t.Threshold.ThresholdType = ThresholdType(internal.Type)
 	t.Threshold.FullScale = internal.FullScale
 
+	if internal.Origin != nil {
+		t.Threshold.Origin = new(Origin)
+		t.Threshold.Origin.FromEvent(internal.Origin)
+	}
+
 	if len(internal.Overall) > 0 {
 		t.Threshold.Overall = new(Overall)
 
@@ -83,9 +96,87 @@ func (t *ThresholdEvent) FromInternal(buf []byte) error {
 	return nil
 }
 
+func (t ThresholdEvent) ToInternal() (events.SetPointAlarmThresholdEvent, error) {
+	var err error
+
+	internal := events.SetPointAlarmThresholdEvent{
+		BaseEvent:    toBaseEvent(t.AggregateID, t.UserID, t.SequenceID, t.Timestamp),
+		Type:         int32(t.Threshold.ThresholdType),
+		Inspection:   nil,
+		FullScale:    t.Threshold.FullScale,
+		BandAlarms:   make([][]byte, len(t.Threshold.BandAlarms)),
+		HalAlarms:    make([][]byte, len(t.Threshold.HALAlarms)),
+		Overall:      nil,
+		RateOfChange: nil,
+		Origin:       nil,
+	}
+
+	if t.Threshold.Origin != nil {
+		internal.Origin = t.Threshold.Origin.ToEvent()
+	}
+
+	if t.Threshold.Overall != nil {
+		if internal.Overall, err = t.Threshold.Overall.ToProto(); err != nil {
+			return events.SetPointAlarmThresholdEvent{}, err
+		}
+
+		internal.Overall = present(internal.Overall)
+	}
+
+	if t.Threshold.RateOfChange != nil {
+		if internal.RateOfChange, err = t.Threshold.RateOfChange.ToProto(); err != nil {
+			return events.SetPointAlarmThresholdEvent{}, err
+		}
+
+		internal.RateOfChange = present(internal.RateOfChange)
+	}
+
+	if t.Threshold.Inspection != nil {
+		if internal.Inspection, err = t.Threshold.Inspection.ToProto(); err != nil {
+			return events.SetPointAlarmThresholdEvent{}, err
+		}
+
+		internal.Inspection = present(internal.Inspection)
+	}
+
+	for i, threshold := range t.Threshold.BandAlarms {
+		if internal.BandAlarms[i], err = threshold.ToProto(); err != nil {
+			return events.SetPointAlarmThresholdEvent{}, err
+		}
+	}
+
+	for i, threshold := range t.Threshold.HALAlarms {
+		if internal.HalAlarms[i], err = threshold.ToProto(); err != nil {
+			return events.SetPointAlarmThresholdEvent{}, err
+		}
+	}
+
+	return internal, nil
+}
+
+// Marshal encodes the event in the format published by the PAS service,
+// which can be decoded using FromInternal.
+func (t ThresholdEvent) Marshal() ([]byte, error) {
+	internal, err := t.ToInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := json.Marshal(internal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	return buf, nil
+}
+
+// AlarmStatusEvent is published when the alarm status of a node is updated,
+// the aggregate is the node the alarm status belongs to.
 type AlarmStatusEvent struct {
 	AggregateID uuid.UUID
 	UserID      uuid.UUID
+	SequenceID  string
+	Timestamp   time.Time
 	Changed     bool
 	AlarmStatus AlarmStatus
 }
@@ -102,10 +193,14 @@ func (a *AlarmStatusEvent) FromInternal(buf []byte) error {
 	}
 
 	a.AlarmStatus.Status = AlarmStatusType(internal.AlarmStatus)
-	a.AggregateID = uuid.UUID(internal.BaseEvent.AggregateID)
-	a.UserID = uuid.UUID(internal.BaseEvent.UserID)
+	a.AggregateID, a.UserID, a.SequenceID, a.Timestamp = fromBaseEvent(internal.BaseEvent)
+	a.AlarmStatus.NodeID = a.AggregateID
 	a.Changed = internal.AlarmsChanged
 
+	if internal.UpdatedAt != 0 {
+		a.AlarmStatus.UpdatedAt = time.UnixMilli(internal.UpdatedAt).UTC()
+	}
+
 	if internal.OverallAlarm != nil {
 		a.AlarmStatus.Overall = new(GenericAlarmStatus)
 		a.AlarmStatus.Overall.FromEvent(internal.OverallAlarm)
@@ -140,3 +235,108 @@ func (a *AlarmStatusEvent) FromInternal(buf []byte) error {
 
 	return nil
 }
+
+func (a AlarmStatusEvent) ToInternal() events.PointAlarmStatusEvent {
+	internal := events.PointAlarmStatusEvent{
+		BaseEvent:         toBaseEvent(a.AggregateID, a.UserID, a.SequenceID, a.Timestamp),
+		AlarmStatus:       int32(a.AlarmStatus.Status),
+		AlarmsChanged:     a.Changed,
+		UpdatedAt:         0,
+		BandAlarms:        make([]events.BandAlarmStatus, len(a.AlarmStatus.Band)),
+		HalAlarms:         make([]events.HalAlarmStatus, len(a.AlarmStatus.HAL)),
+		OverallAlarm:      nil,
+		ExternalAlarm:     nil,
+		InspectionAlarm:   nil,
+		RateOfChangeAlarm: nil,
+	}
+
+	if !a.AlarmStatus.UpdatedAt.IsZero() {
+		internal.UpdatedAt = a.AlarmStatus.UpdatedAt.UnixMilli()
+	}
+
+	if a.AlarmStatus.Overall != nil {
+		internal.OverallAlarm = a.AlarmStatus.Overall.ToEvent()
+	}
+
+	if a.AlarmStatus.RateOfChange != nil {
+		internal.RateOfChangeAlarm = a.AlarmStatus.RateOfChange.ToEvent()
+	}
+
+	if a.AlarmStatus.Inspection != nil {
+		internal.InspectionAlarm = a.AlarmStatus.Inspection.ToEvent()
+	}
+
+	if a.AlarmStatus.External != nil {
+		internal.ExternalAlarm = a.AlarmStatus.External.ToEvent()
+	}
+
+	for i, status := range a.AlarmStatus.Band {
+		internal.BandAlarms[i] = status.ToEvent()
+	}
+
+	for i, status := range a.AlarmStatus.HAL {
+		internal.HalAlarms[i] = status.ToEvent()
+	}
+
+	return internal
+}
+
+// Marshal encodes the event in the format published by the PAS service,
+// which can be decoded using FromInternal.
+func (a AlarmStatusEvent) Marshal() ([]byte, error) {
+	buf, err := json.Marshal(a.ToInternal())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	return buf, nil
+}
+
+// presenceField is a field number which isn't used by the messages of the
+// threshold sections, see present.
+const presenceField protowire.Number = protowire.MaxValidNumber
+
+// present makes the encoding of an empty section non-empty, as an empty
+// encoding is decoded as an absent section, like the sections of events
+// published by the PAS service. The section is encoded with an unknown field,
+// which is ignored when decoding.
+func present(buf []byte) []byte {
+	if len(buf) > 0 {
+		return buf
+	}
+
+	return protowire.AppendVarint(protowire.AppendTag(nil, presenceField, protowire.VarintType), 0)
+}
+
+// fromBaseEvent extracts the aggregate, user, sequence and timestamp of an
+// event, the timestamp is stored in nanoseconds by the event source.
+func fromBaseEvent(base *eventsource.BaseEvent) (aggregateID, userID uuid.UUID, sequenceID string, timestamp time.Time) {
+	if base == nil {
+		return
+	}
+
+	aggregateID = uuid.UUID(base.AggregateID)
+	userID = uuid.UUID(base.UserID)
+	sequenceID = base.SequenceID
+
+	if base.Timestamp != 0 {
+		timestamp = time.Unix(0, base.Timestamp).UTC()
+	}
+
+	return
+}
+
+func toBaseEvent(aggregateID, userID uuid.UUID, sequenceID string, timestamp time.Time) *eventsource.BaseEvent {
+	base := &eventsource.BaseEvent{
+		AggregateID: aggregateID.String(),
+		UserID:      userID.String(),
+		SequenceID:  sequenceID,
+		Timestamp:   0,
+	}
+
+	if !timestamp.IsZero() {
+		base.Timestamp = timestamp.UnixNano()
+	}
+
+	return base
+}
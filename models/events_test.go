@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -47,6 +48,7 @@ func Test_ThresholdEvent_FromInternal(t *testing.T) {
 				AggregateID: uuid.EmptyUUID,
 				UserID:      uuid.EmptyUUID,
 				Threshold: Threshold{
+					NodeID: uuid.EmptyUUID,
 					Overall: &Overall{
 						Unit:      "C",
 						OuterHigh: f64p(70),
@@ -85,6 +87,7 @@ func Test_ThresholdEvent_FromInternal(t *testing.T) {
 				AggregateID: uuid.EmptyUUID,
 				UserID:      uuid.EmptyUUID,
 				Threshold: Threshold{
+					NodeID: uuid.EmptyUUID,
 					RateOfChange: &RateOfChange{
 						Unit:      "gE",
 						OuterHigh: f64p(20),
@@ -117,6 +120,7 @@ func Test_ThresholdEvent_FromInternal(t *testing.T) {
 				AggregateID: uuid.EmptyUUID,
 				UserID:      uuid.EmptyUUID,
 				Threshold: Threshold{
+					NodeID: uuid.EmptyUUID,
 					Inspection: &Inspection{
 						Choices: []InspectionChoice{
 							{
@@ -145,6 +149,7 @@ func Test_ThresholdEvent_FromInternal(t *testing.T) {
 				AggregateID: uuid.EmptyUUID,
 				UserID:      uuid.EmptyUUID,
 				Threshold: Threshold{
+					NodeID: uuid.EmptyUUID,
 					BandAlarms: []BandAlarm{
 						{},
 					},
@@ -166,6 +171,7 @@ func Test_ThresholdEvent_FromInternal(t *testing.T) {
 				AggregateID: uuid.EmptyUUID,
 				UserID:      uuid.EmptyUUID,
 				Threshold: Threshold{
+					NodeID:     uuid.EmptyUUID,
 					BandAlarms: []BandAlarm{},
 					HALAlarms: []HALAlarm{
 						{},
@@ -173,6 +179,21 @@ func Test_ThresholdEvent_FromInternal(t *testing.T) {
 				},
 			},
 		},
+		{
+			set: func(t *testing.T, event *events.SetPointAlarmThresholdEvent) {
+				event.Origin = &events.Origin{ID: "template-1", Provider: "baseline", Type: "template"}
+			},
+			expected: &ThresholdEvent{
+				AggregateID: uuid.EmptyUUID,
+				UserID:      uuid.EmptyUUID,
+				Threshold: Threshold{
+					NodeID:     uuid.EmptyUUID,
+					BandAlarms: []BandAlarm{},
+					HALAlarms:  []HALAlarm{},
+					Origin:     &Origin{ID: "template-1", Provider: "baseline", Type: "template"},
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -312,6 +333,7 @@ func Test_AlarmStatusEvent_FromInternal(t *testing.T) {
 `),
 			expected: &AlarmStatusEvent{
 				AlarmStatus: AlarmStatus{
+					NodeID: uuid.EmptyUUID,
 					Status: AlarmStatusNotConfigured,
 					Band:   []BandAlarmStatus{},
 					HAL:    []HALAlarmStatus{},
@@ -330,6 +352,7 @@ func Test_AlarmStatusEvent_FromInternal(t *testing.T) {
 `),
 			expected: &AlarmStatusEvent{
 				AlarmStatus: AlarmStatus{
+					NodeID: uuid.EmptyUUID,
 					Status: AlarmStatusNoData,
 					Band:   []BandAlarmStatus{},
 					HAL:    []HALAlarmStatus{},
@@ -348,6 +371,7 @@ func Test_AlarmStatusEvent_FromInternal(t *testing.T) {
 `),
 			expected: &AlarmStatusEvent{
 				AlarmStatus: AlarmStatus{
+					NodeID: uuid.EmptyUUID,
 					Status: AlarmStatusGood,
 					Band:   []BandAlarmStatus{},
 					HAL:    []HALAlarmStatus{},
@@ -366,6 +390,7 @@ func Test_AlarmStatusEvent_FromInternal(t *testing.T) {
 `),
 			expected: &AlarmStatusEvent{
 				AlarmStatus: AlarmStatus{
+					NodeID: uuid.EmptyUUID,
 					Status: AlarmStatusAlert,
 					Band:   []BandAlarmStatus{},
 					HAL:    []HALAlarmStatus{},
@@ -384,6 +409,7 @@ func Test_AlarmStatusEvent_FromInternal(t *testing.T) {
 `),
 			expected: &AlarmStatusEvent{
 				AlarmStatus: AlarmStatus{
+					NodeID: uuid.EmptyUUID,
 					Status: AlarmStatusDanger,
 					Band:   []BandAlarmStatus{},
 					HAL:    []HALAlarmStatus{},
@@ -405,6 +431,7 @@ func Test_AlarmStatusEvent_FromInternal(t *testing.T) {
 `),
 			expected: &AlarmStatusEvent{
 				AlarmStatus: AlarmStatus{
+					NodeID: uuid.EmptyUUID,
 					Overall: &GenericAlarmStatus{
 						Status:                AlarmStatusGood,
 						TriggeringMeasurement: uuid.EmptyUUID,
@@ -429,6 +456,7 @@ func Test_AlarmStatusEvent_FromInternal(t *testing.T) {
 `),
 			expected: &AlarmStatusEvent{
 				AlarmStatus: AlarmStatus{
+					NodeID: uuid.EmptyUUID,
 					RateOfChange: &GenericAlarmStatus{
 						Status:                AlarmStatusGood,
 						TriggeringMeasurement: uuid.EmptyUUID,
@@ -453,6 +481,7 @@ func Test_AlarmStatusEvent_FromInternal(t *testing.T) {
 `),
 			expected: &AlarmStatusEvent{
 				AlarmStatus: AlarmStatus{
+					NodeID: uuid.EmptyUUID,
 					Inspection: &GenericAlarmStatus{
 						Status:                AlarmStatusGood,
 						TriggeringMeasurement: uuid.EmptyUUID,
@@ -476,6 +505,7 @@ func Test_AlarmStatusEvent_FromInternal(t *testing.T) {
 `),
 			expected: &AlarmStatusEvent{
 				AlarmStatus: AlarmStatus{
+					NodeID: uuid.EmptyUUID,
 					External: &ExternalAlarmStatus{
 						Status: AlarmStatusGood,
 					},
@@ -514,6 +544,7 @@ func Test_AlarmStatusEvent_FromInternal(t *testing.T) {
 `),
 			expected: &AlarmStatusEvent{
 				AlarmStatus: AlarmStatus{
+					NodeID: uuid.EmptyUUID,
 					Band: []BandAlarmStatus{
 						{
 							Label: "10x RPM",
@@ -561,7 +592,8 @@ func Test_AlarmStatusEvent_FromInternal(t *testing.T) {
 `),
 			expected: &AlarmStatusEvent{
 				AlarmStatus: AlarmStatus{
-					Band: []BandAlarmStatus{},
+					NodeID: uuid.EmptyUUID,
+					Band:   []BandAlarmStatus{},
 					HAL: []HALAlarmStatus{
 						{
 							Label: "10x RPM",
@@ -596,6 +628,34 @@ func Test_AlarmStatusEvent_FromInternal(t *testing.T) {
 	}
 }
 
+func Test_AlarmStatusEvent_FromInternal_BaseEvent(t *testing.T) {
+	t.Parallel()
+
+	given := []byte(`
+{
+	"aggregateId": "2b2f2bb1-0d2c-4b0a-9b55-1d0f6d5e3c11",
+	"userId": "00000000-0000-0000-0000-000000000000",
+	"sequenceId": "0000000042",
+	"timestamp": 1646397000123456789,
+	"alarmStatus": 2,
+	"updatedAt": 1646396999000
+}
+`)
+
+	actual := new(AlarmStatusEvent)
+
+	err := actual.FromInternal(given)
+	require.NoError(t, err)
+
+	nodeID := uuid.UUID("2b2f2bb1-0d2c-4b0a-9b55-1d0f6d5e3c11")
+
+	assert.Equal(t, nodeID, actual.AggregateID)
+	assert.Equal(t, nodeID, actual.AlarmStatus.NodeID)
+	assert.Equal(t, "0000000042", actual.SequenceID)
+	assert.Equal(t, time.Date(2022, time.March, 4, 12, 30, 0, 123456789, time.UTC), actual.Timestamp)
+	assert.Equal(t, time.Date(2022, time.March, 4, 12, 29, 59, 0, time.UTC), actual.AlarmStatus.UpdatedAt)
+}
+
 func Test_AlarmStatusEvent_FromInternal_IsNil(t *testing.T) {
 	t.Parallel()
 
@@ -615,3 +675,157 @@ func Test_AlarmStatusEvent_FromInternal_InvalidBody(t *testing.T) {
 
 	assert.Error(t, err)
 }
+
+func Test_ThresholdEvent_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	nodeID := uuid.New()
+
+	expected := ThresholdEvent{
+		AggregateID: nodeID,
+		UserID:      uuid.New(),
+		SequenceID:  "0000000001",
+		Timestamp:   time.Date(2022, time.March, 4, 12, 30, 0, 123456789, time.UTC),
+		Threshold: Threshold{
+			NodeID:        nodeID,
+			ThresholdType: ThresholdTypeOverallOutOfWindow,
+			Overall:       &Overall{Unit: "C", OuterHigh: f64p(70), InnerHigh: f64p(50), OuterLow: f64p(10)},
+			RateOfChange:  &RateOfChange{Unit: "C", OuterHigh: f64p(5)},
+			Inspection: &Inspection{
+				Choices: []InspectionChoice{{Answer: "ok", Instruction: "none", Status: AlarmStatusGood}},
+			},
+			FullScale: f64p(20),
+			BandAlarms: []BandAlarm{
+				{
+					Label:        "BPFO",
+					MinFrequency: BandAlarmFrequency{ValueType: BandAlarmFrequencySpeedMultiple, Value: 1},
+					MaxFrequency: BandAlarmFrequency{ValueType: BandAlarmFrequencySpeedMultiple, Value: 3},
+					OverallThreshold: &BandAlarmOverallThreshold{
+						Unit:        "gE",
+						UpperAlert:  &BandAlarmThreshold{ValueType: BandAlarmThresholdTypeAbsolute, Value: 2},
+						UpperDanger: &BandAlarmThreshold{ValueType: BandAlarmThresholdTypeRelativeFullscale, Value: 50},
+					},
+				},
+			},
+			HALAlarms: []HALAlarm{
+				{
+					Label:        "outer ring",
+					HALAlarmType: HALAlarmTypeFaultFrequency,
+					Bearing:      &Bearing{Manufacturer: "SKF", ModelNumber: "6205"},
+					UpperAlert:   f64p(1),
+					UpperDanger:  f64p(2),
+				},
+			},
+			Origin: &Origin{ID: "template-1", Provider: "baseline", Type: "template"},
+		},
+	}
+
+	buf, err := expected.Marshal()
+	require.NoError(t, err)
+
+	var actual ThresholdEvent
+
+	require.NoError(t, actual.FromInternal(buf))
+	assert.Equal(t, expected, actual)
+}
+
+func Test_ThresholdEvent_RoundTrip_EmptyThresholds(t *testing.T) {
+	t.Parallel()
+
+	nodeID := uuid.New()
+
+	expected := ThresholdEvent{
+		AggregateID: nodeID,
+		Threshold: Threshold{
+			NodeID:       nodeID,
+			Overall:      &Overall{},
+			RateOfChange: &RateOfChange{},
+			Inspection:   &Inspection{Choices: []InspectionChoice{}},
+			BandAlarms:   []BandAlarm{},
+			HALAlarms:    []HALAlarm{},
+		},
+	}
+
+	buf, err := expected.Marshal()
+	require.NoError(t, err)
+
+	var actual ThresholdEvent
+
+	require.NoError(t, actual.FromInternal(buf))
+	assert.Equal(t, expected, actual)
+
+	absent := ThresholdEvent{
+		AggregateID: nodeID,
+		Threshold:   Threshold{NodeID: nodeID, BandAlarms: []BandAlarm{}, HALAlarms: []HALAlarm{}},
+	}
+
+	buf, err = absent.Marshal()
+	require.NoError(t, err)
+
+	actual = ThresholdEvent{}
+
+	require.NoError(t, actual.FromInternal(buf))
+	assert.Equal(t, absent, actual)
+
+	// empty sections, as published by the PAS service, are absent
+	actual = ThresholdEvent{}
+
+	require.NoError(t, actual.FromInternal([]byte(`{"aggregateId":"`+nodeID.String()+
+		`","thresholdOverall":"","thresholdRateOfChange":"","inspection":""}`)))
+	assert.Nil(t, actual.Threshold.Overall)
+	assert.Nil(t, actual.Threshold.RateOfChange)
+	assert.Nil(t, actual.Threshold.Inspection)
+}
+
+func Test_AlarmStatusEvent_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	var (
+		setBy  = uuid.New()
+		nodeID = uuid.New()
+	)
+
+	expected := AlarmStatusEvent{
+		AggregateID: nodeID,
+		UserID:      uuid.New(),
+		SequenceID:  "0000000002",
+		Timestamp:   time.Date(2022, time.March, 4, 12, 30, 0, 123456789, time.UTC),
+		Changed:     true,
+		AlarmStatus: AlarmStatus{
+			NodeID:       nodeID,
+			UpdatedAt:    time.Date(2022, time.March, 4, 12, 29, 59, 0, time.UTC),
+			Status:       AlarmStatusDanger,
+			Overall:      &GenericAlarmStatus{TriggeringMeasurement: uuid.New(), Status: AlarmStatusAlert},
+			RateOfChange: &GenericAlarmStatus{TriggeringMeasurement: uuid.New(), Status: AlarmStatusGood},
+			Inspection:   &GenericAlarmStatus{TriggeringMeasurement: uuid.New(), Status: AlarmStatusNoData},
+			External:     &ExternalAlarmStatus{Status: AlarmStatusDanger, SetBy: &setBy},
+			Band: []BandAlarmStatus{
+				{
+					GenericAlarmStatus: GenericAlarmStatus{TriggeringMeasurement: uuid.New(), Status: AlarmStatusAlert},
+					Label:              "BPFO",
+					MinFrequency:       BandAlarmFrequency{ValueType: BandAlarmFrequencyFixed, Value: 100},
+					MaxFrequency:       BandAlarmFrequency{ValueType: BandAlarmFrequencyFixed, Value: 200},
+					CalculatedOverall:  &BandAlarmStatusCalculatedOverall{Unit: "gE", Value: 1.5},
+				},
+			},
+			HAL: []HALAlarmStatus{
+				{
+					GenericAlarmStatus: GenericAlarmStatus{TriggeringMeasurement: uuid.New(), Status: AlarmStatusGood},
+					Label:              "outer ring",
+					Bearing:            &Bearing{Manufacturer: "SKF", ModelNumber: "6205"},
+					HALIndex:           f64p(0.5),
+					FaultFrequency:     f64p(120),
+					RPMFactor:          f64p(1),
+				},
+			},
+		},
+	}
+
+	buf, err := expected.Marshal()
+	require.NoError(t, err)
+
+	var actual AlarmStatusEvent
+
+	require.NoError(t, actual.FromInternal(buf))
+	assert.Equal(t, expected, actual)
+}
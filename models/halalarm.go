@@ -3,6 +3,7 @@ package models
 import (
 	"fmt"
 
+	"github.com/go-openapi/strfmt"
 	"google.golang.org/protobuf/proto" //nolint:gci
 
 	"github.com/SKF/go-pas-client/internal/events"
@@ -13,16 +14,16 @@ import (
 
 type (
 	HALAlarm struct {
-		Label        string
-		Bearing      *Bearing
-		HALAlarmType HALAlarmType
-		UpperDanger  *float64
-		UpperAlert   *float64
+		Label        string       `json:"label" yaml:"label"`
+		Bearing      *Bearing     `json:"bearing,omitempty" yaml:"bearing,omitempty"`
+		HALAlarmType HALAlarmType `json:"halAlarmType" yaml:"halAlarmType"`
+		UpperDanger  *float64     `json:"upperDanger,omitempty" yaml:"upperDanger,omitempty"`
+		UpperAlert   *float64     `json:"upperAlert,omitempty" yaml:"upperAlert,omitempty"`
 	}
 
 	Bearing struct {
-		Manufacturer string
-		ModelNumber  string
+		Manufacturer string `json:"manufacturer" yaml:"manufacturer"`
+		ModelNumber  string `json:"modelNumber" yaml:"modelNumber"`
 	}
 )
 
@@ -100,14 +101,14 @@ func (h *HALAlarm) FromProto(buf []byte) error {
 }
 
 type HALAlarmStatus struct {
-	GenericAlarmStatus
-	Label                 string
-	Bearing               *Bearing
-	HALIndex              *float64
-	FaultFrequency        *float64
-	RPMFactor             *float64
-	NumberOfHarmonicsUsed *int64
-	ErrorDescription      *string
+	GenericAlarmStatus    `yaml:",inline"`
+	Label                 string   `json:"label" yaml:"label"`
+	Bearing               *Bearing `json:"bearing,omitempty" yaml:"bearing,omitempty"`
+	HALIndex              *float64 `json:"halIndex,omitempty" yaml:"halIndex,omitempty"`
+	FaultFrequency        *float64 `json:"faultFrequency,omitempty" yaml:"faultFrequency,omitempty"`
+	RPMFactor             *float64 `json:"rpmFactor,omitempty" yaml:"rpmFactor,omitempty"`
+	NumberOfHarmonicsUsed *int64   `json:"numberOfHarmonicsUsed,omitempty" yaml:"numberOfHarmonicsUsed,omitempty"`
+	ErrorDescription      *string  `json:"errorDescription,omitempty" yaml:"errorDescription,omitempty"`
 }
 
 func (h *HALAlarmStatus) FromInternal(internal *models.ModelsGetAlarmStatusResponseHALAlarm) {
@@ -141,6 +142,32 @@ func (h *HALAlarmStatus) FromInternal(internal *models.ModelsGetAlarmStatusRespo
 	h.ErrorDescription = internal.ErrorDescription
 }
 
+func (h HALAlarmStatus) ToInternal() *models.ModelsGetAlarmStatusResponseHALAlarm {
+	var (
+		label                 = h.Label
+		status                = int32(h.Status)
+		triggeringMeasurement = strfmt.UUID(h.TriggeringMeasurement.String())
+	)
+
+	internal := &models.ModelsGetAlarmStatusResponseHALAlarm{
+		Label:                 &label,
+		Status:                &status,
+		TriggeringMeasurement: &triggeringMeasurement,
+		Bearing:               nil,
+		FaultFrequency:        h.FaultFrequency,
+		RpmFactor:             h.RPMFactor,
+		HalIndex:              h.HALIndex,
+		NumberOfHarmonicsUsed: h.NumberOfHarmonicsUsed,
+		ErrorDescription:      h.ErrorDescription,
+	}
+
+	if h.Bearing != nil {
+		internal.Bearing = h.Bearing.ToInternal()
+	}
+
+	return internal
+}
+
 func (h *HALAlarmStatus) FromEvent(internal events.HalAlarmStatus) {
 	if h == nil {
 		return
@@ -163,3 +190,50 @@ func (h *HALAlarmStatus) FromEvent(internal events.HalAlarmStatus) {
 	h.NumberOfHarmonicsUsed = internal.NumberOfHarmonicsUsed
 	h.ErrorDescription = internal.ErrorDescription
 }
+
+func (h HALAlarm) ToProto() ([]byte, error) {
+	internal := pas.HalAlarm{
+		Label:        h.Label,
+		HalAlarmType: string(h.HALAlarmType),
+		UpperAlert:   doubleObject(h.UpperAlert),
+		UpperDanger:  doubleObject(h.UpperDanger),
+		Bearing:      nil,
+	}
+
+	if h.Bearing != nil {
+		internal.Bearing = &pas.Bearing{
+			Manufacturer: h.Bearing.Manufacturer,
+			ModelNumber:  h.Bearing.ModelNumber,
+		}
+	}
+
+	buf, err := proto.Marshal(&internal)
+	if err != nil {
+		return nil, fmt.Errorf("encoding hal alarm failed: %w", err)
+	}
+
+	return buf, nil
+}
+
+func (h HALAlarmStatus) ToEvent() events.HalAlarmStatus {
+	internal := events.HalAlarmStatus{
+		Label:                 h.Label,
+		Status:                int32(h.Status),
+		TriggeringMeasurement: h.TriggeringMeasurement,
+		Bearing:               nil,
+		FaultFrequency:        h.FaultFrequency,
+		RpmFactor:             h.RPMFactor,
+		HALIndex:              h.HALIndex,
+		NumberOfHarmonicsUsed: h.NumberOfHarmonicsUsed,
+		ErrorDescription:      h.ErrorDescription,
+	}
+
+	if h.Bearing != nil {
+		internal.Bearing = &events.Bearing{
+			Manufacturer: h.Bearing.Manufacturer,
+			ModelNumber:  h.Bearing.ModelNumber,
+		}
+	}
+
+	return internal
+}
@@ -11,13 +11,13 @@ import (
 
 type (
 	Inspection struct {
-		Choices []InspectionChoice
+		Choices []InspectionChoice `json:"choices" yaml:"choices"`
 	}
 
 	InspectionChoice struct {
-		Answer      string
-		Instruction string
-		Status      AlarmStatusType
+		Answer      string          `json:"answer" yaml:"answer"`
+		Instruction string          `json:"instruction" yaml:"instruction"`
+		Status      AlarmStatusType `json:"status" yaml:"status"`
 	}
 )
 
@@ -93,3 +93,28 @@ func (i *InspectionChoice) FromProto(internal *pas.InspectionChoice) {
 	i.Instruction = internal.Instruction
 	i.Status = AlarmStatusType(internal.Status)
 }
+
+func (i Inspection) ToProto() ([]byte, error) {
+	internal := pas.Inspection{
+		Choices: make([]*pas.InspectionChoice, len(i.Choices)),
+	}
+
+	for idx, choice := range i.Choices {
+		internal.Choices[idx] = choice.ToProto()
+	}
+
+	buf, err := proto.Marshal(&internal)
+	if err != nil {
+		return nil, fmt.Errorf("encoding inspection alarm failed: %w", err)
+	}
+
+	return buf, nil
+}
+
+func (i InspectionChoice) ToProto() *pas.InspectionChoice {
+	return &pas.InspectionChoice{
+		Answer:      i.Answer,
+		Instruction: i.Instruction,
+		Status:      pas.AlarmStatus(i.Status),
+	}
+}
@@ -19,31 +19,31 @@ const (
 
 type (
 	Measurement struct {
-		MeasurementID   uuid.UUID
-		CreatedAt       time.Time
-		ContentType     ContentType
-		DataPoint       *DataPoint
-		Spectrum        *Spectrum
-		QuestionAnswers []string
-		RateOfChange    *float64
-		Tags            map[string]interface{}
+		MeasurementID   uuid.UUID              `json:"measurementId" yaml:"measurementId"`
+		CreatedAt       time.Time              `json:"createdAt" yaml:"createdAt"`
+		ContentType     ContentType            `json:"contentType" yaml:"contentType"`
+		DataPoint       *DataPoint             `json:"dataPoint,omitempty" yaml:"dataPoint,omitempty"`
+		Spectrum        *Spectrum              `json:"spectrum,omitempty" yaml:"spectrum,omitempty"`
+		QuestionAnswers []string               `json:"questionAnswers,omitempty" yaml:"questionAnswers,omitempty"`
+		RateOfChange    *float64               `json:"rateOfChange,omitempty" yaml:"rateOfChange,omitempty"`
+		Tags            map[string]interface{} `json:"tags,omitempty" yaml:"tags,omitempty"`
 	}
 
 	Coordinate struct {
-		X float64
-		Y float64
+		X float64 `json:"x" yaml:"x"`
+		Y float64 `json:"y" yaml:"y"`
 	}
 
 	DataPoint struct {
-		Coordinate Coordinate
-		XUnit      string
-		YUnit      string
+		Coordinate Coordinate `json:"coordinate" yaml:"coordinate"`
+		XUnit      string     `json:"xUnit" yaml:"xUnit"`
+		YUnit      string     `json:"yUnit" yaml:"yUnit"`
 	}
 
 	Spectrum struct {
-		XUnit string
-		YUnit string
-		Speed float64
+		XUnit string  `json:"xUnit" yaml:"xUnit"`
+		YUnit string  `json:"yUnit" yaml:"yUnit"`
+		Speed float64 `json:"speed" yaml:"speed"`
 	}
 )
 
@@ -96,3 +96,77 @@ func (m *Measurement) ToInternal() models.ModelsUpdateAlarmStatusRequest {
 
 	return internal
 }
+
+func (m *Measurement) FromInternal(internal models.ModelsUpdateAlarmStatusRequest) error {
+	if m == nil {
+		return nil
+	}
+
+	if internal.MeasurementID != nil {
+		m.MeasurementID = uuid.UUID(internal.MeasurementID.String())
+
+		if err := m.MeasurementID.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if internal.CreatedAt != nil {
+		m.CreatedAt = time.Time(*internal.CreatedAt).UTC()
+	}
+
+	if internal.ContentType != nil {
+		m.ContentType = ContentType(*internal.ContentType)
+	}
+
+	m.RateOfChange = internal.RateOfChange
+	m.Tags = internal.Tags
+	m.QuestionAnswers = nil
+	m.DataPoint = nil
+	m.Spectrum = nil
+
+	if length := len(internal.QuestionAnswers); length > 0 {
+		m.QuestionAnswers = make([]string, length)
+
+		copy(m.QuestionAnswers, internal.QuestionAnswers)
+	}
+
+	if internal.DataPoint != nil {
+		m.DataPoint = new(DataPoint)
+
+		if internal.DataPoint.XUnit != nil {
+			m.DataPoint.XUnit = *internal.DataPoint.XUnit
+		}
+
+		if internal.DataPoint.YUnit != nil {
+			m.DataPoint.YUnit = *internal.DataPoint.YUnit
+		}
+
+		if coordinate := internal.DataPoint.Coordinate; coordinate != nil {
+			if coordinate.X != nil {
+				m.DataPoint.Coordinate.X = *coordinate.X
+			}
+
+			if coordinate.Y != nil {
+				m.DataPoint.Coordinate.Y = *coordinate.Y
+			}
+		}
+	}
+
+	if internal.Spectrum != nil {
+		m.Spectrum = new(Spectrum)
+
+		if internal.Spectrum.XUnit != nil {
+			m.Spectrum.XUnit = *internal.Spectrum.XUnit
+		}
+
+		if internal.Spectrum.YUnit != nil {
+			m.Spectrum.YUnit = *internal.Spectrum.YUnit
+		}
+
+		if internal.Spectrum.Speed != nil {
+			m.Spectrum.Speed = *internal.Spectrum.Speed
+		}
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/go-openapi/strfmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	models "github.com/SKF/go-pas-client/internal/models"
 	"github.com/SKF/go-utility/v2/uuid"
@@ -139,3 +140,71 @@ func Test_Measurement_ToInternal_IsNil(t *testing.T) {
 		_ = m.ToInternal()
 	})
 }
+
+func Test_Measurement_FromInternal(t *testing.T) {
+	t.Parallel()
+
+	var (
+		now           = time.UnixMilli(time.Now().UnixMilli()).UTC()
+		measurementID = uuid.EmptyUUID
+	)
+
+	given := []*Measurement{
+		{
+			CreatedAt:     now,
+			MeasurementID: measurementID,
+			ContentType:   ContentTypeDataPoint,
+			DataPoint: &DataPoint{
+				Coordinate: Coordinate{
+					X: float64(now.UnixMilli()),
+					Y: 10.0,
+				},
+				XUnit: "ms",
+				YUnit: "gE",
+			},
+			RateOfChange: f64p(0.5),
+			Tags: map[string]interface{}{
+				"source": "unit-test",
+			},
+		},
+		{
+			CreatedAt:     now,
+			MeasurementID: measurementID,
+			ContentType:   ContentTypeSpectrum,
+			Spectrum: &Spectrum{
+				XUnit: "ms",
+				YUnit: "gE",
+				Speed: 1780.02,
+			},
+		},
+		{
+			CreatedAt:       now,
+			MeasurementID:   measurementID,
+			ContentType:     ContentTypeQuestionAnswers,
+			QuestionAnswers: []string{"good"},
+		},
+	}
+
+	for _, expected := range given {
+		expected := expected
+
+		t.Run("", func(t *testing.T) {
+			actual := new(Measurement)
+
+			err := actual.FromInternal(expected.ToInternal())
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func Test_Measurement_FromInternal_InvalidMeasurementID(t *testing.T) {
+	t.Parallel()
+
+	err := new(Measurement).FromInternal(models.ModelsUpdateAlarmStatusRequest{
+		MeasurementID: strfmtUUIDp("boop"),
+	})
+
+	assert.Error(t, err)
+}
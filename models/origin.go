@@ -0,0 +1,50 @@
+package models
+
+import (
+	"github.com/SKF/go-pas-client/internal/events"
+	models "github.com/SKF/go-pas-client/internal/models"
+)
+
+// Origin records which system authored a threshold, e.g. the ID of a template
+// together with the provider and type of the system which applied it.
+type Origin struct {
+	ID       string `json:"id,omitempty" yaml:"id,omitempty"`
+	Provider string `json:"provider,omitempty" yaml:"provider,omitempty"`
+	Type     string `json:"type,omitempty" yaml:"type,omitempty"`
+}
+
+func (o *Origin) FromInternal(internal *models.ModelsOrigin) {
+	if o == nil || internal == nil {
+		return
+	}
+
+	o.ID = internal.ID
+	o.Provider = internal.Provider
+	o.Type = internal.Type
+}
+
+func (o Origin) ToInternal() *models.ModelsOrigin {
+	return &models.ModelsOrigin{
+		ID:       o.ID,
+		Provider: o.Provider,
+		Type:     o.Type,
+	}
+}
+
+func (o *Origin) FromEvent(internal *events.Origin) {
+	if o == nil || internal == nil {
+		return
+	}
+
+	o.ID = internal.ID
+	o.Provider = internal.Provider
+	o.Type = internal.Type
+}
+
+func (o Origin) ToEvent() *events.Origin {
+	return &events.Origin{
+		ID:       o.ID,
+		Provider: o.Provider,
+		Type:     o.Type,
+	}
+}
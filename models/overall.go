@@ -11,11 +11,11 @@ import (
 )
 
 type Overall struct {
-	OuterHigh *float64
-	InnerHigh *float64
-	InnerLow  *float64
-	OuterLow  *float64
-	Unit      string
+	OuterHigh *float64 `json:"outerHigh,omitempty" yaml:"outerHigh,omitempty"`
+	InnerHigh *float64 `json:"innerHigh,omitempty" yaml:"innerHigh,omitempty"`
+	InnerLow  *float64 `json:"innerLow,omitempty" yaml:"innerLow,omitempty"`
+	OuterLow  *float64 `json:"outerLow,omitempty" yaml:"outerLow,omitempty"`
+	Unit      string   `json:"unit" yaml:"unit"`
 }
 
 func (o *Overall) FromInternal(internal *models.ModelsOverall) {
@@ -71,3 +71,26 @@ func (o *Overall) FromProto(buf []byte) error {
 
 	return nil
 }
+
+func (o Overall) ToProto() ([]byte, error) {
+	buf, err := proto.Marshal(&pas.Overall{
+		Unit:      o.Unit,
+		OuterHigh: doubleObject(o.OuterHigh),
+		InnerHigh: doubleObject(o.InnerHigh),
+		InnerLow:  doubleObject(o.InnerLow),
+		OuterLow:  doubleObject(o.OuterLow),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding overall alarm failed: %w", err)
+	}
+
+	return buf, nil
+}
+
+func doubleObject(f *float64) *pas.DoubleObject {
+	if f == nil {
+		return nil
+	}
+
+	return &pas.DoubleObject{Value: *f}
+}
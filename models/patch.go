@@ -1,5 +1,385 @@
 package models
 
-import "github.com/wI2L/jsondiff"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/wI2L/jsondiff"
+
+	"github.com/SKF/go-pas-client/internal/jsonpatch"
+)
 
 type Patch = []jsondiff.Operation
+
+var (
+	ErrInvalidPatchPath   = errors.New("invalid patch path")
+	ErrPatchNotApplicable = errors.New("patch is not applicable")
+	ErrLabelNotFound      = errors.New("label not found")
+)
+
+type patchSchema map[string]patchSchema
+
+const patchSchemaIndex = "*"
+
+var (
+	limitsPatchSchema = patchSchema{
+		"unit":      nil,
+		"outerHigh": nil,
+		"innerHigh": nil,
+		"innerLow":  nil,
+		"outerLow":  nil,
+	}
+
+	valuePatchSchema = patchSchema{
+		"valueType": nil,
+		"value":     nil,
+	}
+
+	thresholdPatchSchema = patchSchema{
+		"thresholdType": nil,
+		"fullScale":     nil,
+		"overall":       limitsPatchSchema,
+		"rateOfChange":  limitsPatchSchema,
+		"inspection": {
+			"choices": {
+				patchSchemaIndex: {
+					"answer":      nil,
+					"instruction": nil,
+					"status":      nil,
+				},
+			},
+		},
+		"bandAlarms": {
+			patchSchemaIndex: {
+				"label":        nil,
+				"minFrequency": valuePatchSchema,
+				"maxFrequency": valuePatchSchema,
+				"overallThreshold": {
+					"unit":        nil,
+					"upperAlert":  valuePatchSchema,
+					"upperDanger": valuePatchSchema,
+				},
+			},
+		},
+		"halAlarms": {
+			patchSchemaIndex: {
+				"label":        nil,
+				"halAlarmType": nil,
+				"upperAlert":   nil,
+				"upperDanger":  nil,
+				"bearing": {
+					"manufacturer": nil,
+					"modelNumber":  nil,
+				},
+			},
+		},
+	}
+)
+
+func validatePatchPath(path string) error {
+	tokens, err := jsonpatch.Parse(path)
+	if err != nil || len(tokens) == 0 {
+		return fmt.Errorf("%w: %q", ErrInvalidPatchPath, path)
+	}
+
+	schema := thresholdPatchSchema
+
+	for _, token := range tokens {
+		if schema == nil {
+			return fmt.Errorf("%w: %q", ErrInvalidPatchPath, path)
+		}
+
+		if next, found := schema[token]; found {
+			schema = next
+
+			continue
+		}
+
+		next, isArray := schema[patchSchemaIndex]
+		if _, err := strconv.ParseUint(token, 10, 32); !isArray || (err != nil && token != "-") {
+			return fmt.Errorf("%w: %q", ErrInvalidPatchPath, path)
+		}
+
+		schema = next
+	}
+
+	return nil
+}
+
+// PatchBuilder builds a patch for a threshold. The operations are validated
+// against the threshold schema and applied to the current threshold as they
+// are added, any error is returned by Build.
+type PatchBuilder struct {
+	document []byte
+	guarded  bool
+	patch    Patch
+	err      error
+}
+
+// NewPatch creates a patch builder for the current threshold, which is used
+// to resolve labels into indices and to verify that the patch is applicable.
+func NewPatch(current Threshold) *PatchBuilder {
+	document, err := json.Marshal(current.ToInternal())
+
+	return &PatchBuilder{
+		document: document,
+		guarded:  false,
+		patch:    Patch{},
+		err:      err,
+	}
+}
+
+// Guarded prefixes every following replace and remove operation with a test
+// operation on the current value, making the patch fail with a conflict if
+// the threshold has been modified concurrently.
+func (b *PatchBuilder) Guarded() *PatchBuilder {
+	b.guarded = true
+
+	return b
+}
+
+func (b *PatchBuilder) Build() (Patch, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	return append(Patch{}, b.patch...), nil
+}
+
+func (b *PatchBuilder) Add(path string, value interface{}) *PatchBuilder {
+	return b.operation(jsondiff.Operation{Type: jsondiff.OperationAdd, Path: path, Value: value})
+}
+
+func (b *PatchBuilder) Replace(path string, value interface{}) *PatchBuilder {
+	return b.operation(jsondiff.Operation{Type: jsondiff.OperationReplace, Path: path, Value: value})
+}
+
+func (b *PatchBuilder) Remove(path string) *PatchBuilder {
+	return b.operation(jsondiff.Operation{Type: jsondiff.OperationRemove, Path: path})
+}
+
+func (b *PatchBuilder) Test(path string, value interface{}) *PatchBuilder {
+	return b.operation(jsondiff.Operation{Type: jsondiff.OperationTest, Path: path, Value: value})
+}
+
+func (b *PatchBuilder) SetThresholdType(thresholdType ThresholdType) *PatchBuilder {
+	return b.set("/thresholdType", int32(thresholdType))
+}
+
+func (b *PatchBuilder) ReplaceFullScale(fullScale float64) *PatchBuilder {
+	return b.set("/fullScale", fullScale)
+}
+
+func (b *PatchBuilder) RemoveFullScale() *PatchBuilder {
+	return b.Remove("/fullScale")
+}
+
+func (b *PatchBuilder) SetOverall(overall Overall) *PatchBuilder {
+	return b.set("/overall", overall.ToInternal())
+}
+
+func (b *PatchBuilder) RemoveOverall() *PatchBuilder {
+	return b.Remove("/overall")
+}
+
+func (b *PatchBuilder) ReplaceOverallOuterHigh(value float64) *PatchBuilder {
+	return b.set("/overall/outerHigh", value)
+}
+
+func (b *PatchBuilder) ReplaceOverallInnerHigh(value float64) *PatchBuilder {
+	return b.set("/overall/innerHigh", value)
+}
+
+func (b *PatchBuilder) ReplaceOverallInnerLow(value float64) *PatchBuilder {
+	return b.set("/overall/innerLow", value)
+}
+
+func (b *PatchBuilder) ReplaceOverallOuterLow(value float64) *PatchBuilder {
+	return b.set("/overall/outerLow", value)
+}
+
+func (b *PatchBuilder) SetRateOfChange(rateOfChange RateOfChange) *PatchBuilder {
+	return b.set("/rateOfChange", rateOfChange.ToInternal())
+}
+
+func (b *PatchBuilder) RemoveRateOfChange() *PatchBuilder {
+	return b.Remove("/rateOfChange")
+}
+
+func (b *PatchBuilder) ReplaceRateOfChangeOuterHigh(value float64) *PatchBuilder {
+	return b.set("/rateOfChange/outerHigh", value)
+}
+
+func (b *PatchBuilder) ReplaceRateOfChangeInnerHigh(value float64) *PatchBuilder {
+	return b.set("/rateOfChange/innerHigh", value)
+}
+
+func (b *PatchBuilder) ReplaceRateOfChangeInnerLow(value float64) *PatchBuilder {
+	return b.set("/rateOfChange/innerLow", value)
+}
+
+func (b *PatchBuilder) ReplaceRateOfChangeOuterLow(value float64) *PatchBuilder {
+	return b.set("/rateOfChange/outerLow", value)
+}
+
+func (b *PatchBuilder) SetInspection(inspection Inspection) *PatchBuilder {
+	return b.set("/inspection", inspection.ToInternal())
+}
+
+func (b *PatchBuilder) AddInspectionChoice(choice InspectionChoice) *PatchBuilder {
+	return b.append("/inspection/choices", choice.ToInternal())
+}
+
+func (b *PatchBuilder) RemoveInspectionChoiceByAnswer(answer string) *PatchBuilder {
+	return b.byKey("/inspection/choices", "answer", answer, func(path string) *PatchBuilder {
+		return b.Remove(path)
+	})
+}
+
+func (b *PatchBuilder) AddBandAlarm(bandAlarm BandAlarm) *PatchBuilder {
+	return b.append("/bandAlarms", bandAlarm.ToInternal())
+}
+
+func (b *PatchBuilder) ReplaceBandAlarmByLabel(label string, bandAlarm BandAlarm) *PatchBuilder {
+	return b.byKey("/bandAlarms", "label", label, func(path string) *PatchBuilder {
+		return b.Replace(path, bandAlarm.ToInternal())
+	})
+}
+
+func (b *PatchBuilder) RemoveBandAlarmByLabel(label string) *PatchBuilder {
+	return b.byKey("/bandAlarms", "label", label, func(path string) *PatchBuilder {
+		return b.Remove(path)
+	})
+}
+
+func (b *PatchBuilder) ReplaceBandAlarmUpperAlert(label string, threshold BandAlarmThreshold) *PatchBuilder {
+	return b.byKey("/bandAlarms", "label", label, func(path string) *PatchBuilder {
+		return b.set(path+"/overallThreshold/upperAlert", threshold.ToInternal())
+	})
+}
+
+func (b *PatchBuilder) ReplaceBandAlarmUpperDanger(label string, threshold BandAlarmThreshold) *PatchBuilder {
+	return b.byKey("/bandAlarms", "label", label, func(path string) *PatchBuilder {
+		return b.set(path+"/overallThreshold/upperDanger", threshold.ToInternal())
+	})
+}
+
+func (b *PatchBuilder) AddHALAlarm(halAlarm HALAlarm) *PatchBuilder {
+	return b.append("/halAlarms", halAlarm.ToInternal())
+}
+
+func (b *PatchBuilder) ReplaceHALAlarmByLabel(label string, halAlarm HALAlarm) *PatchBuilder {
+	return b.byKey("/halAlarms", "label", label, func(path string) *PatchBuilder {
+		return b.Replace(path, halAlarm.ToInternal())
+	})
+}
+
+func (b *PatchBuilder) RemoveHALAlarmByLabel(label string) *PatchBuilder {
+	return b.byKey("/halAlarms", "label", label, func(path string) *PatchBuilder {
+		return b.Remove(path)
+	})
+}
+
+func (b *PatchBuilder) ReplaceHALAlarmUpperAlert(label string, value float64) *PatchBuilder {
+	return b.byKey("/halAlarms", "label", label, func(path string) *PatchBuilder {
+		return b.set(path+"/upperAlert", value)
+	})
+}
+
+func (b *PatchBuilder) ReplaceHALAlarmUpperDanger(label string, value float64) *PatchBuilder {
+	return b.byKey("/halAlarms", "label", label, func(path string) *PatchBuilder {
+		return b.set(path+"/upperDanger", value)
+	})
+}
+
+// set replaces the value at path, or adds it if it's not set.
+func (b *PatchBuilder) set(path string, value interface{}) *PatchBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	if _, err := jsonpatch.Get(b.document, path); err != nil {
+		return b.Add(path, value)
+	}
+
+	return b.Replace(path, value)
+}
+
+// append adds the value to the end of the array at path, creating the array
+// if it's not set.
+func (b *PatchBuilder) append(path string, value interface{}) *PatchBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	if _, err := jsonpatch.Get(b.document, path); err != nil {
+		return b.Add(path, []interface{}{value})
+	}
+
+	return b.Add(path+"/-", value)
+}
+
+// byKey resolves the index of the element in the array at path whose key
+// equals value and guards it with a test operation, since the index is only
+// valid as long as the array is left unmodified.
+func (b *PatchBuilder) byKey(path, key, value string, operation func(string) *PatchBuilder) *PatchBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	array, _ := jsonpatch.Get(b.document, path)
+	elements, _ := array.([]interface{})
+
+	for idx, element := range elements {
+		if object, ok := element.(map[string]interface{}); ok && object[key] == value {
+			elementPath := fmt.Sprintf("%s/%d", path, idx)
+
+			b.Test(elementPath+"/"+key, value)
+
+			return operation(elementPath)
+		}
+	}
+
+	b.err = fmt.Errorf("%w: %s with %s %q", ErrLabelNotFound, path, key, value)
+
+	return b
+}
+
+func (b *PatchBuilder) operation(operation jsondiff.Operation) *PatchBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	if err := validatePatchPath(operation.Path); err != nil {
+		b.err = err
+
+		return b
+	}
+
+	operations := Patch{operation}
+
+	if b.guarded && (operation.Type == jsondiff.OperationReplace || operation.Type == jsondiff.OperationRemove) {
+		current, err := jsonpatch.Get(b.document, operation.Path)
+		if err != nil {
+			b.err = fmt.Errorf("%w: %s %s: %v", ErrPatchNotApplicable, operation.Type, operation.Path, err)
+
+			return b
+		}
+
+		operations = Patch{{Type: jsondiff.OperationTest, Path: operation.Path, Value: current}, operation}
+	}
+
+	document, err := jsonpatch.Apply(b.document, operations)
+	if err != nil {
+		b.err = fmt.Errorf("%w: %v", ErrPatchNotApplicable, err)
+
+		return b
+	}
+
+	b.document = document
+	b.patch = append(b.patch, operations...)
+
+	return b
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wI2L/jsondiff"
+)
+
+func Test_PatchBuilder(t *testing.T) {
+	t.Parallel()
+
+	current := Threshold{
+		ThresholdType: ThresholdTypeOverallOutOfWindow,
+		Overall:       &Overall{Unit: "C", OuterHigh: f64p(70)},
+		HALAlarms: []HALAlarm{
+			{Label: "first", HALAlarmType: HALAlarmTypeGlobal, UpperAlert: f64p(1)},
+			{Label: "second", HALAlarmType: HALAlarmTypeGlobal, UpperAlert: f64p(2)},
+		},
+	}
+
+	actual, err := NewPatch(current).
+		ReplaceOverallOuterHigh(80).
+		ReplaceOverallInnerHigh(60).
+		RemoveHALAlarmByLabel("second").
+		AddBandAlarm(BandAlarm{Label: "BPFO"}).
+		Build()
+	require.NoError(t, err)
+
+	expected := Patch{
+		{Type: jsondiff.OperationReplace, Path: "/overall/outerHigh", Value: float64(80)},
+		{Type: jsondiff.OperationAdd, Path: "/overall/innerHigh", Value: float64(60)},
+		{Type: jsondiff.OperationTest, Path: "/halAlarms/1/label", Value: "second"},
+		{Type: jsondiff.OperationRemove, Path: "/halAlarms/1"},
+		{Type: jsondiff.OperationAdd, Path: "/bandAlarms", Value: []interface{}{BandAlarm{Label: "BPFO"}.ToInternal()}},
+	}
+
+	assert.Equal(t, expected, actual)
+}
+
+func Test_PatchBuilder_Guarded(t *testing.T) {
+	t.Parallel()
+
+	current := Threshold{
+		ThresholdType: ThresholdTypeOverallOutOfWindow,
+		Overall:       &Overall{Unit: "C", OuterHigh: f64p(70)},
+	}
+
+	actual, err := NewPatch(current).
+		Guarded().
+		ReplaceOverallOuterHigh(80).
+		ReplaceOverallOuterHigh(90).
+		Build()
+	require.NoError(t, err)
+
+	expected := Patch{
+		{Type: jsondiff.OperationTest, Path: "/overall/outerHigh", Value: float64(70)},
+		{Type: jsondiff.OperationReplace, Path: "/overall/outerHigh", Value: float64(80)},
+		{Type: jsondiff.OperationTest, Path: "/overall/outerHigh", Value: float64(80)},
+		{Type: jsondiff.OperationReplace, Path: "/overall/outerHigh", Value: float64(90)},
+	}
+
+	assert.Equal(t, expected, actual)
+}
+
+func Test_PatchBuilder_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		given    *PatchBuilder
+		expected error
+	}{
+		{
+			given:    NewPatch(Threshold{}).Replace("/overall/outerHigh/value", 1),
+			expected: ErrInvalidPatchPath,
+		},
+		{
+			given:    NewPatch(Threshold{}).Replace("/bandAlarms/first/label", "a"),
+			expected: ErrInvalidPatchPath,
+		},
+		{
+			given:    NewPatch(Threshold{}).Remove(""),
+			expected: ErrInvalidPatchPath,
+		},
+		{
+			given:    NewPatch(Threshold{}).ReplaceOverallOuterHigh(80),
+			expected: ErrPatchNotApplicable,
+		},
+		{
+			given:    NewPatch(Threshold{}).RemoveHALAlarmByLabel("missing"),
+			expected: ErrLabelNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run("", func(t *testing.T) {
+			t.Parallel()
+
+			_, err := test.given.Build()
+
+			assert.ErrorIs(t, err, test.expected)
+		})
+	}
+}
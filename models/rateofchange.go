@@ -11,11 +11,11 @@ import (
 )
 
 type RateOfChange struct {
-	OuterHigh *float64
-	InnerHigh *float64
-	InnerLow  *float64
-	OuterLow  *float64
-	Unit      string
+	OuterHigh *float64 `json:"outerHigh,omitempty" yaml:"outerHigh,omitempty"`
+	InnerHigh *float64 `json:"innerHigh,omitempty" yaml:"innerHigh,omitempty"`
+	InnerLow  *float64 `json:"innerLow,omitempty" yaml:"innerLow,omitempty"`
+	OuterLow  *float64 `json:"outerLow,omitempty" yaml:"outerLow,omitempty"`
+	Unit      string   `json:"unit" yaml:"unit"`
 }
 
 func (r *RateOfChange) FromInternal(internal *models.ModelsRateOfChange) {
@@ -71,3 +71,18 @@ func (r *RateOfChange) FromProto(buf []byte) error {
 
 	return nil
 }
+
+func (r RateOfChange) ToProto() ([]byte, error) {
+	buf, err := proto.Marshal(&pas.RateOfChange{
+		Unit:      r.Unit,
+		OuterHigh: doubleObject(r.OuterHigh),
+		InnerHigh: doubleObject(r.InnerHigh),
+		InnerLow:  doubleObject(r.InnerLow),
+		OuterLow:  doubleObject(r.OuterLow),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding rate of change alarm failed: %w", err)
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,167 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrUnknownEnumValue = errors.New("unknown enum value")
+
+// The enums are marshalled as their names, e.g. "Danger", in JSON and YAML.
+// Values without a name, which may be added to the PAS API later, are
+// marshalled as their number. Unmarshalling accepts both, and ignores the case
+// of names. In JSON the numbers are also accepted as JSON numbers, like the
+// enums were marshalled before, e.g. in files written by earlier versions.
+
+var (
+	alarmStatusNames = []string{"NotConfigured", "NoData", "Good", "Alert", "Danger"}
+
+	thresholdTypeNames = []string{"None", "OverallInWindow", "OverallOutOfWindow", "Inspection"}
+
+	bandAlarmFrequencyValueTypeNames = []string{"Unknown", "Fixed", "SpeedMultiple"}
+
+	bandAlarmThresholdTypeNames = []string{"Unknown", "Absolute", "RelativeFullscale"}
+)
+
+func (s AlarmStatusType) String() string {
+	return enumName(alarmStatusNames, int32(s))
+}
+
+func (s AlarmStatusType) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *AlarmStatusType) UnmarshalText(text []byte) error {
+	value, err := parseEnum(alarmStatusNames, "alarm status", text)
+	*s = AlarmStatusType(value)
+
+	return err
+}
+
+func (s *AlarmStatusType) UnmarshalJSON(buf []byte) error {
+	text, err := enumJSONText(buf)
+	if err != nil || text == nil {
+		return err
+	}
+
+	return s.UnmarshalText(text)
+}
+
+func (t ThresholdType) String() string {
+	return enumName(thresholdTypeNames, int32(t))
+}
+
+func (t ThresholdType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *ThresholdType) UnmarshalText(text []byte) error {
+	value, err := parseEnum(thresholdTypeNames, "threshold type", text)
+	*t = ThresholdType(value)
+
+	return err
+}
+
+func (t *ThresholdType) UnmarshalJSON(buf []byte) error {
+	text, err := enumJSONText(buf)
+	if err != nil || text == nil {
+		return err
+	}
+
+	return t.UnmarshalText(text)
+}
+
+func (t BandAlarmFrequencyValueType) String() string {
+	return enumName(bandAlarmFrequencyValueTypeNames, int32(t))
+}
+
+func (t BandAlarmFrequencyValueType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *BandAlarmFrequencyValueType) UnmarshalText(text []byte) error {
+	value, err := parseEnum(bandAlarmFrequencyValueTypeNames, "band alarm frequency value type", text)
+	*t = BandAlarmFrequencyValueType(value)
+
+	return err
+}
+
+func (t *BandAlarmFrequencyValueType) UnmarshalJSON(buf []byte) error {
+	text, err := enumJSONText(buf)
+	if err != nil || text == nil {
+		return err
+	}
+
+	return t.UnmarshalText(text)
+}
+
+func (t BandAlarmThresholdType) String() string {
+	return enumName(bandAlarmThresholdTypeNames, int32(t))
+}
+
+func (t BandAlarmThresholdType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *BandAlarmThresholdType) UnmarshalText(text []byte) error {
+	value, err := parseEnum(bandAlarmThresholdTypeNames, "band alarm threshold type", text)
+	*t = BandAlarmThresholdType(value)
+
+	return err
+}
+
+func (t *BandAlarmThresholdType) UnmarshalJSON(buf []byte) error {
+	text, err := enumJSONText(buf)
+	if err != nil || text == nil {
+		return err
+	}
+
+	return t.UnmarshalText(text)
+}
+
+// enumJSONText returns the text of an enum in JSON, which is either a string
+// or a number, or nil if it's null.
+func enumJSONText(buf []byte) ([]byte, error) {
+	if bytes.Equal(buf, []byte("null")) {
+		return nil, nil
+	}
+
+	if len(buf) == 0 || buf[0] != '"' {
+		return buf, nil
+	}
+
+	var text string
+
+	if err := json.Unmarshal(buf, &text); err != nil {
+		return nil, fmt.Errorf("decoding enum failed: %w", err)
+	}
+
+	return []byte(text), nil
+}
+
+func enumName(names []string, value int32) string {
+	if value >= 0 && int(value) < len(names) {
+		return names[value]
+	}
+
+	return strconv.FormatInt(int64(value), 10)
+}
+
+func parseEnum(names []string, kind string, text []byte) (int32, error) {
+	for i, name := range names {
+		if strings.EqualFold(name, string(text)) {
+			return int32(i), nil
+		}
+	}
+
+	value, err := strconv.ParseInt(string(text), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s %q", ErrUnknownEnumValue, kind, text)
+	}
+
+	return int32(value), nil
+}
@@ -0,0 +1,262 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+func Test_Enums_Text(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value    interface{ MarshalText() ([]byte, error) }
+		expected string
+	}{
+		{value: AlarmStatusDanger, expected: "Danger"},
+		{value: AlarmStatusNotConfigured, expected: "NotConfigured"},
+		{value: ThresholdTypeOverallOutOfWindow, expected: "OverallOutOfWindow"},
+		{value: BandAlarmFrequencySpeedMultiple, expected: "SpeedMultiple"},
+		{value: BandAlarmThresholdTypeRelativeFullscale, expected: "RelativeFullscale"},
+		{value: AlarmStatusType(7), expected: "7"},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.expected, func(t *testing.T) {
+			t.Parallel()
+
+			text, err := test.value.MarshalText()
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, string(text))
+		})
+	}
+}
+
+func Test_AlarmStatusType_UnmarshalText(t *testing.T) {
+	t.Parallel()
+
+	var status AlarmStatusType
+
+	require.NoError(t, status.UnmarshalText([]byte("danger")))
+	assert.Equal(t, AlarmStatusDanger, status)
+
+	require.NoError(t, status.UnmarshalText([]byte("7")))
+	assert.Equal(t, AlarmStatusType(7), status)
+
+	assert.ErrorIs(t, status.UnmarshalText([]byte("Critical")), ErrUnknownEnumValue)
+}
+
+func textThreshold() Threshold {
+	return Threshold{
+		NodeID:        uuid.UUID("5ad5b0a4-7fe0-4b8c-9d28-2a8b6a0c2f5e"),
+		ThresholdType: ThresholdTypeOverallOutOfWindow,
+		Overall:       &Overall{Unit: "C", OuterHigh: f64p(70), InnerHigh: f64p(50), InnerLow: f64p(20), OuterLow: f64p(10)},
+		FullScale:     f64p(20),
+		BandAlarms: []BandAlarm{
+			{
+				Label:        "BPFO",
+				MinFrequency: BandAlarmFrequency{ValueType: BandAlarmFrequencySpeedMultiple, Value: 1},
+				MaxFrequency: BandAlarmFrequency{ValueType: BandAlarmFrequencySpeedMultiple, Value: 3},
+				OverallThreshold: &BandAlarmOverallThreshold{
+					Unit:        "gE",
+					UpperAlert:  &BandAlarmThreshold{ValueType: BandAlarmThresholdTypeAbsolute, Value: 2},
+					UpperDanger: &BandAlarmThreshold{ValueType: BandAlarmThresholdTypeRelativeFullscale, Value: 50},
+				},
+			},
+		},
+		HALAlarms: []HALAlarm{
+			{Label: "outer ring", HALAlarmType: HALAlarmTypeGlobal, UpperAlert: f64p(1), UpperDanger: f64p(2)},
+		},
+		Origin: &Origin{ID: "template-1", Provider: "baseline", Type: "template"},
+	}
+}
+
+func Test_Threshold_JSON(t *testing.T) {
+	t.Parallel()
+
+	given := textThreshold()
+	given.ETag = `"v1"`
+
+	buf, err := json.Marshal(given)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"nodeId": "5ad5b0a4-7fe0-4b8c-9d28-2a8b6a0c2f5e",
+		"thresholdType": "OverallOutOfWindow",
+		"overall": {"unit": "C", "outerHigh": 70, "innerHigh": 50, "innerLow": 20, "outerLow": 10},
+		"fullScale": 20,
+		"bandAlarms": [{
+			"label": "BPFO",
+			"minFrequency": {"valueType": "SpeedMultiple", "value": 1},
+			"maxFrequency": {"valueType": "SpeedMultiple", "value": 3},
+			"overallThreshold": {
+				"unit": "gE",
+				"upperAlert": {"valueType": "Absolute", "value": 2},
+				"upperDanger": {"valueType": "RelativeFullscale", "value": 50}
+			}
+		}],
+		"halAlarms": [{"label": "outer ring", "halAlarmType": "GLOBAL", "upperAlert": 1, "upperDanger": 2}],
+		"origin": {"id": "template-1", "provider": "baseline", "type": "template"}
+	}`, string(buf))
+
+	var actual Threshold
+
+	require.NoError(t, json.Unmarshal(buf, &actual))
+	assert.Equal(t, textThreshold(), actual, "the ETag is left out")
+}
+
+func Test_Threshold_JSON_Numbers(t *testing.T) {
+	t.Parallel()
+
+	// enums were marshalled as numbers before
+	given := `{
+		"nodeId": "5ad5b0a4-7fe0-4b8c-9d28-2a8b6a0c2f5e",
+		"thresholdType": 2,
+		"overall": {"unit": "C", "outerHigh": 70, "innerHigh": 50, "innerLow": 20, "outerLow": 10},
+		"fullScale": 20,
+		"bandAlarms": [{
+			"label": "BPFO",
+			"minFrequency": {"valueType": 2, "value": 1},
+			"maxFrequency": {"valueType": "SpeedMultiple", "value": 3},
+			"overallThreshold": {
+				"unit": "gE",
+				"upperAlert": {"valueType": 1, "value": 2},
+				"upperDanger": {"valueType": 2, "value": 50}
+			}
+		}],
+		"halAlarms": [{"label": "outer ring", "halAlarmType": "GLOBAL", "upperAlert": 1, "upperDanger": 2}],
+		"origin": {"id": "template-1", "provider": "baseline", "type": "template"}
+	}`
+
+	var actual Threshold
+
+	require.NoError(t, json.Unmarshal([]byte(given), &actual))
+	assert.Equal(t, textThreshold(), actual)
+}
+
+func Test_AlarmStatusType_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		given    string
+		expected AlarmStatusType
+		err      bool
+	}{
+		{given: `"Danger"`, expected: AlarmStatusDanger},
+		{given: `4`, expected: AlarmStatusDanger},
+		{given: `"4"`, expected: AlarmStatusDanger},
+		{given: `7`, expected: AlarmStatusType(7)},
+		{given: `null`, expected: AlarmStatusAlert},
+		{given: `4.5`, err: true},
+		{given: `"Critical"`, err: true},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.given, func(t *testing.T) {
+			t.Parallel()
+
+			status := AlarmStatusAlert
+
+			err := json.Unmarshal([]byte(test.given), &status)
+			if test.err {
+				assert.ErrorIs(t, err, ErrUnknownEnumValue)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, status)
+		})
+	}
+}
+
+func Test_Threshold_YAML(t *testing.T) {
+	t.Parallel()
+
+	buf, err := yaml.Marshal(textThreshold())
+	require.NoError(t, err)
+
+	assert.Contains(t, string(buf), "thresholdType: OverallOutOfWindow\n")
+
+	var actual Threshold
+
+	require.NoError(t, yaml.Unmarshal(buf, &actual))
+	assert.Equal(t, textThreshold(), actual)
+}
+
+func Test_AlarmStatus_YAML(t *testing.T) {
+	t.Parallel()
+
+	given := AlarmStatus{
+		NodeID:    uuid.UUID("5ad5b0a4-7fe0-4b8c-9d28-2a8b6a0c2f5e"),
+		Status:    AlarmStatusDanger,
+		UpdatedAt: time.Date(2022, time.March, 4, 12, 30, 0, 0, time.UTC),
+		Overall:   &GenericAlarmStatus{TriggeringMeasurement: uuid.New(), Status: AlarmStatusAlert},
+		Band: []BandAlarmStatus{
+			{
+				GenericAlarmStatus: GenericAlarmStatus{TriggeringMeasurement: uuid.New(), Status: AlarmStatusDanger},
+				Label:              "BPFO",
+				MinFrequency:       BandAlarmFrequency{ValueType: BandAlarmFrequencyFixed, Value: 10},
+				MaxFrequency:       BandAlarmFrequency{ValueType: BandAlarmFrequencyFixed, Value: 20},
+			},
+		},
+		HAL:      []HALAlarmStatus{},
+		External: &ExternalAlarmStatus{Status: AlarmStatusGood, SetBy: nil},
+	}
+
+	buf, err := yaml.Marshal(given)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(buf), "status: Danger\n")
+	assert.Contains(t, string(buf), "updatedAt: 2022-03-04T12:30:00Z\n")
+
+	var actual AlarmStatus
+
+	require.NoError(t, yaml.Unmarshal(buf, &actual))
+	assert.Equal(t, given, actual)
+
+	buf, err = json.Marshal(given)
+	require.NoError(t, err)
+
+	actual = AlarmStatus{}
+
+	require.NoError(t, json.Unmarshal(buf, &actual))
+	assert.Equal(t, given, actual)
+}
+
+func Test_Measurement_JSON(t *testing.T) {
+	t.Parallel()
+
+	given := Measurement{
+		MeasurementID: uuid.New(),
+		CreatedAt:     time.Date(2022, time.March, 4, 12, 30, 0, 0, time.UTC),
+		ContentType:   ContentTypeDataPoint,
+		DataPoint: &DataPoint{
+			Coordinate: Coordinate{X: 1, Y: 2.5},
+			XUnit:      "s",
+			YUnit:      "gE",
+		},
+	}
+
+	buf, err := json.Marshal(given)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(buf), `"createdAt":"2022-03-04T12:30:00Z"`)
+	assert.Contains(t, string(buf), `"dataPoint":{"coordinate":{"x":1,"y":2.5},"xUnit":"s","yUnit":"gE"}`)
+
+	var actual Measurement
+
+	require.NoError(t, json.Unmarshal(buf, &actual))
+	assert.Equal(t, given, actual)
+}
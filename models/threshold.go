@@ -6,14 +6,21 @@ import (
 )
 
 type Threshold struct {
-	NodeID        uuid.UUID
-	ThresholdType ThresholdType
-	Overall       *Overall
-	RateOfChange  *RateOfChange
-	Inspection    *Inspection
-	FullScale     *float64
-	BandAlarms    []BandAlarm
-	HALAlarms     []HALAlarm
+	NodeID        uuid.UUID     `json:"nodeId,omitempty" yaml:"nodeId,omitempty"`
+	ThresholdType ThresholdType `json:"thresholdType" yaml:"thresholdType"`
+	Overall       *Overall      `json:"overall,omitempty" yaml:"overall,omitempty"`
+	RateOfChange  *RateOfChange `json:"rateOfChange,omitempty" yaml:"rateOfChange,omitempty"`
+	Inspection    *Inspection   `json:"inspection,omitempty" yaml:"inspection,omitempty"`
+	FullScale     *float64      `json:"fullScale,omitempty" yaml:"fullScale,omitempty"`
+	BandAlarms    []BandAlarm   `json:"bandAlarms" yaml:"bandAlarms"`
+	HALAlarms     []HALAlarm    `json:"halAlarms" yaml:"halAlarms"`
+	Origin        *Origin       `json:"origin,omitempty" yaml:"origin,omitempty"`
+
+	// ETag identifies the version of the threshold read from the PAS API, it
+	// can be used to make a later write conditional using
+	// client.SetThresholdIfMatch or client.PatchThresholdIfMatch. It's
+	// left out when the threshold is marshalled.
+	ETag string `json:"-" yaml:"-"`
 }
 
 func (t *Threshold) FromInternal(internal models.ModelsGetPointAlarmThresholdResponse) (err error) {
@@ -79,6 +86,10 @@ func (t Threshold) ToInternal() models.ModelsSetPointAlarmThresholdRequest {
 		HalAlarms:     make([]*models.ModelsHALAlarm, len(t.HALAlarms)),
 	}
 
+	if t.Origin != nil {
+		threshold.Origin = t.Origin.ToInternal()
+	}
+
 	if t.Overall != nil {
 		threshold.Overall = t.Overall.ToInternal()
 	}
@@ -101,3 +112,93 @@ func (t Threshold) ToInternal() models.ModelsSetPointAlarmThresholdRequest {
 
 	return threshold
 }
+
+// Clone returns a deep copy of the threshold.
+func (t Threshold) Clone() Threshold {
+	threshold := t
+	threshold.FullScale = cloneFloat64(t.FullScale)
+
+	if t.Origin != nil {
+		origin := *t.Origin
+		threshold.Origin = &origin
+	}
+
+	if t.Overall != nil {
+		threshold.Overall = &Overall{
+			OuterHigh: cloneFloat64(t.Overall.OuterHigh),
+			InnerHigh: cloneFloat64(t.Overall.InnerHigh),
+			InnerLow:  cloneFloat64(t.Overall.InnerLow),
+			OuterLow:  cloneFloat64(t.Overall.OuterLow),
+			Unit:      t.Overall.Unit,
+		}
+	}
+
+	if t.RateOfChange != nil {
+		threshold.RateOfChange = &RateOfChange{
+			OuterHigh: cloneFloat64(t.RateOfChange.OuterHigh),
+			InnerHigh: cloneFloat64(t.RateOfChange.InnerHigh),
+			InnerLow:  cloneFloat64(t.RateOfChange.InnerLow),
+			OuterLow:  cloneFloat64(t.RateOfChange.OuterLow),
+			Unit:      t.RateOfChange.Unit,
+		}
+	}
+
+	if t.Inspection != nil {
+		threshold.Inspection = &Inspection{
+			Choices: append([]InspectionChoice(nil), t.Inspection.Choices...),
+		}
+	}
+
+	if t.BandAlarms != nil {
+		threshold.BandAlarms = make([]BandAlarm, len(t.BandAlarms))
+
+		for i, bandAlarm := range t.BandAlarms {
+			threshold.BandAlarms[i] = bandAlarm
+
+			if bandAlarm.OverallThreshold != nil {
+				threshold.BandAlarms[i].OverallThreshold = &BandAlarmOverallThreshold{
+					Unit:        bandAlarm.OverallThreshold.Unit,
+					UpperAlert:  cloneBandAlarmThreshold(bandAlarm.OverallThreshold.UpperAlert),
+					UpperDanger: cloneBandAlarmThreshold(bandAlarm.OverallThreshold.UpperDanger),
+				}
+			}
+		}
+	}
+
+	if t.HALAlarms != nil {
+		threshold.HALAlarms = make([]HALAlarm, len(t.HALAlarms))
+
+		for i, halAlarm := range t.HALAlarms {
+			threshold.HALAlarms[i] = halAlarm
+			threshold.HALAlarms[i].UpperAlert = cloneFloat64(halAlarm.UpperAlert)
+			threshold.HALAlarms[i].UpperDanger = cloneFloat64(halAlarm.UpperDanger)
+
+			if halAlarm.Bearing != nil {
+				bearing := *halAlarm.Bearing
+				threshold.HALAlarms[i].Bearing = &bearing
+			}
+		}
+	}
+
+	return threshold
+}
+
+func cloneFloat64(f *float64) *float64 {
+	if f == nil {
+		return nil
+	}
+
+	v := *f
+
+	return &v
+}
+
+func cloneBandAlarmThreshold(t *BandAlarmThreshold) *BandAlarmThreshold {
+	if t == nil {
+		return nil
+	}
+
+	v := *t
+
+	return &v
+}
@@ -557,3 +557,48 @@ func Test_Threshold_ToInternal(t *testing.T) {
 		})
 	}
 }
+
+func Test_ThresholdClone(t *testing.T) {
+	t.Parallel()
+
+	given := Threshold{
+		ThresholdType: ThresholdTypeOverallOutOfWindow,
+		Overall:       &Overall{Unit: "C", OuterHigh: f64p(70)},
+		RateOfChange:  &RateOfChange{Unit: "C", OuterHigh: f64p(10)},
+		Inspection:    &Inspection{Choices: []InspectionChoice{{Answer: "ok"}}},
+		FullScale:     f64p(10),
+		BandAlarms: []BandAlarm{
+			{
+				Label: "BPFO",
+				OverallThreshold: &BandAlarmOverallThreshold{
+					UpperAlert: &BandAlarmThreshold{ValueType: BandAlarmThresholdTypeAbsolute, Value: 1},
+				},
+			},
+		},
+		HALAlarms: []HALAlarm{
+			{Label: "global", UpperAlert: f64p(1), Bearing: &Bearing{Manufacturer: "SKF"}},
+		},
+		Origin: &Origin{ID: "template-1"},
+	}
+
+	actual := given.Clone()
+	require.Equal(t, given, actual)
+
+	*actual.Overall.OuterHigh = 80
+	*actual.RateOfChange.OuterHigh = 20
+	actual.Inspection.Choices[0].Answer = "not ok"
+	*actual.FullScale = 20
+	actual.BandAlarms[0].OverallThreshold.UpperAlert.Value = 2
+	*actual.HALAlarms[0].UpperAlert = 2
+	actual.HALAlarms[0].Bearing.Manufacturer = "other"
+	actual.Origin.ID = "template-2"
+
+	assert.Equal(t, 70.0, *given.Overall.OuterHigh)
+	assert.Equal(t, 10.0, *given.RateOfChange.OuterHigh)
+	assert.Equal(t, "ok", given.Inspection.Choices[0].Answer)
+	assert.Equal(t, 10.0, *given.FullScale)
+	assert.Equal(t, 1.0, given.BandAlarms[0].OverallThreshold.UpperAlert.Value)
+	assert.Equal(t, 1.0, *given.HALAlarms[0].UpperAlert)
+	assert.Equal(t, "SKF", given.HALAlarms[0].Bearing.Manufacturer)
+	assert.Equal(t, "template-1", given.Origin.ID)
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+type AlarmComponent string
+
+const (
+	AlarmComponentOverall      AlarmComponent = "overall"
+	AlarmComponentRateOfChange AlarmComponent = "rateOfChange"
+	AlarmComponentInspection   AlarmComponent = "inspection"
+	AlarmComponentExternal     AlarmComponent = "external"
+	AlarmComponentBand         AlarmComponent = "band"
+	AlarmComponentHAL          AlarmComponent = "hal"
+)
+
+// AlarmTransition is a change of status of a single alarm of an alarm
+// status. Label is set for band and HAL alarms, and Bearing for HAL alarms.
+// TriggeringMeasurement is the measurement which caused the new status, it's
+// not set for external alarms.
+type AlarmTransition struct {
+	Component             AlarmComponent
+	Label                 string
+	Bearing               *Bearing
+	From                  AlarmStatusType
+	To                    AlarmStatusType
+	TriggeringMeasurement uuid.UUID
+}
+
+// Escalation reports whether the alarm went into a more severe alert or
+// danger status.
+func (t AlarmTransition) Escalation() bool {
+	return t.To > t.From && t.To >= AlarmStatusAlert
+}
+
+// Recovery reports whether the alarm went out of an alert or danger status
+// into a less severe status.
+func (t AlarmTransition) Recovery() bool {
+	return t.To < t.From && t.From >= AlarmStatusAlert
+}
+
+// CompareAlarmStatus returns the transitions of each alarm between two alarm
+// statuses of the same node. Alarms missing in either status are treated as
+// not configured. Band alarms are matched by label and HAL alarms by label and
+// bearing.
+func CompareAlarmStatus(prev, next AlarmStatus) []AlarmTransition {
+	transitions := []AlarmTransition{}
+
+	transitions = appendTransition(transitions,
+		AlarmTransition{Component: AlarmComponentOverall}, prev.Overall, next.Overall)
+	transitions = appendTransition(transitions,
+		AlarmTransition{Component: AlarmComponentRateOfChange}, prev.RateOfChange, next.RateOfChange)
+	transitions = appendTransition(transitions,
+		AlarmTransition{Component: AlarmComponentInspection}, prev.Inspection, next.Inspection)
+	transitions = appendTransition(transitions,
+		AlarmTransition{Component: AlarmComponentExternal}, externalStatus(prev.External), externalStatus(next.External))
+
+	for _, band := range next.Band {
+		band := band
+		transition := AlarmTransition{Component: AlarmComponentBand, Label: band.Label}
+
+		transitions = appendTransition(transitions, transition, findBand(prev.Band, band.Label), &band.GenericAlarmStatus)
+	}
+
+	for _, band := range prev.Band {
+		band := band
+
+		if findBand(next.Band, band.Label) == nil {
+			transition := AlarmTransition{Component: AlarmComponentBand, Label: band.Label}
+
+			transitions = appendTransition(transitions, transition, &band.GenericAlarmStatus, nil)
+		}
+	}
+
+	for _, hal := range next.HAL {
+		hal := hal
+		transition := AlarmTransition{Component: AlarmComponentHAL, Label: hal.Label, Bearing: hal.Bearing}
+
+		transitions = appendTransition(transitions,
+			transition, findHAL(prev.HAL, hal.Label, hal.Bearing), &hal.GenericAlarmStatus)
+	}
+
+	for _, hal := range prev.HAL {
+		hal := hal
+
+		if findHAL(next.HAL, hal.Label, hal.Bearing) == nil {
+			transition := AlarmTransition{Component: AlarmComponentHAL, Label: hal.Label, Bearing: hal.Bearing}
+
+			transitions = appendTransition(transitions, transition, &hal.GenericAlarmStatus, nil)
+		}
+	}
+
+	return transitions
+}
+
+// appendTransition completes the transition with the status of prev and next
+// and appends it if the status changed.
+func appendTransition(
+	transitions []AlarmTransition,
+	transition AlarmTransition,
+	prev, next *GenericAlarmStatus,
+) []AlarmTransition {
+	transition.From = AlarmStatusNotConfigured
+	transition.To = AlarmStatusNotConfigured
+
+	if prev != nil {
+		transition.From = prev.Status
+	}
+
+	if next != nil {
+		transition.To = next.Status
+		transition.TriggeringMeasurement = next.TriggeringMeasurement
+	}
+
+	if transition.From == transition.To {
+		return transitions
+	}
+
+	return append(transitions, transition)
+}
+
+func externalStatus(external *ExternalAlarmStatus) *GenericAlarmStatus {
+	if external == nil {
+		return nil
+	}
+
+	return &GenericAlarmStatus{TriggeringMeasurement: "", Status: external.Status}
+}
+
+func findBand(bands []BandAlarmStatus, label string) *GenericAlarmStatus {
+	for _, band := range bands {
+		if band.Label == label {
+			return &band.GenericAlarmStatus
+		}
+	}
+
+	return nil
+}
+
+func findHAL(hals []HALAlarmStatus, label string, bearing *Bearing) *GenericAlarmStatus {
+	for _, hal := range hals {
+		if hal.Label == label && sameBearing(hal.Bearing, bearing) {
+			return &hal.GenericAlarmStatus
+		}
+	}
+
+	return nil
+}
+
+func sameBearing(a, b *Bearing) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
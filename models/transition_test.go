@@ -0,0 +1,186 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+func Test_CompareAlarmStatus(t *testing.T) {
+	t.Parallel()
+
+	var (
+		measurement = uuid.New()
+		bearing     = &Bearing{Manufacturer: "SKF", ModelNumber: "6205"}
+	)
+
+	tests := []struct {
+		prev, next AlarmStatus
+		expected   []AlarmTransition
+	}{
+		{
+			prev:     AlarmStatus{},
+			next:     AlarmStatus{},
+			expected: []AlarmTransition{},
+		},
+		{
+			prev: AlarmStatus{
+				Overall: &GenericAlarmStatus{TriggeringMeasurement: uuid.New(), Status: AlarmStatusAlert},
+			},
+			next: AlarmStatus{
+				Overall: &GenericAlarmStatus{TriggeringMeasurement: measurement, Status: AlarmStatusAlert},
+			},
+			expected: []AlarmTransition{},
+		},
+		{
+			prev: AlarmStatus{
+				Overall:      &GenericAlarmStatus{Status: AlarmStatusGood},
+				RateOfChange: &GenericAlarmStatus{Status: AlarmStatusDanger},
+			},
+			next: AlarmStatus{
+				Overall:      &GenericAlarmStatus{TriggeringMeasurement: measurement, Status: AlarmStatusAlert},
+				RateOfChange: &GenericAlarmStatus{TriggeringMeasurement: measurement, Status: AlarmStatusGood},
+				Inspection:   &GenericAlarmStatus{TriggeringMeasurement: measurement, Status: AlarmStatusNoData},
+			},
+			expected: []AlarmTransition{
+				{
+					Component:             AlarmComponentOverall,
+					From:                  AlarmStatusGood,
+					To:                    AlarmStatusAlert,
+					TriggeringMeasurement: measurement,
+				},
+				{
+					Component:             AlarmComponentRateOfChange,
+					From:                  AlarmStatusDanger,
+					To:                    AlarmStatusGood,
+					TriggeringMeasurement: measurement,
+				},
+				{
+					Component:             AlarmComponentInspection,
+					From:                  AlarmStatusNotConfigured,
+					To:                    AlarmStatusNoData,
+					TriggeringMeasurement: measurement,
+				},
+			},
+		},
+		{
+			prev: AlarmStatus{
+				External: &ExternalAlarmStatus{Status: AlarmStatusGood},
+			},
+			next: AlarmStatus{
+				External: &ExternalAlarmStatus{Status: AlarmStatusDanger},
+			},
+			expected: []AlarmTransition{
+				{
+					Component: AlarmComponentExternal,
+					From:      AlarmStatusGood,
+					To:        AlarmStatusDanger,
+				},
+			},
+		},
+		{
+			prev: AlarmStatus{
+				Band: []BandAlarmStatus{
+					{Label: "BPFO", GenericAlarmStatus: GenericAlarmStatus{Status: AlarmStatusAlert}},
+					{Label: "BPFI", GenericAlarmStatus: GenericAlarmStatus{Status: AlarmStatusGood}},
+					{Label: "BSF", GenericAlarmStatus: GenericAlarmStatus{Status: AlarmStatusAlert}},
+				},
+			},
+			next: AlarmStatus{
+				Band: []BandAlarmStatus{
+					{Label: "BPFI", GenericAlarmStatus: GenericAlarmStatus{TriggeringMeasurement: measurement, Status: AlarmStatusGood}},
+					{Label: "BPFO", GenericAlarmStatus: GenericAlarmStatus{TriggeringMeasurement: measurement, Status: AlarmStatusDanger}},
+				},
+			},
+			expected: []AlarmTransition{
+				{
+					Component:             AlarmComponentBand,
+					Label:                 "BPFO",
+					From:                  AlarmStatusAlert,
+					To:                    AlarmStatusDanger,
+					TriggeringMeasurement: measurement,
+				},
+				{
+					Component: AlarmComponentBand,
+					Label:     "BSF",
+					From:      AlarmStatusAlert,
+					To:        AlarmStatusNotConfigured,
+				},
+			},
+		},
+		{
+			prev: AlarmStatus{
+				HAL: []HALAlarmStatus{
+					{Label: "outer ring", GenericAlarmStatus: GenericAlarmStatus{Status: AlarmStatusGood}},
+					{Label: "outer ring", Bearing: bearing, GenericAlarmStatus: GenericAlarmStatus{Status: AlarmStatusGood}},
+				},
+			},
+			next: AlarmStatus{
+				HAL: []HALAlarmStatus{
+					{Label: "outer ring", GenericAlarmStatus: GenericAlarmStatus{Status: AlarmStatusGood}},
+					{
+						Label:              "outer ring",
+						Bearing:            &Bearing{Manufacturer: "SKF", ModelNumber: "6205"},
+						GenericAlarmStatus: GenericAlarmStatus{TriggeringMeasurement: measurement, Status: AlarmStatusAlert},
+					},
+				},
+			},
+			expected: []AlarmTransition{
+				{
+					Component:             AlarmComponentHAL,
+					Label:                 "outer ring",
+					Bearing:               bearing,
+					From:                  AlarmStatusGood,
+					To:                    AlarmStatusAlert,
+					TriggeringMeasurement: measurement,
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run("", func(t *testing.T) {
+			t.Parallel()
+
+			actual := CompareAlarmStatus(test.prev, test.next)
+
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func Test_AlarmTransition_Direction(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		from, to   AlarmStatusType
+		escalation bool
+		recovery   bool
+	}{
+		{from: AlarmStatusGood, to: AlarmStatusAlert, escalation: true},
+		{from: AlarmStatusAlert, to: AlarmStatusDanger, escalation: true},
+		{from: AlarmStatusNotConfigured, to: AlarmStatusDanger, escalation: true},
+		{from: AlarmStatusDanger, to: AlarmStatusAlert, recovery: true},
+		{from: AlarmStatusAlert, to: AlarmStatusGood, recovery: true},
+		{from: AlarmStatusAlert, to: AlarmStatusNoData, recovery: true},
+		{from: AlarmStatusNoData, to: AlarmStatusGood},
+		{from: AlarmStatusGood, to: AlarmStatusNoData},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run("", func(t *testing.T) {
+			t.Parallel()
+
+			transition := AlarmTransition{From: test.from, To: test.to}
+
+			assert.Equal(t, test.escalation, transition.Escalation())
+			assert.Equal(t, test.recovery, transition.Recovery())
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	ValidationReason struct {
+		Name   string
+		Reason string
+	}
+
+	// ValidationError is returned when a threshold is rejected by Validate,
+	// the names of the reasons refer to the JSON fields of the PAS API.
+	ValidationError struct {
+		Reasons []ValidationReason
+	}
+)
+
+func (e ValidationError) Error() string {
+	reasons := make([]string, len(e.Reasons))
+
+	for i, reason := range e.Reasons {
+		reasons[i] = fmt.Sprintf("%s: %s", reason.Name, reason.Reason)
+	}
+
+	return "invalid threshold: " + strings.Join(reasons, ", ")
+}
+
+type validator struct {
+	reasons []ValidationReason
+}
+
+func (v *validator) add(name, format string, args ...interface{}) {
+	v.reasons = append(v.reasons, ValidationReason{
+		Name:   name,
+		Reason: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) err() error {
+	if len(v.reasons) == 0 {
+		return nil
+	}
+
+	return ValidationError{Reasons: v.reasons}
+}
+
+// Validate checks the threshold for errors which would otherwise be rejected
+// by the PAS API, it returns a ValidationError listing all problems found.
+func (t Threshold) Validate() error {
+	v := new(validator)
+
+	t.validateThresholdType(v)
+
+	if t.Overall != nil {
+		validateLimits(v, "overall", t.Overall.OuterLow, t.Overall.InnerLow, t.Overall.InnerHigh, t.Overall.OuterHigh)
+	}
+
+	if t.RateOfChange != nil {
+		validateLimits(v, "rateOfChange",
+			t.RateOfChange.OuterLow, t.RateOfChange.InnerLow, t.RateOfChange.InnerHigh, t.RateOfChange.OuterHigh)
+	}
+
+	if t.Inspection != nil {
+		t.Inspection.validate(v)
+	}
+
+	t.validateBandAlarms(v)
+	t.validateHALAlarms(v)
+
+	return v.err()
+}
+
+func (t Threshold) validateThresholdType(v *validator) {
+	switch t.ThresholdType {
+	case ThresholdTypeOverallInWindow, ThresholdTypeOverallOutOfWindow:
+		if t.Overall == nil {
+			v.add("overall", "must be set for threshold type %d", t.ThresholdType)
+		}
+
+		if t.Inspection != nil {
+			v.add("inspection", "must not be set for threshold type %d", t.ThresholdType)
+		}
+	case ThresholdTypeInspection:
+		if t.Inspection == nil {
+			v.add("inspection", "must be set for threshold type %d", t.ThresholdType)
+		}
+
+		if t.Overall != nil {
+			v.add("overall", "must not be set for threshold type %d", t.ThresholdType)
+		}
+	case ThresholdTypeNone:
+		if t.Overall != nil {
+			v.add("thresholdType", "must be overall in window or out of window when overall is set")
+		}
+
+		if t.Inspection != nil {
+			v.add("thresholdType", "must be inspection when inspection is set")
+		}
+	default:
+		v.add("thresholdType", "unknown threshold type %d", t.ThresholdType)
+	}
+}
+
+// validateLimits makes sure the limits which are set are strictly increasing
+// in the order outer low, inner low, inner high and outer high.
+func validateLimits(v *validator, name string, outerLow, innerLow, innerHigh, outerHigh *float64) {
+	limits := []struct {
+		name  string
+		value *float64
+	}{
+		{name: "outerLow", value: outerLow},
+		{name: "innerLow", value: innerLow},
+		{name: "innerHigh", value: innerHigh},
+		{name: "outerHigh", value: outerHigh},
+	}
+
+	for i, lower := range limits {
+		if lower.value == nil {
+			continue
+		}
+
+		for _, upper := range limits[i+1:] {
+			if upper.value == nil {
+				continue
+			}
+
+			if *lower.value >= *upper.value {
+				v.add(name+"."+upper.name, "must be greater than %s", lower.name)
+			}
+
+			break
+		}
+	}
+}
+
+func (i Inspection) validate(v *validator) {
+	if len(i.Choices) == 0 {
+		v.add("inspection.choices", "must not be empty")
+	}
+
+	answers := make(map[string]struct{}, len(i.Choices))
+
+	for idx, choice := range i.Choices {
+		if _, duplicate := answers[choice.Answer]; duplicate {
+			v.add(fmt.Sprintf("inspection.choices[%d].answer", idx), "duplicate answer %q", choice.Answer)
+		}
+
+		answers[choice.Answer] = struct{}{}
+	}
+}
+
+func (t Threshold) validateBandAlarms(v *validator) {
+	labels := make(map[string]struct{}, len(t.BandAlarms))
+
+	for idx, bandAlarm := range t.BandAlarms {
+		name := fmt.Sprintf("bandAlarms[%d]", idx)
+
+		if _, duplicate := labels[bandAlarm.Label]; duplicate {
+			v.add(name+".label", "duplicate label %q", bandAlarm.Label)
+		}
+
+		labels[bandAlarm.Label] = struct{}{}
+
+		if bandAlarm.MinFrequency.ValueType == bandAlarm.MaxFrequency.ValueType &&
+			bandAlarm.MinFrequency.Value >= bandAlarm.MaxFrequency.Value {
+			v.add(name+".maxFrequency", "must be greater than minFrequency")
+		}
+
+		if bandAlarm.OverallThreshold == nil || t.FullScale != nil {
+			continue
+		}
+
+		for _, threshold := range []*BandAlarmThreshold{
+			bandAlarm.OverallThreshold.UpperAlert,
+			bandAlarm.OverallThreshold.UpperDanger,
+		} {
+			if threshold != nil && threshold.ValueType == BandAlarmThresholdTypeRelativeFullscale {
+				v.add("fullScale", "must be set when %s uses a relative full scale threshold", name)
+
+				break
+			}
+		}
+	}
+}
+
+func (t Threshold) validateHALAlarms(v *validator) {
+	labels := make(map[string]struct{}, len(t.HALAlarms))
+
+	for idx, halAlarm := range t.HALAlarms {
+		if _, duplicate := labels[halAlarm.Label]; duplicate {
+			v.add(fmt.Sprintf("halAlarms[%d].label", idx), "duplicate label %q", halAlarm.Label)
+		}
+
+		labels[halAlarm.Label] = struct{}{}
+	}
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ThresholdValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		given    Threshold
+		expected []string
+	}{
+		{
+			given:    Threshold{},
+			expected: nil,
+		},
+		{
+			given: Threshold{
+				ThresholdType: ThresholdTypeOverallInWindow,
+				Overall:       &Overall{InnerHigh: f64p(50), OuterHigh: f64p(70)},
+			},
+			expected: nil,
+		},
+		{
+			given: Threshold{
+				ThresholdType: ThresholdTypeOverallOutOfWindow,
+				Overall:       &Overall{OuterLow: f64p(10), OuterHigh: f64p(5)},
+			},
+			expected: []string{"overall.outerHigh"},
+		},
+		{
+			given: Threshold{
+				RateOfChange: &RateOfChange{InnerLow: f64p(1), InnerHigh: f64p(1)},
+			},
+			expected: []string{"rateOfChange.innerHigh"},
+		},
+		{
+			given:    Threshold{ThresholdType: ThresholdTypeOverallOutOfWindow},
+			expected: []string{"overall"},
+		},
+		{
+			given:    Threshold{Overall: &Overall{}},
+			expected: []string{"thresholdType"},
+		},
+		{
+			given: Threshold{
+				ThresholdType: ThresholdTypeInspection,
+				Inspection:    &Inspection{Choices: []InspectionChoice{{Answer: "ok"}, {Answer: "ok"}}},
+			},
+			expected: []string{"inspection.choices[1].answer"},
+		},
+		{
+			given:    Threshold{ThresholdType: 42},
+			expected: []string{"thresholdType"},
+		},
+		{
+			given: Threshold{
+				BandAlarms: []BandAlarm{
+					{
+						Label:        "a",
+						MinFrequency: BandAlarmFrequency{ValueType: BandAlarmFrequencyFixed, Value: 200},
+						MaxFrequency: BandAlarmFrequency{ValueType: BandAlarmFrequencyFixed, Value: 100},
+					},
+					{
+						Label:        "a",
+						MinFrequency: BandAlarmFrequency{ValueType: BandAlarmFrequencySpeedMultiple, Value: 1},
+						MaxFrequency: BandAlarmFrequency{ValueType: BandAlarmFrequencySpeedMultiple, Value: 2},
+						OverallThreshold: &BandAlarmOverallThreshold{
+							UpperDanger: &BandAlarmThreshold{ValueType: BandAlarmThresholdTypeRelativeFullscale, Value: 80},
+						},
+					},
+				},
+			},
+			expected: []string{"bandAlarms[0].maxFrequency", "bandAlarms[1].label", "fullScale"},
+		},
+		{
+			given: Threshold{
+				HALAlarms: []HALAlarm{{Label: "a"}, {Label: "b"}, {Label: "a"}},
+			},
+			expected: []string{"halAlarms[2].label"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run("", func(t *testing.T) {
+			t.Parallel()
+
+			err := test.given.Validate()
+
+			if test.expected == nil {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			var validationErr ValidationError
+
+			require.True(t, errors.As(err, &validationErr))
+
+			names := make([]string, len(validationErr.Reasons))
+			for i, reason := range validationErr.Reasons {
+				names[i] = reason.Name
+			}
+
+			assert.Equal(t, test.expected, names)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Version orders the states of a node, e.g. the events of its aggregate, by
+// sequence ID when both have one and otherwise by timestamp.
+type Version struct {
+	SequenceID string
+	Timestamp  time.Time
+}
+
+func (v Version) IsZero() bool {
+	return v.SequenceID == "" && v.Timestamp.IsZero()
+}
+
+func (v Version) After(other Version) bool {
+	if v.SequenceID != "" && other.SequenceID != "" {
+		return v.SequenceID > other.SequenceID
+	}
+
+	return v.Timestamp.After(other.Timestamp)
+}
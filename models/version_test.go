@@ -0,0 +1,58 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Version_After(t *testing.T) {
+	t.Parallel()
+
+	var (
+		earlier = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		later   = earlier.Add(time.Second)
+	)
+
+	tests := map[string]struct {
+		v, other Version
+		expected bool
+	}{
+		"later sequence ID": {
+			v:        Version{SequenceID: "02", Timestamp: earlier},
+			other:    Version{SequenceID: "01", Timestamp: later},
+			expected: true,
+		},
+		"same sequence ID": {
+			v:        Version{SequenceID: "01", Timestamp: later},
+			other:    Version{SequenceID: "01", Timestamp: earlier},
+			expected: false,
+		},
+		"later timestamp without sequence ID": {
+			v:        Version{SequenceID: "01", Timestamp: later},
+			other:    Version{SequenceID: "", Timestamp: earlier},
+			expected: true,
+		},
+		"same timestamp": {
+			v:        Version{SequenceID: "", Timestamp: earlier},
+			other:    Version{SequenceID: "", Timestamp: earlier},
+			expected: false,
+		},
+		"zero": {
+			v:        Version{SequenceID: "01", Timestamp: earlier},
+			other:    Version{SequenceID: "", Timestamp: time.Time{}},
+			expected: true,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, test.v.After(test.other))
+		})
+	}
+}
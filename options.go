@@ -0,0 +1,73 @@
+package client
+
+import (
+	"crypto/rand"
+	"time"
+
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-rest-utility/client/retry"
+)
+
+const (
+	defaultBatchConcurrency    = 10
+	defaultConflictBackoffBase = 25 * time.Millisecond
+	defaultConflictBackoffCap  = time.Second
+	defaultConflictRetries     = 5
+)
+
+// options holds the configuration which is specific to the PAS client and
+// therefore can't be stored on the underlying rest client.
+type options struct {
+	batchConcurrency int
+	conflictBackoff  retry.BackoffProvider
+	retryPolicy      *RetryPolicy
+	limits           limits
+
+	// thresholdValidators and alarmStatusValidators are nil unless
+	// conditional requests are enabled.
+	thresholdValidators   *validatorCache[models.Threshold]
+	alarmStatusValidators *validatorCache[models.AlarmStatus]
+}
+
+func defaultOptions() *options {
+	return &options{
+		batchConcurrency: defaultBatchConcurrency,
+		conflictBackoff: &retry.ExponentialJitterBackoff{
+			Base:         defaultConflictBackoffBase,
+			Cap:          defaultConflictBackoffCap,
+			MaxAttempts:  defaultConflictRetries,
+			JitterSource: rand.Reader,
+		},
+		retryPolicy: nil,
+		limits:      limits{limiters: nil, observer: nil},
+
+		thresholdValidators:   nil,
+		alarmStatusValidators: nil,
+	}
+}
+
+// ClientOption configures the PAS client itself, e.g. WithBatchConcurrency,
+// see NewWithOptions.
+type ClientOption func(*options)
+
+// WithBatchConcurrency limits the number of concurrent requests made by
+// GetThresholds and GetAlarmStatuses, defaults to 10.
+func WithBatchConcurrency(n int) ClientOption {
+	return func(o *options) {
+		if n < 1 {
+			n = 1
+		}
+
+		o.batchConcurrency = n
+	}
+}
+
+// WithConflictBackoff sets the backoff used by UpdateThreshold between
+// retries of conflicting updates, the update fails once the backoff provider
+// returns an error. Defaults to an exponential backoff with jitter, retrying
+// up to 5 times.
+func WithConflictBackoff(backoff retry.BackoffProvider) ClientOption {
+	return func(o *options) {
+		o.conflictBackoff = backoff
+	}
+}
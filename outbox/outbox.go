@@ -0,0 +1,620 @@
+// Package outbox persists measurements on disk until they have been sent to
+// the PAS service, so they survive network outages and restarts.
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	pas "github.com/SKF/go-pas-client"
+	"github.com/SKF/go-pas-client/internal/jsonl"
+	internal_models "github.com/SKF/go-pas-client/internal/models"
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+const (
+	filePermissions = 0o600
+	maxLineSize     = 64 << 20
+	// compactionRatio is how many times larger than the entries left in the
+	// outbox the file may grow before it's compacted.
+	compactionRatio = 2
+)
+
+var (
+	// ErrFull is returned when a measurement doesn't fit in the outbox and
+	// the eviction policy is RejectNew.
+	ErrFull = errors.New("outbox is full")
+	// ErrEvicted is passed to the drop handler for measurements evicted to
+	// make room for newer ones.
+	ErrEvicted = errors.New("measurement evicted from outbox")
+)
+
+// Sender is used to send the measurements, it's implemented by the client.
+type Sender interface {
+	UpdateAlarmStatus(ctx context.Context, nodeID uuid.UUID, measurement *models.Measurement) error
+}
+
+var _ Sender = pas.API(nil)
+
+// Entry is a measurement waiting in the outbox.
+type Entry struct {
+	NodeID      uuid.UUID
+	Measurement *models.Measurement
+	EnqueuedAt  time.Time
+}
+
+type Stats struct {
+	// Depth is the number of measurements waiting to be sent.
+	Depth int
+	// Bytes is the size of the outbox file.
+	Bytes int64
+	// OldestAge is how long the oldest measurement has been waiting, zero if
+	// the outbox is empty.
+	OldestAge time.Duration
+}
+
+// EvictionPolicy decides what happens when a measurement doesn't fit within
+// the size limit of the outbox.
+type EvictionPolicy int
+
+const (
+	// EvictOldest evicts the oldest measurements until the new one fits.
+	EvictOldest EvictionPolicy = iota
+	// RejectNew rejects the new measurement with ErrFull.
+	RejectNew
+)
+
+type Option func(*Outbox)
+
+// WithMaxBytes limits the size of the outbox file, by default it's unlimited.
+func WithMaxBytes(n int64) Option {
+	return func(o *Outbox) {
+		o.maxBytes = n
+	}
+}
+
+// WithEvictionPolicy sets what happens when the outbox is full, defaults to
+// EvictOldest.
+func WithEvictionPolicy(policy EvictionPolicy) Option {
+	return func(o *Outbox) {
+		o.policy = policy
+	}
+}
+
+// WithDropHandler sets a function which is called for each measurement which
+// is removed from the outbox without being sent, either because it was
+// evicted or because the PAS service rejected it.
+func WithDropHandler(handler func(Entry, error)) Option {
+	return func(o *Outbox) {
+		o.onDrop = handler
+	}
+}
+
+// WithPermanentErrors sets which failures to send a measurement are
+// permanent, the measurement is then dropped instead of retried. Defaults to
+// pas.IsPermanent, which e.g. also drops measurements when the credentials
+// are refused.
+func WithPermanentErrors(permanent func(error) bool) Option {
+	return func(o *Outbox) {
+		o.permanent = permanent
+	}
+}
+
+// WithErrorHandler sets a function which is called with the error of each
+// failed replay made by Run.
+func WithErrorHandler(handler func(error)) Option {
+	return func(o *Outbox) {
+		o.onError = handler
+	}
+}
+
+type (
+	fileRecord struct {
+		Entry *fileEntry `json:"entry,omitempty"`
+		// Ack marks all entries up to and including the sequence number as
+		// removed.
+		Ack *uint64 `json:"ack,omitempty"`
+	}
+
+	fileEntry struct {
+		Seq         uint64                                          `json:"seq"`
+		NodeID      uuid.UUID                                       `json:"nodeId"`
+		EnqueuedAt  int64                                           `json:"enqueuedAt"`
+		Measurement *internal_models.ModelsUpdateAlarmStatusRequest `json:"measurement,omitempty"`
+		// CreatedAt keeps the full precision of the creation time of the
+		// measurement, which is truncated to milliseconds in Measurement.
+		CreatedAt string `json:"createdAt,omitempty"`
+	}
+)
+
+type entry struct {
+	Entry
+	seq  uint64
+	size int64
+}
+
+// Outbox is safe for concurrent use. Measurements are sent in the order they
+// were added, a measurement which fails to be sent holds back the ones after
+// it until the next replay. Measurements with the MeasurementID of a
+// measurement already waiting in the outbox are ignored, while a measurement
+// added again after it has been sent is sent again.
+type Outbox struct {
+	sender    Sender
+	maxBytes  int64
+	policy    EvictionPolicy
+	permanent func(error) bool
+	onDrop    func(Entry, error)
+	onError   func(error)
+
+	mutex     sync.Mutex
+	path      string
+	file      *os.File
+	size      int64
+	liveBytes int64
+	entries   []entry
+	ids       map[uuid.UUID]struct{}
+	nextSeq   uint64
+
+	// sending is the sequence number of the entry being sent by the replay,
+	// zero if none. An entry evicted while being sent is only dropped once
+	// the send has failed, as it may still be delivered.
+	sending        uint64
+	sendingEvicted bool
+
+	// replaying makes sure only one replay runs at a time.
+	replaying sync.Mutex
+}
+
+// Open opens the outbox stored in the file at path, creating it if it
+// doesn't exist. Measurements left in the outbox are sent by the next replay.
+func Open(path string, sender Sender, opts ...Option) (*Outbox, error) {
+	o := &Outbox{
+		sender:         sender,
+		maxBytes:       0,
+		policy:         EvictOldest,
+		permanent:      pas.IsPermanent,
+		onDrop:         nil,
+		onError:        nil,
+		mutex:          sync.Mutex{},
+		path:           path,
+		file:           nil,
+		size:           0,
+		liveBytes:      0,
+		entries:        nil,
+		ids:            map[uuid.UUID]struct{}{},
+		nextSeq:        1,
+		sending:        0,
+		sendingEvicted: false,
+		replaying:      sync.Mutex{},
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if err := jsonl.TruncatePartialLine(path); err != nil {
+		return nil, fmt.Errorf("opening outbox failed: %w", err)
+	}
+
+	if err := o.load(); err != nil {
+		return nil, err
+	}
+
+	// Compacting on open drops the lines of measurements sent before.
+	if err := o.compact(); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func (o *Outbox) Close() error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return o.file.Close()
+}
+
+// UpdateAlarmStatus adds the measurement to the outbox, it returns once the
+// measurement has been persisted. This makes the outbox usable in place of
+// the client for ingesting measurements.
+func (o *Outbox) UpdateAlarmStatus(_ context.Context, nodeID uuid.UUID, measurement *models.Measurement) error {
+	evicted, err := o.add(nodeID, measurement)
+
+	for _, e := range evicted {
+		o.drop(e, ErrEvicted)
+	}
+
+	return err
+}
+
+// add adds the measurement to the outbox, returning any entries evicted to
+// make room for it.
+func (o *Outbox) add(nodeID uuid.UUID, measurement *models.Measurement) ([]Entry, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if measurement != nil && measurement.MeasurementID != "" {
+		if _, duplicate := o.ids[measurement.MeasurementID]; duplicate {
+			return nil, nil
+		}
+	}
+
+	e := entry{
+		Entry: Entry{
+			NodeID:      nodeID,
+			Measurement: nil,
+			EnqueuedAt:  time.Now().UTC(),
+		},
+		seq:  o.nextSeq,
+		size: 0,
+	}
+
+	if measurement != nil {
+		copied := *measurement
+		e.Measurement = &copied
+	}
+
+	line, err := encodeEntry(e)
+	if err != nil {
+		return nil, err
+	}
+
+	e.size = int64(len(line))
+
+	evicted, err := o.makeRoom(e.size)
+	if err != nil {
+		return evicted, err
+	}
+
+	if err = o.write(line); err != nil {
+		return evicted, err
+	}
+
+	o.nextSeq++
+	o.liveBytes += e.size
+	o.entries = append(o.entries, e)
+
+	if measurement != nil && measurement.MeasurementID != "" {
+		o.ids[measurement.MeasurementID] = struct{}{}
+	}
+
+	return evicted, nil
+}
+
+// Replay sends the measurements in the outbox in order, until it's empty or a
+// measurement fails to be sent. Measurements which fail permanently, see
+// WithPermanentErrors, are dropped, any other failure stops the replay and is
+// returned.
+func (o *Outbox) Replay(ctx context.Context) error {
+	o.replaying.Lock()
+	defer o.replaying.Unlock()
+
+	for {
+		head, found := o.startSend()
+		if !found {
+			return nil
+		}
+
+		err := o.sender.UpdateAlarmStatus(ctx, head.NodeID, head.Measurement)
+		evicted := o.finishSend()
+
+		switch {
+		case err != nil && !o.permanent(err):
+			if evicted {
+				o.drop(head.Entry, ErrEvicted)
+			}
+
+			return fmt.Errorf("replaying outbox failed: %w", err)
+		case err != nil:
+			o.drop(head.Entry, err)
+		}
+
+		if evicted {
+			continue
+		}
+
+		if err = o.ack(head.seq); err != nil {
+			return err
+		}
+	}
+}
+
+// Run replays the outbox every interval until the context is canceled. A
+// failed replay is passed to the error handler, and retried on the next
+// interval.
+func (o *Outbox) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := o.Replay(ctx); err != nil && ctx.Err() == nil && o.onError != nil {
+			o.onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (o *Outbox) Stats() Stats {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	stats := Stats{
+		Depth:     len(o.entries),
+		Bytes:     o.size,
+		OldestAge: 0,
+	}
+
+	if len(o.entries) > 0 {
+		stats.OldestAge = time.Since(o.entries[0].EnqueuedAt)
+	}
+
+	return stats
+}
+
+// startSend returns the oldest entry, marking it as being sent.
+func (o *Outbox) startSend() (entry, bool) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if len(o.entries) == 0 {
+		return entry{}, false
+	}
+
+	o.sending, o.sendingEvicted = o.entries[0].seq, false
+
+	return o.entries[0], true
+}
+
+// finishSend reports whether the entry was evicted while being sent.
+func (o *Outbox) finishSend() bool {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	evicted := o.sendingEvicted
+	o.sending, o.sendingEvicted = 0, false
+
+	return evicted
+}
+
+// ack removes the entries up to and including seq, compacting the file once
+// most of it is made up of removed entries.
+func (o *Outbox) ack(seq uint64) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	line, err := json.Marshal(fileRecord{Entry: nil, Ack: &seq})
+	if err != nil {
+		return fmt.Errorf("encoding ack failed: %w", err)
+	}
+
+	if err = o.write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	o.remove(seq)
+
+	if o.size > compactionRatio*o.liveBytes {
+		return o.compact()
+	}
+
+	return nil
+}
+
+// makeRoom makes sure an entry of the size fits within the size limit,
+// returning the evicted entries. It must be called with the mutex held.
+func (o *Outbox) makeRoom(size int64) ([]Entry, error) {
+	if o.maxBytes <= 0 || o.size+size <= o.maxBytes {
+		return nil, nil
+	}
+
+	if o.liveBytes+size <= o.maxBytes {
+		return nil, o.compact()
+	}
+
+	if o.policy == RejectNew || size > o.maxBytes {
+		return nil, ErrFull
+	}
+
+	var evicted []Entry
+
+	for len(o.entries) > 0 && o.liveBytes+size > o.maxBytes {
+		if o.entries[0].seq == o.sending {
+			o.sendingEvicted = true
+		} else {
+			evicted = append(evicted, o.entries[0].Entry)
+		}
+
+		o.remove(o.entries[0].seq)
+	}
+
+	return evicted, o.compact()
+}
+
+// remove removes the entries up to and including seq from memory.
+func (o *Outbox) remove(seq uint64) {
+	for len(o.entries) > 0 && o.entries[0].seq <= seq {
+		removed := o.entries[0]
+
+		o.entries = o.entries[1:]
+		o.liveBytes -= removed.size
+
+		if removed.Measurement != nil {
+			delete(o.ids, removed.Measurement.MeasurementID)
+		}
+	}
+}
+
+func (o *Outbox) drop(e Entry, err error) {
+	if o.onDrop != nil {
+		o.onDrop(e, err)
+	}
+}
+
+func (o *Outbox) write(line []byte) error {
+	if _, err := o.file.Write(line); err != nil {
+		return fmt.Errorf("writing to outbox failed: %w", err)
+	}
+
+	if err := o.file.Sync(); err != nil {
+		return fmt.Errorf("syncing outbox failed: %w", err)
+	}
+
+	o.size += int64(len(line))
+
+	return nil
+}
+
+// compact rewrites the file with only the entries left in the outbox.
+func (o *Outbox) compact() error {
+	var buf bytes.Buffer
+
+	for _, e := range o.entries {
+		line, err := encodeEntry(e)
+		if err != nil {
+			return err
+		}
+
+		buf.Write(line)
+	}
+
+	if err := jsonl.Replace(o.path, buf.Bytes(), filePermissions); err != nil {
+		return fmt.Errorf("compacting outbox failed: %w", err)
+	}
+
+	file, err := os.OpenFile(o.path, os.O_WRONLY|os.O_APPEND, filePermissions)
+	if err != nil {
+		return fmt.Errorf("opening outbox failed: %w", err)
+	}
+
+	if o.file != nil {
+		_ = o.file.Close()
+	}
+
+	o.file = file
+	o.size = int64(buf.Len())
+
+	return nil
+}
+
+func (o *Outbox) load() error {
+	file, err := os.Open(o.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("opening outbox failed: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxLineSize)
+
+	for line := 1; scanner.Scan(); line++ {
+		var (
+			record fileRecord
+			e      entry
+		)
+
+		if err = json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("decoding line %d of outbox failed: %w", line, err)
+		}
+
+		if record.Ack != nil {
+			o.remove(*record.Ack)
+		}
+
+		if record.Entry == nil {
+			continue
+		}
+
+		if e, err = record.Entry.toEntry(); err != nil {
+			return fmt.Errorf("decoding line %d of outbox failed: %w", line, err)
+		}
+
+		e.size = int64(len(scanner.Bytes()) + 1)
+
+		o.entries = append(o.entries, e)
+		o.liveBytes += e.size
+
+		if e.Measurement != nil && e.Measurement.MeasurementID != "" {
+			o.ids[e.Measurement.MeasurementID] = struct{}{}
+		}
+
+		if e.seq >= o.nextSeq {
+			o.nextSeq = e.seq + 1
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("reading outbox failed: %w", err)
+	}
+
+	return nil
+}
+
+func encodeEntry(e entry) ([]byte, error) {
+	encoded := &fileEntry{
+		Seq:         e.seq,
+		NodeID:      e.NodeID,
+		EnqueuedAt:  e.EnqueuedAt.UnixNano(),
+		Measurement: nil,
+		CreatedAt:   "",
+	}
+
+	if e.Measurement != nil {
+		measurement := e.Measurement.ToInternal()
+		encoded.Measurement = &measurement
+		encoded.CreatedAt = e.Measurement.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	line, err := json.Marshal(fileRecord{Entry: encoded, Ack: nil})
+	if err != nil {
+		return nil, fmt.Errorf("encoding measurement failed: %w", err)
+	}
+
+	return append(line, '\n'), nil
+}
+
+func (f fileEntry) toEntry() (entry, error) {
+	e := entry{
+		Entry: Entry{
+			NodeID:      f.NodeID,
+			Measurement: nil,
+			EnqueuedAt:  time.Unix(0, f.EnqueuedAt).UTC(),
+		},
+		seq:  f.Seq,
+		size: 0,
+	}
+
+	if f.Measurement != nil {
+		e.Measurement = new(models.Measurement)
+
+		if err := e.Measurement.FromInternal(*f.Measurement); err != nil {
+			return entry{}, fmt.Errorf("decoding measurement failed: %w", err)
+		}
+	}
+
+	if e.Measurement != nil && f.CreatedAt != "" {
+		createdAt, err := time.Parse(time.RFC3339Nano, f.CreatedAt)
+		if err != nil {
+			return entry{}, fmt.Errorf("decoding measurement failed: %w", err)
+		}
+
+		e.Measurement.CreatedAt = createdAt.UTC()
+	}
+
+	return e, nil
+}
@@ -0,0 +1,406 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pas "github.com/SKF/go-pas-client"
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-pas-client/outbox"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+var epoch = time.Date(2022, time.March, 4, 12, 0, 0, 0, time.UTC)
+
+type fakeSender struct {
+	mutex     sync.Mutex
+	sent      []uuid.UUID
+	createdAt []time.Time
+	err       error
+}
+
+func (s *fakeSender) UpdateAlarmStatus(_ context.Context, _ uuid.UUID, measurement *models.Measurement) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	s.sent = append(s.sent, measurement.MeasurementID)
+	s.createdAt = append(s.createdAt, measurement.CreatedAt)
+
+	return nil
+}
+
+// blockingSender blocks the first send until released, then fails it with err.
+type blockingSender struct {
+	fakeSender
+
+	started chan struct{}
+	release chan error
+	once    sync.Once
+}
+
+func (s *blockingSender) UpdateAlarmStatus(ctx context.Context, nodeID uuid.UUID, measurement *models.Measurement) error {
+	blocked := false
+
+	s.once.Do(func() {
+		blocked = true
+	})
+
+	if blocked {
+		close(s.started)
+
+		if err := <-s.release; err != nil {
+			return err
+		}
+	}
+
+	return s.fakeSender.UpdateAlarmStatus(ctx, nodeID, measurement)
+}
+
+func (s *fakeSender) setErr(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.err = err
+}
+
+func measurement(minutes int) *models.Measurement {
+	return &models.Measurement{
+		MeasurementID: uuid.New(),
+		CreatedAt:     epoch.Add(time.Duration(minutes) * time.Minute),
+		ContentType:   models.ContentTypeDataPoint,
+		DataPoint: &models.DataPoint{
+			Coordinate: models.Coordinate{X: 0, Y: 2.5},
+			XUnit:      "s",
+			YUnit:      "gE",
+		},
+	}
+}
+
+func open(t *testing.T, path string, sender outbox.Sender, opts ...outbox.Option) *outbox.Outbox {
+	t.Helper()
+
+	o, err := outbox.Open(path, sender, opts...)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		o.Close()
+	})
+
+	return o
+}
+
+func Test_Outbox_ReplaysInOrderAfterOutage(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx    = context.Background()
+		nodeID = uuid.New()
+		path   = filepath.Join(t.TempDir(), "outbox.jsonl")
+		sender = &fakeSender{err: pas.ErrServer}
+		o      = open(t, path, sender)
+		first  = measurement(1)
+		second = measurement(2)
+	)
+
+	first.CreatedAt = first.CreatedAt.Add(123456789 * time.Nanosecond)
+
+	require.NoError(t, o.UpdateAlarmStatus(ctx, nodeID, first))
+	require.NoError(t, o.UpdateAlarmStatus(ctx, nodeID, second))
+	require.NoError(t, o.UpdateAlarmStatus(ctx, nodeID, first), "duplicates are ignored")
+
+	assert.ErrorIs(t, o.Replay(ctx), pas.ErrServer)
+
+	stats := o.Stats()
+	assert.Equal(t, 2, stats.Depth)
+	assert.Greater(t, stats.Bytes, int64(0))
+	assert.Greater(t, stats.OldestAge, time.Duration(0))
+
+	// the outbox survives a restart
+	require.NoError(t, o.Close())
+
+	sender.setErr(nil)
+	o = open(t, path, sender)
+
+	assert.Equal(t, 2, o.Stats().Depth)
+
+	require.NoError(t, o.Replay(ctx))
+
+	assert.Equal(t, []uuid.UUID{first.MeasurementID, second.MeasurementID}, sender.sent)
+	assert.Equal(t, []time.Time{first.CreatedAt, second.CreatedAt}, sender.createdAt)
+	assert.Equal(t, outbox.Stats{Depth: 0, Bytes: 0, OldestAge: 0}, o.Stats())
+
+	require.NoError(t, o.Close())
+
+	o = open(t, path, sender)
+	assert.Equal(t, 0, o.Stats().Depth, "sent measurements are not replayed again")
+}
+
+func Test_Outbox_DropsRejected(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		err     error
+		opts    []outbox.Option
+		dropped bool
+	}{
+		"validation": {
+			err:     pas.ErrValidation,
+			opts:    nil,
+			dropped: true,
+		},
+		"unauthorized": {
+			err:     pas.ErrUnauthorized,
+			opts:    nil,
+			dropped: true,
+		},
+		"server": {
+			err:     pas.ErrServer,
+			opts:    nil,
+			dropped: false,
+		},
+		"unauthorized is not permanent": {
+			err: pas.ErrUnauthorized,
+			opts: []outbox.Option{outbox.WithPermanentErrors(func(err error) bool {
+				return errors.Is(err, pas.ErrValidation)
+			})},
+			dropped: false,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				ctx     = context.Background()
+				sender  = &fakeSender{err: test.err}
+				dropped []outbox.Entry
+				opts    = append([]outbox.Option{outbox.WithDropHandler(
+					func(e outbox.Entry, err error) {
+						assert.ErrorIs(t, err, test.err)
+
+						dropped = append(dropped, e)
+					},
+				)}, test.opts...)
+				o = open(t, filepath.Join(t.TempDir(), "outbox.jsonl"), sender, opts...)
+				m = measurement(1)
+			)
+
+			require.NoError(t, o.UpdateAlarmStatus(ctx, uuid.New(), m))
+
+			if !test.dropped {
+				assert.ErrorIs(t, o.Replay(ctx), test.err)
+				assert.Empty(t, dropped)
+				assert.Equal(t, 1, o.Stats().Depth)
+
+				return
+			}
+
+			require.NoError(t, o.Replay(ctx))
+
+			require.Len(t, dropped, 1)
+			assert.Equal(t, m, dropped[0].Measurement)
+			assert.Equal(t, 0, o.Stats().Depth)
+		})
+	}
+}
+
+func Test_Outbox_Eviction(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	size := func(t *testing.T) int64 {
+		t.Helper()
+
+		path := filepath.Join(t.TempDir(), "outbox.jsonl")
+		o := open(t, path, &fakeSender{err: nil})
+
+		require.NoError(t, o.UpdateAlarmStatus(ctx, uuid.New(), measurement(1)))
+
+		return o.Stats().Bytes
+	}(t)
+
+	t.Run("evict oldest", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			sender  = &fakeSender{err: nil}
+			evicted []outbox.Entry
+			path    = filepath.Join(t.TempDir(), "outbox.jsonl")
+			o       = open(t, path, sender, outbox.WithMaxBytes(2*size), outbox.WithDropHandler(
+				func(e outbox.Entry, err error) {
+					assert.ErrorIs(t, err, outbox.ErrEvicted)
+
+					evicted = append(evicted, e)
+				},
+			))
+			measurements = []*models.Measurement{measurement(1), measurement(2), measurement(3)}
+		)
+
+		for _, m := range measurements {
+			require.NoError(t, o.UpdateAlarmStatus(ctx, uuid.New(), m))
+		}
+
+		require.Len(t, evicted, 1)
+		assert.Equal(t, measurements[0].MeasurementID, evicted[0].Measurement.MeasurementID)
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), 2*size)
+
+		require.NoError(t, o.Replay(ctx))
+		assert.Equal(t, []uuid.UUID{measurements[1].MeasurementID, measurements[2].MeasurementID}, sender.sent)
+	})
+
+	t.Run("reject new", func(t *testing.T) {
+		t.Parallel()
+
+		o := open(t, filepath.Join(t.TempDir(), "outbox.jsonl"), &fakeSender{err: nil},
+			outbox.WithMaxBytes(size), outbox.WithEvictionPolicy(outbox.RejectNew))
+
+		require.NoError(t, o.UpdateAlarmStatus(ctx, uuid.New(), measurement(1)))
+		assert.ErrorIs(t, o.UpdateAlarmStatus(ctx, uuid.New(), measurement(2)), outbox.ErrFull)
+		assert.Equal(t, 1, o.Stats().Depth)
+	})
+}
+
+func Test_Outbox_EvictedWhileSending(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	size := func(t *testing.T) int64 {
+		t.Helper()
+
+		o := open(t, filepath.Join(t.TempDir(), "outbox.jsonl"), &fakeSender{err: nil})
+
+		require.NoError(t, o.UpdateAlarmStatus(ctx, uuid.New(), measurement(1)))
+
+		return o.Stats().Bytes
+	}(t)
+
+	tests := map[string]struct {
+		err     error
+		dropped bool
+	}{
+		"delivered": {err: nil, dropped: false},
+		"failed":    {err: pas.ErrServer, dropped: true},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				sender = &blockingSender{
+					fakeSender: fakeSender{err: nil},
+					started:    make(chan struct{}),
+					release:    make(chan error),
+					once:       sync.Once{},
+				}
+				mutex   sync.Mutex
+				dropped []outbox.Entry
+				o       = open(t, filepath.Join(t.TempDir(), "outbox.jsonl"), sender,
+					outbox.WithMaxBytes(2*size),
+					outbox.WithDropHandler(func(e outbox.Entry, err error) {
+						mutex.Lock()
+						defer mutex.Unlock()
+
+						assert.ErrorIs(t, err, outbox.ErrEvicted)
+
+						dropped = append(dropped, e)
+					}),
+				)
+				first = measurement(1)
+				done  = make(chan error)
+			)
+
+			require.NoError(t, o.UpdateAlarmStatus(ctx, uuid.New(), first))
+
+			go func() {
+				done <- o.Replay(ctx)
+			}()
+
+			<-sender.started
+
+			// evicts the measurement being sent
+			require.NoError(t, o.UpdateAlarmStatus(ctx, uuid.New(), measurement(2)))
+			require.NoError(t, o.UpdateAlarmStatus(ctx, uuid.New(), measurement(3)))
+
+			mutex.Lock()
+			assert.Empty(t, dropped, "not dropped before the send has finished")
+			mutex.Unlock()
+
+			sender.release <- test.err
+
+			err := <-done
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if !test.dropped {
+				require.NoError(t, err)
+				assert.Empty(t, dropped)
+				assert.Len(t, sender.sent, 3)
+
+				return
+			}
+
+			assert.ErrorIs(t, err, test.err)
+			require.Len(t, dropped, 1)
+			assert.Equal(t, first.MeasurementID, dropped[0].Measurement.MeasurementID)
+			assert.Equal(t, 2, o.Stats().Depth)
+		})
+	}
+}
+
+func Test_Outbox_Run(t *testing.T) {
+	t.Parallel()
+
+	var (
+		failures int32
+		sender   = &fakeSender{err: pas.ErrServer}
+		o        = open(t, filepath.Join(t.TempDir(), "outbox.jsonl"), sender, outbox.WithErrorHandler(func(err error) {
+			if errors.Is(err, pas.ErrServer) {
+				atomic.AddInt32(&failures, 1)
+			}
+		}))
+		ctx, cancel = context.WithCancel(context.Background())
+		done        = make(chan error)
+	)
+
+	require.NoError(t, o.UpdateAlarmStatus(ctx, uuid.New(), measurement(1)))
+
+	go func() {
+		done <- o.Run(ctx, 10*time.Millisecond)
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	sender.setErr(nil)
+
+	require.Eventually(t, func() bool {
+		return o.Stats().Depth == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Positive(t, atomic.LoadInt32(&failures), "failed replays are reported")
+}
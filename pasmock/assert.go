@@ -0,0 +1,149 @@
+package pasmock
+
+import (
+	"fmt"
+
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+// TestingT is the part of testing.T used to report failed assertions, it's
+// also satisfied by assert.TestingT of testify.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// AssertCalled asserts that the method was called at least once for the node.
+func (c *Client) AssertCalled(t TestingT, method string, nodeID uuid.UUID) bool {
+	return c.assertMatch(t, method, nodeID, func(Call) bool { return true })
+}
+
+// AssertNotCalled asserts that the method was never called for the node.
+func (c *Client) AssertNotCalled(t TestingT, method string, nodeID uuid.UUID) bool {
+	for _, call := range c.CallsTo(method) {
+		if call.NodeID == nodeID {
+			return fail(t, fmt.Sprintf("Expected %s not to be called for node %s", method, nodeID))
+		}
+	}
+
+	return true
+}
+
+// AssertNumberOfCalls asserts that the method was called exactly n times, for any node.
+func (c *Client) AssertNumberOfCalls(t TestingT, method string, n int) bool {
+	actual := len(c.CallsTo(method))
+	if actual != n {
+		return fail(t, fmt.Sprintf("Expected %s to be called %d time(s) but was called %d time(s)", method, n, actual))
+	}
+
+	return true
+}
+
+// AssertSetThreshold asserts that SetThreshold was called for the node with
+// a threshold satisfying match.
+func (c *Client) AssertSetThreshold(t TestingT, nodeID uuid.UUID, match func(models.Threshold) bool) bool {
+	return c.assertMatch(t, MethodSetThreshold, nodeID, func(call Call) bool {
+		return call.Threshold != nil && match(*call.Threshold)
+	})
+}
+
+// AssertPatchThreshold asserts that PatchThreshold was called for the node
+// with a patch satisfying match.
+func (c *Client) AssertPatchThreshold(t TestingT, nodeID uuid.UUID, match func(models.Patch) bool) bool {
+	return c.assertMatch(t, MethodPatchThreshold, nodeID, func(call Call) bool {
+		return match(call.Patch)
+	})
+}
+
+// AssertSetThresholdIfMatch asserts that SetThresholdIfMatch was called for
+// the node with the ETag and a threshold satisfying match.
+func (c *Client) AssertSetThresholdIfMatch(
+	t TestingT,
+	nodeID uuid.UUID,
+	etag string,
+	match func(models.Threshold) bool,
+) bool {
+	return c.assertMatch(t, MethodSetThresholdIfMatch, nodeID, func(call Call) bool {
+		return call.ETag == etag && call.Threshold != nil && match(*call.Threshold)
+	})
+}
+
+// AssertPatchThresholdIfMatch asserts that PatchThresholdIfMatch was called
+// for the node with the ETag and a patch satisfying match.
+func (c *Client) AssertPatchThresholdIfMatch(
+	t TestingT,
+	nodeID uuid.UUID,
+	etag string,
+	match func(models.Patch) bool,
+) bool {
+	return c.assertMatch(t, MethodPatchThresholdIfMatch, nodeID, func(call Call) bool {
+		return call.ETag == etag && match(call.Patch)
+	})
+}
+
+// AssertUpdateAlarmStatus asserts that UpdateAlarmStatus was called for the
+// node with a measurement satisfying match.
+func (c *Client) AssertUpdateAlarmStatus(t TestingT, nodeID uuid.UUID, match func(*models.Measurement) bool) bool {
+	return c.assertMatch(t, MethodUpdateAlarmStatus, nodeID, func(call Call) bool {
+		return match(call.Measurement)
+	})
+}
+
+// AssertSetExternalAlarmStatus asserts that SetExternalAlarmStatus was called
+// for the node with an external alarm status satisfying match.
+func (c *Client) AssertSetExternalAlarmStatus(
+	t TestingT,
+	nodeID uuid.UUID,
+	match func(models.ExternalAlarmStatus) bool,
+) bool {
+	return c.assertMatch(t, MethodSetExternalAlarmStatus, nodeID, func(call Call) bool {
+		return call.ExternalAlarmStatus != nil && match(*call.ExternalAlarmStatus)
+	})
+}
+
+// AssertUpdateThreshold asserts that UpdateThreshold was called for the node
+// and that the mutated threshold satisfies match.
+func (c *Client) AssertUpdateThreshold(t TestingT, nodeID uuid.UUID, match func(models.Threshold) bool) bool {
+	return c.assertMatch(t, MethodUpdateThreshold, nodeID, func(call Call) bool {
+		return call.Threshold != nil && match(*call.Threshold)
+	})
+}
+
+// OverallOuterHigh matches thresholds with the given overall outer high level.
+func OverallOuterHigh(value float64) func(models.Threshold) bool {
+	return func(threshold models.Threshold) bool {
+		return threshold.Overall != nil &&
+			threshold.Overall.OuterHigh != nil &&
+			*threshold.Overall.OuterHigh == value
+	}
+}
+
+// ThresholdType matches thresholds of the given type.
+func ThresholdType(thresholdType models.ThresholdType) func(models.Threshold) bool {
+	return func(threshold models.Threshold) bool {
+		return threshold.ThresholdType == thresholdType
+	}
+}
+
+func (c *Client) assertMatch(t TestingT, method string, nodeID uuid.UUID, match func(Call) bool) bool {
+	calls := c.CallsTo(method)
+
+	for _, call := range calls {
+		if call.NodeID == nodeID && match(call) {
+			return true
+		}
+	}
+
+	return fail(t, fmt.Sprintf("Expected a matching call to %s for node %s, recorded %d call(s) to %s",
+		method, nodeID, len(calls), method))
+}
+
+func fail(t TestingT, message string) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	t.Errorf("%s", message)
+
+	return false
+}
@@ -0,0 +1,352 @@
+// Package pasmock provides a programmable implementation of the client API
+// which records all calls made to it.
+package pasmock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pas "github.com/SKF/go-pas-client"
+	"github.com/SKF/go-pas-client/cache"
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+const (
+	MethodGetThreshold           = "GetThreshold"
+	MethodSetThreshold           = "SetThreshold"
+	MethodPatchThreshold         = "PatchThreshold"
+	MethodGetAlarmStatus         = "GetAlarmStatus"
+	MethodSetExternalAlarmStatus = "SetExternalAlarmStatus"
+	MethodUpdateAlarmStatus      = "UpdateAlarmStatus"
+	MethodGetThresholds          = "GetThresholds"
+	MethodGetAlarmStatuses       = "GetAlarmStatuses"
+	MethodUpdateThreshold        = "UpdateThreshold"
+	MethodWatchAlarmStatus       = "WatchAlarmStatus"
+
+	MethodUpdateAlarmStatusAndGet      = "UpdateAlarmStatusAndGet"
+	MethodSetExternalAlarmStatusAndGet = "SetExternalAlarmStatusAndGet"
+
+	MethodSetThresholdIfMatch   = "SetThresholdIfMatch"
+	MethodPatchThresholdIfMatch = "PatchThresholdIfMatch"
+)
+
+// Call is a recorded call to the mock, only the fields relevant for the
+// called method are set.
+type Call struct {
+	Method              string
+	NodeID              uuid.UUID
+	NodeIDs             []uuid.UUID
+	Threshold           *models.Threshold
+	Patch               models.Patch
+	Measurement         *models.Measurement
+	ExternalAlarmStatus *models.ExternalAlarmStatus
+	Interval            time.Duration
+	ETag                string
+}
+
+type response struct {
+	threshold     models.Threshold
+	alarmStatus   models.AlarmStatus
+	thresholds    map[uuid.UUID]models.Threshold
+	alarmStatuses map[uuid.UUID]models.AlarmStatus
+	errs          map[uuid.UUID]error
+	changes       []pas.AlarmStatusChange
+	err           error
+}
+
+// Client is a mock of the client API. Responses are queued per method and
+// consumed in order, a call without any queued response returns the zero
+// value and no error.
+type Client struct {
+	lock      sync.Mutex
+	calls     []Call
+	responses map[string][]response
+}
+
+var _ cache.Client = &Client{lock: sync.Mutex{}, calls: nil, responses: nil}
+
+func New() *Client {
+	return &Client{
+		lock:      sync.Mutex{},
+		calls:     []Call{},
+		responses: make(map[string][]response),
+	}
+}
+
+func (c *Client) QueueGetThreshold(threshold models.Threshold, err error) *Client {
+	return c.queue(MethodGetThreshold, response{threshold: threshold, err: err})
+}
+
+func (c *Client) QueueSetThreshold(err error) *Client {
+	return c.queue(MethodSetThreshold, response{err: err})
+}
+
+func (c *Client) QueuePatchThreshold(threshold models.Threshold, err error) *Client {
+	return c.queue(MethodPatchThreshold, response{threshold: threshold, err: err})
+}
+
+func (c *Client) QueueSetThresholdIfMatch(err error) *Client {
+	return c.queue(MethodSetThresholdIfMatch, response{err: err})
+}
+
+func (c *Client) QueuePatchThresholdIfMatch(threshold models.Threshold, err error) *Client {
+	return c.queue(MethodPatchThresholdIfMatch, response{threshold: threshold, err: err})
+}
+
+func (c *Client) QueueGetAlarmStatus(alarmStatus models.AlarmStatus, err error) *Client {
+	return c.queue(MethodGetAlarmStatus, response{alarmStatus: alarmStatus, err: err})
+}
+
+func (c *Client) QueueSetExternalAlarmStatus(err error) *Client {
+	return c.queue(MethodSetExternalAlarmStatus, response{err: err})
+}
+
+func (c *Client) QueueUpdateAlarmStatus(err error) *Client {
+	return c.queue(MethodUpdateAlarmStatus, response{err: err})
+}
+
+func (c *Client) QueueUpdateAlarmStatusAndGet(alarmStatus models.AlarmStatus, err error) *Client {
+	return c.queue(MethodUpdateAlarmStatusAndGet, response{alarmStatus: alarmStatus, err: err})
+}
+
+func (c *Client) QueueSetExternalAlarmStatusAndGet(alarmStatus models.AlarmStatus, err error) *Client {
+	return c.queue(MethodSetExternalAlarmStatusAndGet, response{alarmStatus: alarmStatus, err: err})
+}
+
+func (c *Client) QueueGetThresholds(thresholds map[uuid.UUID]models.Threshold, errs map[uuid.UUID]error) *Client {
+	return c.queue(MethodGetThresholds, response{thresholds: thresholds, errs: errs})
+}
+
+func (c *Client) QueueGetAlarmStatuses(alarmStatuses map[uuid.UUID]models.AlarmStatus, errs map[uuid.UUID]error) *Client {
+	return c.queue(MethodGetAlarmStatuses, response{alarmStatuses: alarmStatuses, errs: errs})
+}
+
+// QueueUpdateThreshold queues the current threshold which the mutator passed
+// to UpdateThreshold is applied to, unless err is set.
+func (c *Client) QueueUpdateThreshold(current models.Threshold, err error) *Client {
+	return c.queue(MethodUpdateThreshold, response{threshold: current, err: err})
+}
+
+// QueueWatchAlarmStatus queues the changes delivered by WatchAlarmStatus,
+// the channel is closed once the context is canceled.
+func (c *Client) QueueWatchAlarmStatus(changes ...pas.AlarmStatusChange) *Client {
+	return c.queue(MethodWatchAlarmStatus, response{changes: changes})
+}
+
+// Calls returns all recorded calls in the order they were made.
+func (c *Client) Calls() []Call {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	calls := make([]Call, len(c.calls))
+	copy(calls, c.calls)
+
+	return calls
+}
+
+// CallsTo returns all recorded calls to the given method.
+func (c *Client) CallsTo(method string) []Call {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	calls := []Call{}
+
+	for _, call := range c.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// Reset removes all recorded calls and queued responses.
+func (c *Client) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.calls = []Call{}
+	c.responses = make(map[string][]response)
+}
+
+func (c *Client) GetThreshold(_ context.Context, nodeID uuid.UUID) (models.Threshold, error) {
+	r := c.record(Call{Method: MethodGetThreshold, NodeID: nodeID})
+
+	return r.threshold, r.err
+}
+
+func (c *Client) SetThreshold(_ context.Context, nodeID uuid.UUID, threshold models.Threshold) error {
+	r := c.record(Call{Method: MethodSetThreshold, NodeID: nodeID, Threshold: &threshold})
+
+	return r.err
+}
+
+func (c *Client) PatchThreshold(_ context.Context, nodeID uuid.UUID, patch models.Patch) (models.Threshold, error) {
+	r := c.record(Call{Method: MethodPatchThreshold, NodeID: nodeID, Patch: patch})
+
+	return r.threshold, r.err
+}
+
+func (c *Client) SetThresholdIfMatch(
+	_ context.Context,
+	nodeID uuid.UUID,
+	threshold models.Threshold,
+	etag string,
+) error {
+	r := c.record(Call{Method: MethodSetThresholdIfMatch, NodeID: nodeID, Threshold: &threshold, ETag: etag})
+
+	return r.err
+}
+
+func (c *Client) PatchThresholdIfMatch(
+	_ context.Context,
+	nodeID uuid.UUID,
+	patch models.Patch,
+	etag string,
+) (models.Threshold, error) {
+	r := c.record(Call{Method: MethodPatchThresholdIfMatch, NodeID: nodeID, Patch: patch, ETag: etag})
+
+	return r.threshold, r.err
+}
+
+func (c *Client) GetAlarmStatus(_ context.Context, nodeID uuid.UUID) (models.AlarmStatus, error) {
+	r := c.record(Call{Method: MethodGetAlarmStatus, NodeID: nodeID})
+
+	return r.alarmStatus, r.err
+}
+
+func (c *Client) SetExternalAlarmStatus(_ context.Context, nodeID uuid.UUID, status models.ExternalAlarmStatus) error {
+	r := c.record(Call{Method: MethodSetExternalAlarmStatus, NodeID: nodeID, ExternalAlarmStatus: &status})
+
+	return r.err
+}
+
+func (c *Client) UpdateAlarmStatus(_ context.Context, nodeID uuid.UUID, measurement *models.Measurement) error {
+	r := c.record(Call{Method: MethodUpdateAlarmStatus, NodeID: nodeID, Measurement: measurement})
+
+	return r.err
+}
+
+func (c *Client) UpdateAlarmStatusAndGet(
+	_ context.Context,
+	nodeID uuid.UUID,
+	measurement *models.Measurement,
+) (models.AlarmStatus, error) {
+	r := c.record(Call{Method: MethodUpdateAlarmStatusAndGet, NodeID: nodeID, Measurement: measurement})
+
+	return r.alarmStatus, r.err
+}
+
+func (c *Client) SetExternalAlarmStatusAndGet(
+	_ context.Context,
+	nodeID uuid.UUID,
+	status models.ExternalAlarmStatus,
+) (models.AlarmStatus, error) {
+	r := c.record(Call{Method: MethodSetExternalAlarmStatusAndGet, NodeID: nodeID, ExternalAlarmStatus: &status})
+
+	return r.alarmStatus, r.err
+}
+
+func (c *Client) GetThresholds(
+	_ context.Context,
+	nodeIDs []uuid.UUID,
+) (map[uuid.UUID]models.Threshold, map[uuid.UUID]error) {
+	r := c.record(Call{Method: MethodGetThresholds, NodeIDs: nodeIDs})
+
+	return r.thresholds, r.errs
+}
+
+func (c *Client) GetAlarmStatuses(
+	_ context.Context,
+	nodeIDs []uuid.UUID,
+) (map[uuid.UUID]models.AlarmStatus, map[uuid.UUID]error) {
+	r := c.record(Call{Method: MethodGetAlarmStatuses, NodeIDs: nodeIDs})
+
+	return r.alarmStatuses, r.errs
+}
+
+// UpdateThreshold applies the mutator to the queued threshold and records the
+// mutated threshold.
+func (c *Client) UpdateThreshold(
+	_ context.Context,
+	nodeID uuid.UUID,
+	mutate func(*models.Threshold) error,
+) (models.Threshold, error) {
+	r := c.next(MethodUpdateThreshold)
+	threshold := r.threshold.Clone()
+
+	if r.err == nil && mutate != nil {
+		r.err = mutate(&threshold)
+	}
+
+	c.append(Call{Method: MethodUpdateThreshold, NodeID: nodeID, Threshold: &threshold})
+
+	if r.err != nil {
+		return models.Threshold{}, r.err
+	}
+
+	return threshold, nil
+}
+
+func (c *Client) WatchAlarmStatus(
+	ctx context.Context,
+	nodeIDs []uuid.UUID,
+	interval time.Duration,
+) <-chan pas.AlarmStatusChange {
+	r := c.record(Call{Method: MethodWatchAlarmStatus, NodeIDs: nodeIDs, Interval: interval})
+	changes := make(chan pas.AlarmStatusChange)
+
+	go func() {
+		defer close(changes)
+
+		for _, change := range r.changes {
+			select {
+			case <-ctx.Done():
+				return
+			case changes <- change:
+			}
+		}
+
+		<-ctx.Done()
+	}()
+
+	return changes
+}
+
+func (c *Client) queue(method string, r response) *Client {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.responses[method] = append(c.responses[method], r)
+
+	return c
+}
+
+func (c *Client) record(call Call) response {
+	c.append(call)
+
+	return c.next(call.Method)
+}
+
+func (c *Client) append(call Call) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.calls = append(c.calls, call)
+}
+
+func (c *Client) next(method string) response {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	queued := c.responses[method]
+	if len(queued) == 0 {
+		return response{}
+	}
+
+	c.responses[method] = queued[1:]
+
+	return queued[0]
+}
@@ -0,0 +1,195 @@
+package pasmock_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pas "github.com/SKF/go-pas-client"
+	"github.com/SKF/go-pas-client/cache"
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-pas-client/pasmock"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+const nodeID = uuid.UUID("5ad5b0a4-7fe0-4b8c-9d28-2a8b6a0c2f5e")
+
+func f64p(f float64) *float64 {
+	return &f
+}
+
+type failureRecorder struct {
+	failed bool
+}
+
+func (r *failureRecorder) Errorf(string, ...interface{}) {
+	r.failed = true
+}
+
+func Test_QueuedResponses(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mock     = pasmock.New()
+		errBoom  = errors.New("boom")
+		expected = models.Threshold{NodeID: nodeID, ThresholdType: models.ThresholdTypeInspection}
+	)
+
+	mock.QueueGetThreshold(expected, nil).QueueGetThreshold(models.Threshold{}, errBoom)
+
+	actual, err := mock.GetThreshold(context.TODO(), nodeID)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	_, err = mock.GetThreshold(context.TODO(), nodeID)
+	assert.ErrorIs(t, err, errBoom)
+
+	actual, err = mock.GetThreshold(context.TODO(), nodeID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Threshold{}, actual)
+
+	mock.AssertNumberOfCalls(t, pasmock.MethodGetThreshold, 3)
+}
+
+func Test_Assertions(t *testing.T) {
+	t.Parallel()
+
+	mock := pasmock.New()
+
+	err := mock.SetThreshold(context.TODO(), nodeID, models.Threshold{
+		ThresholdType: models.ThresholdTypeOverallOutOfWindow,
+		Overall: &models.Overall{
+			Unit:      "C",
+			OuterHigh: f64p(80),
+		},
+	})
+	require.NoError(t, err)
+
+	mock.AssertCalled(t, pasmock.MethodSetThreshold, nodeID)
+	mock.AssertNotCalled(t, pasmock.MethodPatchThreshold, nodeID)
+	mock.AssertSetThreshold(t, nodeID, pasmock.OverallOuterHigh(80))
+	mock.AssertSetThreshold(t, nodeID, pasmock.ThresholdType(models.ThresholdTypeOverallOutOfWindow))
+
+	failing := new(failureRecorder)
+
+	assert.False(t, mock.AssertSetThreshold(failing, nodeID, pasmock.OverallOuterHigh(70)))
+	assert.False(t, mock.AssertCalled(failing, pasmock.MethodSetThreshold, uuid.EmptyUUID))
+	assert.True(t, failing.failed)
+}
+
+func Test_IfMatch(t *testing.T) {
+	t.Parallel()
+
+	mock := pasmock.New().
+		QueueSetThresholdIfMatch(pas.ErrPreconditionFailed).
+		QueuePatchThresholdIfMatch(models.Threshold{ThresholdType: models.ThresholdTypeNone}, nil)
+
+	err := mock.SetThresholdIfMatch(context.TODO(), nodeID, models.Threshold{
+		ThresholdType: models.ThresholdTypeOverallOutOfWindow,
+	}, `"v1"`)
+	assert.ErrorIs(t, err, pas.ErrPreconditionFailed)
+
+	patched, err := mock.PatchThresholdIfMatch(context.TODO(), nodeID, models.Patch{}, `"v2"`)
+	require.NoError(t, err)
+	assert.Equal(t, models.ThresholdTypeNone, patched.ThresholdType)
+
+	mock.AssertSetThresholdIfMatch(t, nodeID, `"v1"`, pasmock.ThresholdType(models.ThresholdTypeOverallOutOfWindow))
+	mock.AssertPatchThresholdIfMatch(t, nodeID, `"v2"`, func(models.Patch) bool { return true })
+
+	failing := new(failureRecorder)
+
+	assert.False(t, mock.AssertPatchThresholdIfMatch(failing, nodeID, `"v1"`, func(models.Patch) bool { return true }))
+	assert.True(t, failing.failed)
+}
+
+func Test_Reset(t *testing.T) {
+	t.Parallel()
+
+	mock := pasmock.New()
+	mock.QueueUpdateAlarmStatus(errors.New("boom"))
+
+	_ = mock.SetExternalAlarmStatus(context.TODO(), nodeID, models.ExternalAlarmStatus{Status: models.AlarmStatusDanger})
+
+	mock.Reset()
+
+	assert.Empty(t, mock.Calls())
+	assert.NoError(t, mock.UpdateAlarmStatus(context.TODO(), nodeID, nil))
+}
+
+// Test_RecordsAllMethods makes sure every method of the client interface is
+// recorded under its own name, so the mock is kept in sync with the interface.
+func Test_RecordsAllMethods(t *testing.T) {
+	t.Parallel()
+
+	var (
+		api     = reflect.TypeOf((*cache.Client)(nil)).Elem()
+		mock    = pasmock.New()
+		mockVal = reflect.ValueOf(mock)
+	)
+
+	for i := 0; i < api.NumMethod(); i++ {
+		method := api.Method(i)
+
+		args := make([]reflect.Value, method.Type.NumIn())
+		for j := range args {
+			args[j] = reflect.Zero(method.Type.In(j))
+		}
+
+		args[0] = reflect.ValueOf(context.TODO())
+
+		mockVal.MethodByName(method.Name).Call(args)
+
+		calls := mock.Calls()
+		require.NotEmpty(t, calls)
+		assert.Equal(t, method.Name, calls[len(calls)-1].Method)
+	}
+}
+
+func Test_UpdateThreshold(t *testing.T) {
+	t.Parallel()
+
+	mock := pasmock.New().QueueUpdateThreshold(models.Threshold{
+		NodeID:        nodeID,
+		ThresholdType: models.ThresholdTypeOverallOutOfWindow,
+		Overall:       &models.Overall{Unit: "C", OuterHigh: f64p(70)},
+	}, nil)
+
+	actual, err := mock.UpdateThreshold(context.TODO(), nodeID, func(threshold *models.Threshold) error {
+		threshold.Overall.OuterHigh = f64p(80)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 80.0, *actual.Overall.OuterHigh)
+	mock.AssertUpdateThreshold(t, nodeID, pasmock.OverallOuterHigh(80))
+}
+
+func Test_WatchAlarmStatus(t *testing.T) {
+	t.Parallel()
+
+	var (
+		expected    = pas.AlarmStatusChange{NodeID: nodeID, Current: models.AlarmStatus{Status: models.AlarmStatusDanger}}
+		mock        = pasmock.New().QueueWatchAlarmStatus(expected)
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+
+	changes := mock.WatchAlarmStatus(ctx, []uuid.UUID{nodeID}, time.Second)
+
+	assert.Equal(t, expected, <-changes)
+
+	cancel()
+
+	_, open := <-changes
+	assert.False(t, open)
+
+	calls := mock.CallsTo(pasmock.MethodWatchAlarmStatus)
+	require.Len(t, calls, 1)
+	assert.Equal(t, []uuid.UUID{nodeID}, calls[0].NodeIDs)
+	assert.Equal(t, time.Second, calls[0].Interval)
+}
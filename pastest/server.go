@@ -0,0 +1,665 @@
+// Package pastest provides an in-process fake of the PAS API for use in
+// integration tests.
+package pastest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	openapi_errors "github.com/go-openapi/errors"
+	"github.com/go-openapi/strfmt"
+	"github.com/wI2L/jsondiff"
+
+	"github.com/SKF/go-pas-client/evaluate"
+	"github.com/SKF/go-pas-client/internal/jsonpatch"
+	internal_models "github.com/SKF/go-pas-client/internal/models"
+	"github.com/SKF/go-pas-client/internal/responses"
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-rest-utility/problems"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+const (
+	routeThreshold   = "point-alarm-threshold"
+	routeAlarmStatus = "alarm-status"
+
+	// etagLength is the number of bytes of the hash of a value used as its
+	// ETag.
+	etagLength = 8
+)
+
+// Server is a stateful fake of the PAS API. Thresholds and alarm statuses are
+// stored per node and alarm statuses are recomputed using the evaluate
+// package whenever a measurement is received. Thresholds and alarm statuses
+// are returned with an ETag, and conditional requests are answered with 304
+// Not Modified and 412 Precondition Failed like by the PAS API.
+type Server struct {
+	*httptest.Server
+
+	lock          sync.Mutex
+	thresholds    map[uuid.UUID]models.Threshold
+	alarmStatuses map[uuid.UUID]models.AlarmStatus
+	bandOveralls  map[uuid.UUID]map[string]float64
+	halIndexes    map[uuid.UUID]map[string]float64
+}
+
+// NewServer starts and returns a new Server. The caller should call Close
+// when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		Server:        nil,
+		lock:          sync.Mutex{},
+		thresholds:    make(map[uuid.UUID]models.Threshold),
+		alarmStatuses: make(map[uuid.UUID]models.AlarmStatus),
+		bandOveralls:  make(map[uuid.UUID]map[string]float64),
+		halIndexes:    make(map[uuid.UUID]map[string]float64),
+	}
+
+	s.Server = httptest.NewServer(s)
+
+	return s
+}
+
+// SetThreshold stores a threshold for a node without going through the API.
+func (s *Server) SetThreshold(nodeID uuid.UUID, threshold models.Threshold) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	threshold.NodeID = nodeID
+	s.thresholds[nodeID] = threshold
+}
+
+// Threshold returns the currently stored threshold of a node.
+func (s *Server) Threshold(nodeID uuid.UUID) (models.Threshold, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	threshold, found := s.thresholds[nodeID]
+
+	return threshold, found
+}
+
+// SetAlarmStatus stores an alarm status for a node without going through the API.
+func (s *Server) SetAlarmStatus(nodeID uuid.UUID, alarmStatus models.AlarmStatus) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.alarmStatuses[nodeID] = alarmStatus
+}
+
+// SetBandOveralls sets the overall of each band, keyed by the label of the
+// band alarm, used when evaluating the following measurements of a node. The
+// PAS service calculates them from the spectrum, which measurements don't carry.
+func (s *Server) SetBandOveralls(nodeID uuid.UUID, bandOveralls map[string]float64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.bandOveralls[nodeID] = bandOveralls
+}
+
+// SetHALIndexes sets the HAL index of each HAL alarm, keyed by the label of
+// the HAL alarm, used when evaluating the following measurements of a node.
+// The PAS service calculates them from the spectrum, which measurements don't
+// carry.
+func (s *Server) SetHALIndexes(nodeID uuid.UUID, halIndexes map[string]float64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.halIndexes[nodeID] = halIndexes
+}
+
+// AlarmStatus returns the currently stored alarm status of a node.
+func (s *Server) AlarmStatus(nodeID uuid.UUID) (models.AlarmStatus, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	alarmStatus, found := s.alarmStatuses[nodeID]
+
+	return alarmStatus, found
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	if len(segments) < 3 || segments[0] != "v1" {
+		writeProblem(w, routeNotFound(r))
+
+		return
+	}
+
+	nodeID := uuid.UUID(segments[2])
+
+	if err := nodeID.Validate(); err != nil {
+		writeProblem(w, problems.Validation(problems.ValidationReason{
+			Name:   "nodeId",
+			Reason: err.Error(),
+			Cause:  err,
+		}))
+
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch route := strings.Join(append([]string{segments[1]}, segments[3:]...), "/"); {
+	case route == routeThreshold && r.Method == http.MethodGet:
+		s.getThreshold(w, r, nodeID)
+	case route == routeThreshold && r.Method == http.MethodPut:
+		s.setThreshold(w, r, nodeID)
+	case route == routeThreshold && r.Method == http.MethodPatch:
+		s.patchThreshold(w, r, nodeID)
+	case route == routeAlarmStatus && r.Method == http.MethodGet:
+		s.getAlarmStatus(w, r, nodeID)
+	case route == routeAlarmStatus && r.Method == http.MethodPut:
+		s.updateAlarmStatus(w, r, nodeID)
+	case route == routeAlarmStatus+"/status/external" && r.Method == http.MethodPut:
+		s.setExternalAlarmStatus(w, r, nodeID)
+	default:
+		writeProblem(w, routeNotFound(r))
+	}
+}
+
+func (s *Server) getThreshold(w http.ResponseWriter, r *http.Request, nodeID uuid.UUID) {
+	threshold, found := s.thresholds[nodeID]
+	if !found {
+		writeProblem(w, notFound("threshold", nodeID))
+
+		return
+	}
+
+	response, err := thresholdResponse(threshold)
+	if err != nil {
+		writeProblem(w, problems.Internal(err))
+
+		return
+	}
+
+	writeVersioned(w, r, response, time.Time{})
+}
+
+// checkIfMatch responds with 412 Precondition Failed and returns false if the
+// request carries an If-Match header which doesn't match the ETag of the
+// current threshold of the node.
+func (s *Server) checkIfMatch(w http.ResponseWriter, r *http.Request, nodeID uuid.UUID) bool {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return true
+	}
+
+	threshold, found := s.thresholds[nodeID]
+	if found && ifMatch == "*" {
+		return true
+	}
+
+	if found {
+		response, err := thresholdResponse(threshold)
+		if err != nil {
+			writeProblem(w, problems.Internal(err))
+
+			return false
+		}
+
+		etag, err := etagOf(response)
+		if err != nil {
+			writeProblem(w, problems.Internal(err))
+
+			return false
+		}
+
+		if etag == ifMatch {
+			return true
+		}
+	}
+
+	writeProblem(w, preconditionFailed(nodeID))
+
+	return false
+}
+
+func (s *Server) setThreshold(w http.ResponseWriter, r *http.Request, nodeID uuid.UUID) {
+	if !s.checkIfMatch(w, r, nodeID) {
+		return
+	}
+
+	var request internal_models.ModelsSetPointAlarmThresholdRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeProblem(w, invalidBody(err))
+
+		return
+	}
+
+	if err := request.Validate(strfmt.Default); err != nil {
+		writeProblem(w, validationProblem(err))
+
+		return
+	}
+
+	threshold, err := toThreshold(request)
+	if err != nil {
+		writeProblem(w, problems.Internal(err))
+
+		return
+	}
+
+	threshold.NodeID = nodeID
+	s.thresholds[nodeID] = threshold
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) patchThreshold(w http.ResponseWriter, r *http.Request, nodeID uuid.UUID) {
+	threshold, found := s.thresholds[nodeID]
+	if !found {
+		writeProblem(w, notFound("threshold", nodeID))
+
+		return
+	}
+
+	if !s.checkIfMatch(w, r, nodeID) {
+		return
+	}
+
+	var patch []jsondiff.Operation
+
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeProblem(w, invalidBody(err))
+
+		return
+	}
+
+	document, err := json.Marshal(threshold.ToInternal())
+	if err != nil {
+		writeProblem(w, problems.Internal(err))
+
+		return
+	}
+
+	if document, err = jsonpatch.Apply(document, patch); err != nil {
+		problem := problems.Validation(problems.ValidationReason{Name: "patch", Reason: err.Error(), Cause: err})
+
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			problem.Type = "/problems/conflict"
+			problem.Title = "The patch could not be applied to the current threshold."
+			problem.Status = http.StatusConflict
+		}
+
+		writeProblem(w, problem)
+
+		return
+	}
+
+	var request internal_models.ModelsSetPointAlarmThresholdRequest
+
+	if err = json.Unmarshal(document, &request); err != nil {
+		writeProblem(w, invalidBody(err))
+
+		return
+	}
+
+	if err = request.Validate(strfmt.Default); err != nil {
+		writeProblem(w, validationProblem(err))
+
+		return
+	}
+
+	patched, err := toThreshold(request)
+	if err != nil {
+		writeProblem(w, problems.Internal(err))
+
+		return
+	}
+
+	patched.NodeID = nodeID
+	s.thresholds[nodeID] = patched
+
+	response, err := thresholdResponse(patched)
+	if err != nil {
+		writeProblem(w, problems.Internal(err))
+
+		return
+	}
+
+	writeVersioned(w, r, response, time.Time{})
+}
+
+func (s *Server) getAlarmStatus(w http.ResponseWriter, r *http.Request, nodeID uuid.UUID) {
+	alarmStatus, found := s.alarmStatuses[nodeID]
+	if !found {
+		writeProblem(w, notFound("alarm status", nodeID))
+
+		return
+	}
+
+	writeVersioned(w, r, alarmStatusResponse(nodeID, alarmStatus), alarmStatus.UpdatedAt)
+}
+
+func (s *Server) updateAlarmStatus(w http.ResponseWriter, r *http.Request, nodeID uuid.UUID) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeProblem(w, invalidBody(err))
+
+		return
+	}
+
+	if len(body) == 0 {
+		w.WriteHeader(http.StatusOK)
+
+		return
+	}
+
+	var request internal_models.ModelsUpdateAlarmStatusRequest
+
+	if err = json.Unmarshal(body, &request); err != nil {
+		writeProblem(w, invalidBody(err))
+
+		return
+	}
+
+	if err = request.Validate(strfmt.Default); err != nil {
+		writeProblem(w, validationProblem(err))
+
+		return
+	}
+
+	measurement := models.Measurement{} //nolint:exhaustruct
+
+	if err = measurement.FromInternal(request); err != nil {
+		writeProblem(w, validationProblem(err))
+
+		return
+	}
+
+	alarmStatus, err := evaluate.Evaluate(s.thresholds[nodeID], measurement,
+		evaluate.WithBandOveralls(s.bandOveralls[nodeID]),
+		evaluate.WithHALIndexes(s.halIndexes[nodeID]),
+	)
+	if err != nil {
+		writeProblem(w, validationProblem(err))
+
+		return
+	}
+
+	s.alarmStatuses[nodeID] = merge(s.alarmStatuses[nodeID], alarmStatus)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) setExternalAlarmStatus(w http.ResponseWriter, r *http.Request, nodeID uuid.UUID) {
+	var request internal_models.ModelsSetExternalAlarmStatusRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeProblem(w, invalidBody(err))
+
+		return
+	}
+
+	if err := request.Validate(strfmt.Default); err != nil {
+		writeProblem(w, validationProblem(err))
+
+		return
+	}
+
+	alarmStatus := s.alarmStatuses[nodeID]
+	alarmStatus.External = new(models.ExternalAlarmStatus)
+	alarmStatus.External.FromInternal(&internal_models.ModelsGetAlarmStatusResponseExternal{
+		Status: request.Status,
+		SetBy:  request.SetBy,
+	})
+
+	alarmStatus.UpdatedAt = time.Now().UTC()
+	alarmStatus.Status = evaluate.Worst(alarmStatus)
+	s.alarmStatuses[nodeID] = alarmStatus
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// merge combines a newly evaluated alarm status with the previous one, alarms
+// which the new measurement carried no data for keep their previous status.
+func merge(previous, next models.AlarmStatus) models.AlarmStatus {
+	next.Overall = mergeGeneric(previous.Overall, next.Overall)
+	next.RateOfChange = mergeGeneric(previous.RateOfChange, next.RateOfChange)
+	next.Inspection = mergeGeneric(previous.Inspection, next.Inspection)
+	next.External = previous.External
+
+	for i, hal := range next.HAL {
+		if hal.Status != models.AlarmStatusNoData {
+			continue
+		}
+
+		for _, previousHAL := range previous.HAL {
+			if previousHAL.Label == hal.Label && sameBearing(previousHAL.Bearing, hal.Bearing) {
+				next.HAL[i] = previousHAL
+			}
+		}
+	}
+
+	for i, band := range next.Band {
+		if band.Status != models.AlarmStatusNoData {
+			continue
+		}
+
+		for _, previousBand := range previous.Band {
+			if previousBand.Label == band.Label {
+				next.Band[i] = previousBand
+			}
+		}
+	}
+
+	next.Status = evaluate.Worst(next)
+
+	return next
+}
+
+func sameBearing(a, b *models.Bearing) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+func mergeGeneric(previous, next *models.GenericAlarmStatus) *models.GenericAlarmStatus {
+	if next != nil && next.Status == models.AlarmStatusNoData && previous != nil {
+		return previous
+	}
+
+	return next
+}
+
+func thresholdResponse(from models.Threshold) (responses.Threshold, error) {
+	var (
+		response responses.Threshold
+		nodeID   = strfmt.UUID(from.NodeID.String())
+	)
+
+	if err := convert(from.ToInternal(), &response); err != nil {
+		return response, err
+	}
+
+	response.NodeID = &nodeID
+
+	return response, nil
+}
+
+func alarmStatusResponse(nodeID uuid.UUID, alarmStatus models.AlarmStatus) internal_models.ModelsGetAlarmStatusResponse {
+	var (
+		response = alarmStatus.ToInternal()
+		id       = strfmt.UUID(nodeID.String())
+	)
+
+	response.NodeID = &id
+
+	return response
+}
+
+// toThreshold converts any JSON representation of a threshold into a
+// threshold model by passing it through its JSON encoding.
+func toThreshold(from interface{}) (models.Threshold, error) {
+	var response responses.Threshold
+
+	if err := convert(from, &response); err != nil {
+		return models.Threshold{}, err
+	}
+
+	return response.ToThreshold()
+}
+
+func convert(from interface{}, to interface{}) error {
+	buf, err := json.Marshal(from)
+	if err != nil {
+		return fmt.Errorf("encoding failed: %w", err)
+	}
+
+	if err = json.Unmarshal(buf, to); err != nil {
+		return fmt.Errorf("decoding failed: %w", err)
+	}
+
+	return nil
+}
+
+// writeVersioned writes the value together with its ETag, which is derived
+// from its JSON encoding, and its Last-Modified unless lastModified is zero.
+// A GET request which is conditional on either is answered with 304 Not
+// Modified if the value hasn't changed.
+func writeVersioned(w http.ResponseWriter, r *http.Request, v interface{}, lastModified time.Time) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		writeProblem(w, problems.Internal(err))
+
+		return
+	}
+
+	etag := etagOfJSON(buf)
+
+	w.Header().Set("ETag", etag)
+
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if r.Method == http.MethodGet && notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, _ = w.Write(buf)
+}
+
+// notModified reports whether the validators of the request match, the ETag
+// takes precedence over the modification time.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return ifNoneMatch == etag
+	}
+
+	ifModifiedSince := r.Header.Get("If-Modified-Since")
+	if ifModifiedSince == "" || lastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(ifModifiedSince)
+
+	return err == nil && !lastModified.Truncate(time.Second).After(since)
+}
+
+func etagOf(v interface{}) (string, error) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("encoding failed: %w", err)
+	}
+
+	return etagOfJSON(buf), nil
+}
+
+func etagOfJSON(buf []byte) string {
+	sum := sha256.Sum256(buf)
+
+	return `"` + hex.EncodeToString(sum[:etagLength]) + `"`
+}
+
+func writeProblem(w http.ResponseWriter, problem problems.Problem) {
+	w.Header().Set("Content-Type", problems.ContentType)
+	w.WriteHeader(problem.ProblemStatus())
+
+	_ = json.NewEncoder(w).Encode(problem) //nolint:errchkjson
+}
+
+func routeNotFound(r *http.Request) problems.Problem {
+	return problems.BasicProblem{
+		Type:          "/problems/route-not-found",
+		Title:         "The requested route does not exist.",
+		Status:        http.StatusNotFound,
+		Detail:        fmt.Sprintf("%s %s is not a known route.", r.Method, r.URL.Path),
+		Instance:      r.URL.String(),
+		CorrelationID: "",
+	}
+}
+
+func preconditionFailed(nodeID uuid.UUID) problems.Problem {
+	return problems.BasicProblem{
+		Type:          "/problems/precondition-failed",
+		Title:         "The threshold has been modified.",
+		Status:        http.StatusPreconditionFailed,
+		Detail:        fmt.Sprintf("The threshold of node %s doesn't match If-Match.", nodeID),
+		Instance:      "",
+		CorrelationID: "",
+	}
+}
+
+func notFound(resource string, nodeID uuid.UUID) problems.Problem {
+	return problems.BasicProblem{
+		Type:          "/problems/not-found",
+		Title:         "The requested resource does not exist.",
+		Status:        http.StatusNotFound,
+		Detail:        fmt.Sprintf("No %s exists for node %s.", resource, nodeID),
+		Instance:      "",
+		CorrelationID: "",
+	}
+}
+
+func invalidBody(err error) problems.Problem {
+	return problems.Validation(problems.ValidationReason{
+		Name:   "body",
+		Reason: err.Error(),
+		Cause:  err,
+	})
+}
+
+func validationProblem(err error) problems.Problem {
+	problem := problems.Validation()
+
+	var composite *openapi_errors.CompositeError
+
+	if !errors.As(err, &composite) {
+		problem.Append(problems.ValidationReason{Name: "", Reason: err.Error(), Cause: err})
+
+		return problem
+	}
+
+	for _, cause := range composite.Errors {
+		var validation *openapi_errors.Validation
+
+		if errors.As(cause, &validation) {
+			problem.Append(problems.ValidationReason{Name: validation.Name, Reason: validation.Error(), Cause: cause})
+		} else {
+			problem.Append(problems.ValidationReason{Name: "", Reason: cause.Error(), Cause: cause})
+		}
+	}
+
+	return problem
+}
@@ -0,0 +1,360 @@
+package pastest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pas "github.com/SKF/go-pas-client"
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-pas-client/pastest"
+	rest "github.com/SKF/go-rest-utility/client"
+	"github.com/SKF/go-rest-utility/problems"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+const nodeID = uuid.UUID("5ad5b0a4-7fe0-4b8c-9d28-2a8b6a0c2f5e")
+
+func f64p(f float64) *float64 {
+	return &f
+}
+
+func overallThreshold() models.Threshold {
+	return models.Threshold{
+		ThresholdType: models.ThresholdTypeOverallOutOfWindow,
+		Overall: &models.Overall{
+			Unit:      "C",
+			OuterHigh: f64p(70),
+			InnerHigh: f64p(50),
+			InnerLow:  f64p(20),
+			OuterLow:  f64p(10),
+		},
+		BandAlarms: []models.BandAlarm{},
+		HALAlarms:  []models.HALAlarm{},
+	}
+}
+
+func Test_Threshold(t *testing.T) {
+	t.Parallel()
+
+	fake := pastest.NewServer()
+	defer fake.Close()
+
+	client := pas.New(rest.WithBaseURL(fake.URL))
+
+	err := client.SetThreshold(context.TODO(), nodeID, overallThreshold())
+	require.NoError(t, err)
+
+	actual, err := client.GetThreshold(context.TODO(), nodeID)
+	require.NoError(t, err)
+
+	expected := overallThreshold()
+	expected.NodeID = nodeID
+	expected.ETag = actual.ETag
+
+	assert.NotEmpty(t, actual.ETag)
+	assert.Equal(t, expected, actual)
+}
+
+func Test_Threshold_Origin(t *testing.T) {
+	t.Parallel()
+
+	fake := pastest.NewServer()
+	defer fake.Close()
+
+	client := pas.New(rest.WithBaseURL(fake.URL))
+
+	threshold := overallThreshold()
+	threshold.Origin = &models.Origin{ID: "template-1", Provider: "baseline", Type: "template"}
+
+	require.NoError(t, client.SetThreshold(context.TODO(), nodeID, threshold))
+
+	actual, err := client.GetThreshold(context.TODO(), nodeID)
+	require.NoError(t, err)
+
+	assert.Equal(t, threshold.Origin, actual.Origin)
+}
+
+func Test_GetThreshold_NotFound(t *testing.T) {
+	t.Parallel()
+
+	fake := pastest.NewServer()
+	defer fake.Close()
+
+	client := pas.New(rest.WithBaseURL(fake.URL))
+
+	_, err := client.GetThreshold(context.TODO(), nodeID)
+
+	var problem problems.BasicProblem
+
+	require.ErrorAs(t, err, &problem)
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+}
+
+func Test_SetThreshold_Invalid(t *testing.T) {
+	t.Parallel()
+
+	fake := pastest.NewServer()
+	defer fake.Close()
+
+	client := pas.New(rest.WithBaseURL(fake.URL))
+
+	threshold := overallThreshold()
+	threshold.ThresholdType = 10
+
+	err := client.SetThreshold(context.TODO(), nodeID, threshold)
+
+	var problem problems.ValidationProblem
+
+	require.ErrorAs(t, err, &problem)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.NotEmpty(t, problem.Reasons)
+}
+
+func Test_PatchThreshold(t *testing.T) {
+	t.Parallel()
+
+	fake := pastest.NewServer()
+	defer fake.Close()
+
+	fake.SetThreshold(nodeID, overallThreshold())
+
+	client := pas.New(rest.WithBaseURL(fake.URL))
+
+	actual, err := client.PatchThreshold(context.TODO(), nodeID, models.Patch{
+		{Type: "test", Path: "/overall/outerHigh", Value: 70},
+		{Type: "replace", Path: "/overall/outerHigh", Value: 80},
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, actual.Overall)
+	assert.Equal(t, f64p(80), actual.Overall.OuterHigh)
+
+	stored, found := fake.Threshold(nodeID)
+	require.True(t, found)
+
+	stored.ETag = actual.ETag
+	assert.Equal(t, actual, stored)
+}
+
+func Test_PatchThreshold_Conflict(t *testing.T) {
+	t.Parallel()
+
+	fake := pastest.NewServer()
+	defer fake.Close()
+
+	fake.SetThreshold(nodeID, overallThreshold())
+
+	client := pas.New(rest.WithBaseURL(fake.URL))
+
+	_, err := client.PatchThreshold(context.TODO(), nodeID, models.Patch{
+		{Type: "test", Path: "/overall/outerHigh", Value: 60},
+		{Type: "replace", Path: "/overall/outerHigh", Value: 80},
+	})
+
+	var problem problems.ValidationProblem
+
+	require.ErrorAs(t, err, &problem)
+	assert.Equal(t, http.StatusConflict, problem.Status)
+}
+
+func Test_UpdateThreshold(t *testing.T) {
+	t.Parallel()
+
+	fake := pastest.NewServer()
+	defer fake.Close()
+
+	fake.SetThreshold(nodeID, overallThreshold())
+
+	client := pas.New(rest.WithBaseURL(fake.URL))
+
+	actual, err := client.UpdateThreshold(context.TODO(), nodeID, func(threshold *models.Threshold) error {
+		threshold.Overall.OuterHigh = f64p(80)
+		threshold.Overall.InnerHigh = nil
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	stored, found := fake.Threshold(nodeID)
+	require.True(t, found)
+
+	stored.ETag = actual.ETag
+	assert.Equal(t, actual, stored)
+	assert.Equal(t, f64p(80), stored.Overall.OuterHigh)
+	assert.Nil(t, stored.Overall.InnerHigh)
+}
+
+func Test_UpdateAlarmStatus(t *testing.T) {
+	t.Parallel()
+
+	fake := pastest.NewServer()
+	defer fake.Close()
+
+	fake.SetThreshold(nodeID, overallThreshold())
+
+	client := pas.New(rest.WithBaseURL(fake.URL))
+
+	var (
+		measurementID = uuid.UUID("0e9fd2a4-4fe4-4d5b-93c4-7e0b2d1c0a33")
+		createdAt     = time.UnixMilli(time.Now().UnixMilli()).UTC()
+	)
+
+	err := client.UpdateAlarmStatus(context.TODO(), nodeID, &models.Measurement{
+		MeasurementID: measurementID,
+		CreatedAt:     createdAt,
+		ContentType:   models.ContentTypeDataPoint,
+		DataPoint: &models.DataPoint{
+			Coordinate: models.Coordinate{X: float64(createdAt.UnixMilli()), Y: 60},
+			XUnit:      "ms",
+			YUnit:      "C",
+		},
+	})
+	require.NoError(t, err)
+
+	actual, err := client.GetAlarmStatus(context.TODO(), nodeID)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.AlarmStatusAlert, actual.Status)
+	assert.Equal(t, createdAt, actual.UpdatedAt)
+
+	require.NotNil(t, actual.Overall)
+	assert.Equal(t, measurementID, actual.Overall.TriggeringMeasurement)
+	assert.Equal(t, models.AlarmStatusAlert, actual.Overall.Status)
+
+	err = client.SetExternalAlarmStatus(context.TODO(), nodeID, models.ExternalAlarmStatus{
+		Status: models.AlarmStatusDanger,
+	})
+	require.NoError(t, err)
+
+	actual, err = client.GetAlarmStatus(context.TODO(), nodeID)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.AlarmStatusDanger, actual.Status)
+	require.NotNil(t, actual.Overall)
+	assert.Equal(t, models.AlarmStatusAlert, actual.Overall.Status)
+}
+
+func Test_UpdateAlarmStatus_HAL(t *testing.T) {
+	t.Parallel()
+
+	fake := pastest.NewServer()
+	defer fake.Close()
+
+	threshold := overallThreshold()
+	threshold.HALAlarms = []models.HALAlarm{{
+		Label:        "outer ring",
+		Bearing:      &models.Bearing{Manufacturer: "SKF", ModelNumber: "6205"},
+		HALAlarmType: models.HALAlarmTypeGlobal,
+		UpperDanger:  f64p(2),
+		UpperAlert:   f64p(1),
+	}}
+
+	fake.SetThreshold(nodeID, threshold)
+
+	client := pas.New(rest.WithBaseURL(fake.URL))
+
+	update := func(t *testing.T, halIndexes map[string]float64) models.AlarmStatus {
+		t.Helper()
+
+		fake.SetHALIndexes(nodeID, halIndexes)
+
+		err := client.UpdateAlarmStatus(context.TODO(), nodeID, &models.Measurement{
+			MeasurementID: uuid.New(),
+			CreatedAt:     time.Now().UTC(),
+			ContentType:   models.ContentTypeDataPoint,
+			DataPoint: &models.DataPoint{
+				Coordinate: models.Coordinate{X: 0, Y: 30},
+				XUnit:      "ms",
+				YUnit:      "C",
+			},
+		})
+		require.NoError(t, err)
+
+		actual, err := client.GetAlarmStatus(context.TODO(), nodeID)
+		require.NoError(t, err)
+		require.Len(t, actual.HAL, 1)
+
+		return actual
+	}
+
+	actual := update(t, map[string]float64{"outer ring": 0.5})
+
+	assert.Equal(t, models.AlarmStatusGood, actual.Status)
+	assert.Equal(t, models.AlarmStatusGood, actual.HAL[0].Status)
+
+	actual = update(t, map[string]float64{"outer ring": 2.5})
+
+	assert.Equal(t, models.AlarmStatusDanger, actual.Status)
+	assert.Equal(t, models.AlarmStatusDanger, actual.HAL[0].Status)
+	require.NotNil(t, actual.HAL[0].HALIndex)
+	assert.Equal(t, 2.5, *actual.HAL[0].HALIndex)
+
+	// a measurement without a HAL index keeps the previous HAL status
+	actual = update(t, nil)
+
+	assert.Equal(t, models.AlarmStatusDanger, actual.Status)
+	assert.Equal(t, models.AlarmStatusDanger, actual.HAL[0].Status)
+}
+
+func Test_GetAlarmStatus_NotFound(t *testing.T) {
+	t.Parallel()
+
+	fake := pastest.NewServer()
+	defer fake.Close()
+
+	client := pas.New(rest.WithBaseURL(fake.URL))
+
+	_, err := client.GetAlarmStatus(context.TODO(), nodeID)
+
+	var problem problems.BasicProblem
+
+	require.ErrorAs(t, err, &problem)
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+}
+
+func Test_Threshold_ConditionalRequests(t *testing.T) {
+	t.Parallel()
+
+	fake := pastest.NewServer()
+	defer fake.Close()
+
+	var (
+		ctx    = context.Background()
+		client = pas.NewWithOptions([]rest.Option{rest.WithBaseURL(fake.URL)}, pas.WithConditionalRequests(10))
+	)
+
+	fake.SetThreshold(nodeID, overallThreshold())
+
+	read, err := client.GetThreshold(ctx, nodeID)
+	require.NoError(t, err)
+	require.NotEmpty(t, read.ETag)
+
+	notModified, err := client.GetThreshold(ctx, nodeID)
+	require.NoError(t, err)
+	assert.Equal(t, read, notModified)
+
+	modified := read.Clone()
+	modified.Overall.OuterHigh = f64p(80)
+
+	require.NoError(t, client.SetThresholdIfMatch(ctx, nodeID, modified, read.ETag))
+
+	err = client.SetThresholdIfMatch(ctx, nodeID, read, read.ETag)
+	assert.ErrorIs(t, err, pas.ErrPreconditionFailed, "the threshold was modified since it was read")
+
+	_, err = client.PatchThresholdIfMatch(ctx, nodeID, models.Patch{}, read.ETag)
+	assert.ErrorIs(t, err, pas.ErrPreconditionFailed)
+
+	current, err := client.GetThreshold(ctx, nodeID)
+	require.NoError(t, err)
+	assert.NotEqual(t, read.ETag, current.ETag)
+	assert.Equal(t, f64p(80), current.Overall.OuterHigh)
+
+	patched, err := client.PatchThresholdIfMatch(ctx, nodeID, models.Patch{}, current.ETag)
+	require.NoError(t, err)
+	assert.Equal(t, current.ETag, patched.ETag, "an empty patch doesn't modify the threshold")
+}
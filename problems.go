@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/SKF/go-rest-utility/problems"
 )
@@ -13,20 +15,58 @@ type ProblemDecoder struct{}
 func (p *ProblemDecoder) DecodeProblem(_ context.Context, r *http.Response) (problems.Problem, error) {
 	decoder := json.NewDecoder(r.Body)
 
-	switch r.StatusCode {
-	case http.StatusBadRequest, http.StatusConflict:
+	switch {
+	case r.StatusCode == http.StatusBadRequest, r.StatusCode == http.StatusConflict:
 		var (
 			problem = problems.ValidationProblem{}
 			err     = decoder.Decode(&problem)
 		)
 
-		return problem, err
+		problem.Status = r.StatusCode
+
+		return ValidationError{problem}, err
+	case r.StatusCode >= http.StatusInternalServerError:
+		var (
+			problem = problems.BasicProblem{}
+			err     = decoder.Decode(&problem)
+		)
+
+		problem.Status = r.StatusCode
+
+		return ServerError{BasicProblem: problem, RetryAfter: retryAfter(r)}, err
 	default:
 		var (
 			problem = problems.BasicProblem{}
 			err     = decoder.Decode(&problem)
 		)
 
-		return problem, err
+		problem.Status = r.StatusCode
+
+		return ProblemError{BasicProblem: problem, RetryAfter: retryAfter(r)}, err
 	}
 }
+
+// retryAfter parses the Retry-After header of the response, which is either a
+// number of seconds or a HTTP date.
+func retryAfter(r *http.Response) time.Duration {
+	value := r.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
@@ -3,42 +3,58 @@ package client
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SKF/go-rest-utility/problems"
 )
 
 func Test_ProblemDecoder(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		given *http.Response
+		given    *http.Response
+		expected error
 	}{
 		{
 			given: &http.Response{
 				StatusCode: http.StatusBadRequest,
 				Body:       ioutil.NopCloser(bytes.NewBuffer([]byte(`{}`))),
 			},
+			expected: ErrValidation,
 		},
 		{
 			given: &http.Response{
 				StatusCode: http.StatusConflict,
 				Body:       ioutil.NopCloser(bytes.NewBuffer([]byte(`{}`))),
 			},
+			expected: ErrConflict,
 		},
 		{
 			given: &http.Response{
 				StatusCode: http.StatusInternalServerError,
 				Body:       ioutil.NopCloser(bytes.NewBuffer([]byte(`{}`))),
 			},
+			expected: ErrServer,
 		},
 		{
 			given: &http.Response{
 				StatusCode: http.StatusUnauthorized,
 				Body:       ioutil.NopCloser(bytes.NewBuffer([]byte(`{}`))),
 			},
+			expected: ErrUnauthorized,
+		},
+		{
+			given: &http.Response{
+				StatusCode: http.StatusNotFound,
+				Body:       ioutil.NopCloser(bytes.NewBuffer([]byte(`{}`))),
+			},
+			expected: ErrNotFound,
 		},
 	}
 
@@ -48,9 +64,57 @@ func Test_ProblemDecoder(t *testing.T) {
 		t.Run("", func(t *testing.T) {
 			p := &ProblemDecoder{}
 
-			_, err := p.DecodeProblem(context.TODO(), test.given)
+			problem, err := p.DecodeProblem(context.TODO(), test.given)
 
 			assert.NoError(t, err)
+			assert.ErrorIs(t, problem, test.expected)
 		})
 	}
 }
+
+func Test_ProblemDecoder_ValidationReasons(t *testing.T) {
+	t.Parallel()
+
+	given := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Body: ioutil.NopCloser(bytes.NewBuffer([]byte(`{
+			"type": "/problems/validation-error",
+			"reasons": [{"name": "overall.outerHigh", "reason": "must be greater than innerHigh"}]
+		}`))),
+	}
+
+	p := &ProblemDecoder{}
+
+	problem, err := p.DecodeProblem(context.TODO(), given)
+	require.NoError(t, err)
+
+	var validationErr ValidationError
+
+	require.True(t, errors.As(problem, &validationErr))
+	require.Len(t, validationErr.Reasons, 1)
+	assert.Equal(t, "overall.outerHigh", validationErr.Reasons[0].Name)
+	assert.Equal(t, "must be greater than innerHigh", validationErr.Reasons[0].Reason)
+
+	var validationProblem problems.ValidationProblem
+
+	assert.True(t, errors.As(problem, &validationProblem))
+}
+
+func Test_ProblemDecoder_CorrelationID(t *testing.T) {
+	t.Parallel()
+
+	given := &http.Response{
+		StatusCode: http.StatusInternalServerError,
+		Body:       ioutil.NopCloser(bytes.NewBuffer([]byte(`{"correlationId": "abc123"}`))),
+	}
+
+	p := &ProblemDecoder{}
+
+	problem, err := p.DecodeProblem(context.TODO(), given)
+	require.NoError(t, err)
+
+	var serverErr ServerError
+
+	require.True(t, errors.As(problem, &serverErr))
+	assert.Equal(t, "abc123", serverErr.CorrelationID)
+}
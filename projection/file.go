@@ -0,0 +1,301 @@
+package projection
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SKF/go-pas-client/internal/jsonl"
+	internal_models "github.com/SKF/go-pas-client/internal/models"
+	"github.com/SKF/go-pas-client/internal/responses"
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+const (
+	fileStorePermissions = 0o600
+	fileStoreMaxLineSize = 64 << 20
+)
+
+// FileStore appends the snapshots and checkpoints as JSON lines to a single
+// file, the latest line of each node and checkpoint wins when loading. A line
+// which was only partially written, e.g. due to a crash, is discarded when the
+// file is opened. Use Compact to remove superseded lines.
+type FileStore struct {
+	mutex sync.Mutex
+	path  string
+	file  *os.File
+}
+
+var _ Store = &FileStore{mutex: sync.Mutex{}, path: "", file: nil}
+
+type (
+	fileRecord struct {
+		Snapshot   *fileSnapshot `json:"snapshot,omitempty"`
+		Checkpoint *Checkpoint   `json:"checkpoint,omitempty"`
+	}
+
+	fileSnapshot struct {
+		NodeID             uuid.UUID                                     `json:"nodeId"`
+		Threshold          json.RawMessage                               `json:"threshold,omitempty"`
+		ThresholdVersion   fileVersion                                   `json:"thresholdVersion"`
+		AlarmStatus        *internal_models.ModelsGetAlarmStatusResponse `json:"alarmStatus,omitempty"`
+		AlarmStatusVersion fileVersion                                   `json:"alarmStatusVersion"`
+	}
+
+	fileVersion struct {
+		SequenceID string `json:"sequenceId,omitempty"`
+		Timestamp  int64  `json:"timestamp,omitempty"`
+	}
+)
+
+// OpenFileStore opens the file at path, creating it if it doesn't exist.
+func OpenFileStore(path string) (*FileStore, error) {
+	if err := jsonl.TruncatePartialLine(path); err != nil {
+		return nil, fmt.Errorf("opening file store failed: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, fileStorePermissions)
+	if err != nil {
+		return nil, fmt.Errorf("opening file store failed: %w", err)
+	}
+
+	return &FileStore{
+		mutex: sync.Mutex{},
+		path:  path,
+		file:  file,
+	}, nil
+}
+
+func (s *FileStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.file.Close()
+}
+
+func (s *FileStore) Load(_ context.Context) ([]Snapshot, Checkpoint, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.load()
+}
+
+func (s *FileStore) Save(_ context.Context, snapshots []Snapshot, checkpoint Checkpoint) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	buf, err := encodeRecords(snapshots, checkpoint)
+	if err != nil {
+		return err
+	}
+
+	if _, err = s.file.Write(buf); err != nil {
+		return fmt.Errorf("writing to file store failed: %w", err)
+	}
+
+	if err = s.file.Sync(); err != nil {
+		return fmt.Errorf("syncing file store failed: %w", err)
+	}
+
+	return nil
+}
+
+// Compact rewrites the file with only the latest snapshot of each node and
+// the latest checkpoint.
+func (s *FileStore) Compact(_ context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	snapshots, checkpoint, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	buf, err := encodeRecords(snapshots, checkpoint)
+	if err != nil {
+		return err
+	}
+
+	if err = jsonl.Replace(s.path, buf, fileStorePermissions); err != nil {
+		return fmt.Errorf("compacting file store failed: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, fileStorePermissions)
+	if err != nil {
+		return fmt.Errorf("opening file store failed: %w", err)
+	}
+
+	_ = s.file.Close()
+	s.file = file
+
+	return nil
+}
+
+func (s *FileStore) load() ([]Snapshot, Checkpoint, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, "", fmt.Errorf("opening file store failed: %w", err)
+	}
+	defer file.Close()
+
+	var (
+		latest     = map[uuid.UUID]Snapshot{}
+		checkpoint Checkpoint
+		scanner    = bufio.NewScanner(file)
+	)
+
+	scanner.Buffer(nil, fileStoreMaxLineSize)
+
+	for line := 1; scanner.Scan(); line++ {
+		var (
+			record   fileRecord
+			snapshot Snapshot
+		)
+
+		if err = json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, "", fmt.Errorf("decoding line %d of file store failed: %w", line, err)
+		}
+
+		if record.Checkpoint != nil {
+			checkpoint = *record.Checkpoint
+		}
+
+		if record.Snapshot != nil {
+			if snapshot, err = record.Snapshot.toSnapshot(); err != nil {
+				return nil, "", fmt.Errorf("decoding line %d of file store failed: %w", line, err)
+			}
+
+			latest[snapshot.NodeID] = snapshot
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, "", fmt.Errorf("reading file store failed: %w", err)
+	}
+
+	snapshots := make([]Snapshot, 0, len(latest))
+
+	for _, snapshot := range latest {
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].NodeID < snapshots[j].NodeID
+	})
+
+	return snapshots, checkpoint, nil
+}
+
+// encodeRecords encodes the snapshots followed by the checkpoint, so the
+// checkpoint is only stored if all snapshots before it were.
+func encodeRecords(snapshots []Snapshot, checkpoint Checkpoint) ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+
+	for _, snapshot := range snapshots {
+		encoded, err := newFileSnapshot(snapshot)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = encoder.Encode(fileRecord{Snapshot: encoded, Checkpoint: nil}); err != nil {
+			return nil, fmt.Errorf("encoding snapshot failed: %w", err)
+		}
+	}
+
+	if err := encoder.Encode(fileRecord{Snapshot: nil, Checkpoint: &checkpoint}); err != nil {
+		return nil, fmt.Errorf("encoding checkpoint failed: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func newFileSnapshot(snapshot Snapshot) (*fileSnapshot, error) {
+	encoded := &fileSnapshot{
+		NodeID:             snapshot.NodeID,
+		Threshold:          nil,
+		ThresholdVersion:   newFileVersion(snapshot.ThresholdVersion),
+		AlarmStatus:        nil,
+		AlarmStatusVersion: newFileVersion(snapshot.AlarmStatusVersion),
+	}
+
+	if snapshot.Threshold != nil {
+		threshold, err := json.Marshal(snapshot.Threshold.ToInternal())
+		if err != nil {
+			return nil, fmt.Errorf("encoding threshold failed: %w", err)
+		}
+
+		encoded.Threshold = threshold
+	}
+
+	if snapshot.AlarmStatus != nil {
+		alarmStatus := snapshot.AlarmStatus.ToInternal()
+		encoded.AlarmStatus = &alarmStatus
+	}
+
+	return encoded, nil
+}
+
+func (f fileSnapshot) toSnapshot() (Snapshot, error) {
+	snapshot := Snapshot{
+		Node: Node{
+			NodeID:      f.NodeID,
+			Threshold:   nil,
+			AlarmStatus: nil,
+		},
+		ThresholdVersion:   f.ThresholdVersion.toVersion(),
+		AlarmStatusVersion: f.AlarmStatusVersion.toVersion(),
+	}
+
+	if len(f.Threshold) > 0 {
+		var response responses.Threshold
+
+		if err := json.Unmarshal(f.Threshold, &response); err != nil {
+			return Snapshot{}, fmt.Errorf("decoding threshold failed: %w", err)
+		}
+
+		threshold, err := response.ToThreshold()
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("decoding threshold failed: %w", err)
+		}
+
+		threshold.NodeID = f.NodeID
+		snapshot.Threshold = &threshold
+	}
+
+	if f.AlarmStatus != nil {
+		snapshot.AlarmStatus = new(models.AlarmStatus)
+		snapshot.AlarmStatus.FromInternal(*f.AlarmStatus)
+		snapshot.AlarmStatus.NodeID = f.NodeID
+	}
+
+	return snapshot, nil
+}
+
+func newFileVersion(v models.Version) fileVersion {
+	encoded := fileVersion{SequenceID: v.SequenceID, Timestamp: 0}
+
+	if !v.Timestamp.IsZero() {
+		encoded.Timestamp = v.Timestamp.UnixNano()
+	}
+
+	return encoded
+}
+
+func (f fileVersion) toVersion() models.Version {
+	v := models.Version{SequenceID: f.SequenceID, Timestamp: time.Time{}}
+
+	if f.Timestamp != 0 {
+		v.Timestamp = time.Unix(0, f.Timestamp).UTC()
+	}
+
+	return v
+}
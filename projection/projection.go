@@ -0,0 +1,316 @@
+// Package projection maintains an in-memory read model of the thresholds and
+// alarm statuses of nodes, built from the events published by the PAS service.
+package projection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	pas "github.com/SKF/go-pas-client"
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+// Node is the projected state of a node, the threshold or alarm status is nil
+// until it has been received or seeded.
+type Node struct {
+	NodeID      uuid.UUID
+	Threshold   *models.Threshold
+	AlarmStatus *models.AlarmStatus
+}
+
+// Change is passed to subscribers whenever the projected state of a node
+// changes.
+type Change struct {
+	Previous Node
+	Current  Node
+}
+
+type Filter func(Node) bool
+
+// WithAlarmStatus matches nodes whose alarm status is any of the given
+// statuses, e.g. all nodes in danger.
+func WithAlarmStatus(statuses ...models.AlarmStatusType) Filter {
+	return func(node Node) bool {
+		if node.AlarmStatus == nil {
+			return false
+		}
+
+		for _, status := range statuses {
+			if node.AlarmStatus.Status == status {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Source is used to seed nodes which haven't been seen in any event yet, it's
+// implemented by the client.
+type Source interface {
+	GetThreshold(ctx context.Context, nodeID uuid.UUID) (models.Threshold, error)
+	GetAlarmStatus(ctx context.Context, nodeID uuid.UUID) (models.AlarmStatus, error)
+}
+
+var _ Source = pas.API(nil)
+
+type node struct {
+	threshold          *models.Threshold
+	thresholdVersion   models.Version
+	alarmStatus        *models.AlarmStatus
+	alarmStatusVersion models.Version
+}
+
+func (n node) toNode(nodeID uuid.UUID) Node {
+	result := Node{
+		NodeID:      nodeID,
+		Threshold:   nil,
+		AlarmStatus: nil,
+	}
+
+	if n.threshold != nil {
+		threshold := n.threshold.Clone()
+		result.Threshold = &threshold
+	}
+
+	if n.alarmStatus != nil {
+		alarmStatus := n.alarmStatus.Clone()
+		result.AlarmStatus = &alarmStatus
+	}
+
+	return result
+}
+
+// Projection is safe for concurrent use. Events which are older than, or the
+// same as, the already applied event of a node are ignored, which makes it
+// safe to apply duplicate and out-of-order events. Seeded state carries no
+// version and is replaced by any later event.
+type Projection struct {
+	mutex          sync.RWMutex
+	nodes          map[uuid.UUID]node
+	dirty          map[uuid.UUID]struct{}
+	subscribers    map[int]func(Change)
+	nextSubscriber int
+
+	// notifying makes sure subscribers receive changes in the order they
+	// were applied.
+	notifying sync.Mutex
+}
+
+func New() *Projection {
+	return &Projection{
+		mutex:          sync.RWMutex{},
+		nodes:          map[uuid.UUID]node{},
+		dirty:          map[uuid.UUID]struct{}{},
+		subscribers:    map[int]func(Change){},
+		nextSubscriber: 0,
+		notifying:      sync.Mutex{},
+	}
+}
+
+// Get returns the projected state of the node, and false if nothing is known
+// about it.
+func (p *Projection) Get(nodeID uuid.UUID) (Node, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	n, found := p.nodes[nodeID]
+	if !found {
+		return Node{}, false
+	}
+
+	return n.toNode(nodeID), true
+}
+
+// List returns all nodes matching the filter ordered by node ID, a nil filter
+// matches all nodes.
+func (p *Projection) List(filter Filter) []Node {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	nodes := []Node{}
+
+	for nodeID, n := range p.nodes {
+		if result := n.toNode(nodeID); filter == nil || filter(result) {
+			nodes = append(nodes, result)
+		}
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].NodeID < nodes[j].NodeID
+	})
+
+	return nodes
+}
+
+// Subscribe registers a handler which is called synchronously for every
+// change, the handler must not apply events to the projection. The returned
+// function removes the subscription.
+func (p *Projection) Subscribe(handler func(Change)) func() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	id := p.nextSubscriber
+	p.nextSubscriber++
+	p.subscribers[id] = handler
+
+	return func() {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+
+		delete(p.subscribers, id)
+	}
+}
+
+// ApplyThreshold applies a threshold event and reports whether the projection
+// changed.
+func (p *Projection) ApplyThreshold(event models.ThresholdEvent) bool {
+	v := models.Version{SequenceID: event.SequenceID, Timestamp: event.Timestamp}
+
+	return p.update(event.AggregateID, func(n *node) bool {
+		if n.threshold != nil && !n.thresholdVersion.IsZero() && !v.After(n.thresholdVersion) {
+			return false
+		}
+
+		threshold := event.Threshold.Clone()
+		threshold.NodeID = event.AggregateID
+
+		n.threshold, n.thresholdVersion = &threshold, v
+
+		return true
+	})
+}
+
+// ApplyAlarmStatus applies an alarm status event and reports whether the
+// projection changed.
+func (p *Projection) ApplyAlarmStatus(event models.AlarmStatusEvent) bool {
+	v := models.Version{SequenceID: event.SequenceID, Timestamp: event.Timestamp}
+
+	return p.update(event.AggregateID, func(n *node) bool {
+		if n.alarmStatus != nil && !n.alarmStatusVersion.IsZero() && !v.After(n.alarmStatusVersion) {
+			return false
+		}
+
+		alarmStatus := event.AlarmStatus.Clone()
+		alarmStatus.NodeID = event.AggregateID
+
+		n.alarmStatus, n.alarmStatusVersion = &alarmStatus, v
+
+		return true
+	})
+}
+
+// HandleThreshold applies the event, it can be registered as a handler using
+// events.Router.OnThreshold.
+func (p *Projection) HandleThreshold(_ context.Context, event models.ThresholdEvent) error {
+	p.ApplyThreshold(event)
+
+	return nil
+}
+
+// HandleAlarmStatus applies the event, it can be registered as a handler
+// using events.Router.OnAlarmStatus.
+func (p *Projection) HandleAlarmStatus(_ context.Context, event models.AlarmStatusEvent) error {
+	p.ApplyAlarmStatus(event)
+
+	return nil
+}
+
+// Seed fetches the threshold and alarm status of the nodes from the source,
+// unless they have already been received. Nodes without a threshold or alarm
+// status in the source are left without one.
+func (p *Projection) Seed(ctx context.Context, source Source, nodeIDs ...uuid.UUID) error {
+	for _, nodeID := range nodeIDs {
+		current, _ := p.Get(nodeID)
+
+		if current.Threshold == nil {
+			threshold, err := source.GetThreshold(ctx, nodeID)
+			if err = p.seed(nodeID, err, func(n *node) bool {
+				if n.threshold != nil {
+					return false
+				}
+
+				threshold.NodeID = nodeID
+				n.threshold = &threshold
+
+				return true
+			}); err != nil {
+				return err
+			}
+		}
+
+		if current.AlarmStatus == nil {
+			alarmStatus, err := source.GetAlarmStatus(ctx, nodeID)
+			if err = p.seed(nodeID, err, func(n *node) bool {
+				if n.alarmStatus != nil {
+					return false
+				}
+
+				alarmStatus.NodeID = nodeID
+				n.alarmStatus = &alarmStatus
+
+				return true
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *Projection) seed(nodeID uuid.UUID, err error, apply func(*node) bool) error {
+	if errors.Is(err, pas.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("seeding node %s failed: %w", nodeID, err)
+	}
+
+	p.update(nodeID, apply)
+
+	return nil
+}
+
+// update applies the function to the node and notifies the subscribers if it
+// reports a change.
+func (p *Projection) update(nodeID uuid.UUID, apply func(*node) bool) bool {
+	p.mutex.Lock()
+
+	n, found := p.nodes[nodeID]
+	previous := Node{NodeID: nodeID, Threshold: nil, AlarmStatus: nil}
+
+	if found {
+		previous = n.toNode(nodeID)
+	}
+
+	if !apply(&n) {
+		p.mutex.Unlock()
+
+		return false
+	}
+
+	p.nodes[nodeID] = n
+	p.dirty[nodeID] = struct{}{}
+
+	change := Change{Previous: previous, Current: n.toNode(nodeID)}
+	subscribers := make([]func(Change), 0, len(p.subscribers))
+
+	for _, subscriber := range p.subscribers {
+		subscribers = append(subscribers, subscriber)
+	}
+
+	p.notifying.Lock()
+	p.mutex.Unlock()
+
+	defer p.notifying.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(change)
+	}
+
+	return true
+}
@@ -0,0 +1,218 @@
+package projection_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pas "github.com/SKF/go-pas-client"
+	"github.com/SKF/go-pas-client/events"
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-pas-client/pasmock"
+	"github.com/SKF/go-pas-client/projection"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+const (
+	nodeA = uuid.UUID("0b0f5c3e-3c9a-4b8e-9f3a-6c1d2e4f5a01")
+	nodeB = uuid.UUID("0b0f5c3e-3c9a-4b8e-9f3a-6c1d2e4f5a02")
+)
+
+func alarmStatusEvent(nodeID uuid.UUID, sequenceID string, status models.AlarmStatusType) models.AlarmStatusEvent {
+	return models.AlarmStatusEvent{
+		AggregateID: nodeID,
+		SequenceID:  sequenceID,
+		AlarmStatus: models.AlarmStatus{
+			Status: status,
+			Band:   []models.BandAlarmStatus{},
+			HAL:    []models.HALAlarmStatus{},
+		},
+	}
+}
+
+func Test_ApplyAlarmStatus_Ordering(t *testing.T) {
+	t.Parallel()
+
+	p := projection.New()
+
+	assert.True(t, p.ApplyAlarmStatus(alarmStatusEvent(nodeA, "01A", models.AlarmStatusGood)))
+	assert.True(t, p.ApplyAlarmStatus(alarmStatusEvent(nodeA, "01C", models.AlarmStatusDanger)))
+	assert.False(t, p.ApplyAlarmStatus(alarmStatusEvent(nodeA, "01C", models.AlarmStatusDanger)), "duplicate")
+	assert.False(t, p.ApplyAlarmStatus(alarmStatusEvent(nodeA, "01B", models.AlarmStatusAlert)), "out of order")
+
+	node, found := p.Get(nodeA)
+	require.True(t, found)
+	require.NotNil(t, node.AlarmStatus)
+
+	assert.Equal(t, models.AlarmStatusDanger, node.AlarmStatus.Status)
+	assert.Equal(t, nodeA, node.AlarmStatus.NodeID)
+	assert.Nil(t, node.Threshold)
+}
+
+func Test_ApplyThreshold_OrderingByTimestamp(t *testing.T) {
+	t.Parallel()
+
+	var (
+		p   = projection.New()
+		now = time.Now()
+	)
+
+	newer := models.ThresholdEvent{
+		AggregateID: nodeA,
+		Timestamp:   now,
+		Threshold:   models.Threshold{ThresholdType: models.ThresholdTypeInspection},
+	}
+	older := models.ThresholdEvent{
+		AggregateID: nodeA,
+		Timestamp:   now.Add(-time.Second),
+		Threshold:   models.Threshold{ThresholdType: models.ThresholdTypeNone},
+	}
+
+	assert.True(t, p.ApplyThreshold(newer))
+	assert.False(t, p.ApplyThreshold(older))
+
+	node, found := p.Get(nodeA)
+	require.True(t, found)
+	require.NotNil(t, node.Threshold)
+
+	assert.Equal(t, models.ThresholdTypeInspection, node.Threshold.ThresholdType)
+	assert.Equal(t, nodeA, node.Threshold.NodeID)
+}
+
+func Test_Get_ReturnsCopy(t *testing.T) {
+	t.Parallel()
+
+	p := projection.New()
+	p.ApplyAlarmStatus(alarmStatusEvent(nodeA, "01A", models.AlarmStatusGood))
+
+	node, _ := p.Get(nodeA)
+	node.AlarmStatus.Status = models.AlarmStatusDanger
+
+	node, _ = p.Get(nodeA)
+	assert.Equal(t, models.AlarmStatusGood, node.AlarmStatus.Status)
+
+	_, found := p.Get(nodeB)
+	assert.False(t, found)
+}
+
+func Test_List(t *testing.T) {
+	t.Parallel()
+
+	p := projection.New()
+	p.ApplyAlarmStatus(alarmStatusEvent(nodeB, "01A", models.AlarmStatusDanger))
+	p.ApplyAlarmStatus(alarmStatusEvent(nodeA, "01A", models.AlarmStatusDanger))
+	p.ApplyThreshold(models.ThresholdEvent{AggregateID: uuid.New(), SequenceID: "01A"})
+
+	assert.Len(t, p.List(nil), 3)
+
+	danger := p.List(projection.WithAlarmStatus(models.AlarmStatusDanger))
+	require.Len(t, danger, 2)
+
+	assert.Equal(t, nodeA, danger[0].NodeID)
+	assert.Equal(t, nodeB, danger[1].NodeID)
+	assert.Empty(t, p.List(projection.WithAlarmStatus(models.AlarmStatusGood)))
+}
+
+func Test_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	var (
+		p       = projection.New()
+		changes []projection.Change
+	)
+
+	unsubscribe := p.Subscribe(func(change projection.Change) {
+		changes = append(changes, change)
+	})
+
+	p.ApplyAlarmStatus(alarmStatusEvent(nodeA, "01A", models.AlarmStatusGood))
+	p.ApplyAlarmStatus(alarmStatusEvent(nodeA, "01A", models.AlarmStatusGood))
+	p.ApplyAlarmStatus(alarmStatusEvent(nodeA, "01B", models.AlarmStatusAlert))
+
+	unsubscribe()
+
+	p.ApplyAlarmStatus(alarmStatusEvent(nodeA, "01C", models.AlarmStatusDanger))
+
+	require.Len(t, changes, 2)
+
+	assert.Nil(t, changes[0].Previous.AlarmStatus)
+	assert.Equal(t, models.AlarmStatusGood, changes[0].Current.AlarmStatus.Status)
+	assert.Equal(t, models.AlarmStatusGood, changes[1].Previous.AlarmStatus.Status)
+	assert.Equal(t, models.AlarmStatusAlert, changes[1].Current.AlarmStatus.Status)
+}
+
+func Test_Seed(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx    = context.Background()
+		p      = projection.New()
+		source = pasmock.New().
+			QueueGetThreshold(models.Threshold{}, pas.ErrNotFound).
+			QueueGetThreshold(models.Threshold{ThresholdType: models.ThresholdTypeInspection}, nil).
+			QueueGetAlarmStatus(models.AlarmStatus{}, pas.ErrNotFound)
+	)
+
+	p.ApplyAlarmStatus(alarmStatusEvent(nodeA, "01A", models.AlarmStatusAlert))
+
+	require.NoError(t, p.Seed(ctx, source, nodeA, nodeB))
+	assert.Len(t, source.CallsTo(pasmock.MethodGetAlarmStatus), 1, "only fetched for node B")
+
+	nodeAState, _ := p.Get(nodeA)
+	assert.Nil(t, nodeAState.Threshold)
+	assert.Equal(t, models.AlarmStatusAlert, nodeAState.AlarmStatus.Status)
+
+	nodeBState, found := p.Get(nodeB)
+	require.True(t, found)
+	require.NotNil(t, nodeBState.Threshold)
+
+	assert.Equal(t, nodeB, nodeBState.Threshold.NodeID)
+	assert.Nil(t, nodeBState.AlarmStatus)
+
+	// seeded state is replaced by any event
+	p.ApplyThreshold(models.ThresholdEvent{AggregateID: nodeB, SequenceID: "01A"})
+
+	nodeBState, _ = p.Get(nodeB)
+	assert.Equal(t, models.ThresholdTypeNone, nodeBState.Threshold.ThresholdType)
+}
+
+func Test_Seed_Error(t *testing.T) {
+	t.Parallel()
+
+	var (
+		errBoom = errors.New("boom")
+		p       = projection.New()
+		source  = pasmock.New().QueueGetThreshold(models.Threshold{}, errBoom)
+	)
+
+	err := p.Seed(context.Background(), source, nodeA)
+
+	assert.ErrorIs(t, err, errBoom)
+}
+
+func Test_Router(t *testing.T) {
+	t.Parallel()
+
+	p := projection.New()
+
+	router := events.NewRouter().
+		OnThreshold(p.HandleThreshold).
+		OnAlarmStatus(p.HandleAlarmStatus)
+
+	buf, err := alarmStatusEvent(nodeA, "01A", models.AlarmStatusDanger).Marshal()
+	require.NoError(t, err)
+
+	err = router.Route(context.Background(), buf, map[string]string{
+		models.EventAttributeEventType: models.EventTypeAlarmStatus,
+	})
+	require.NoError(t, err)
+
+	node, found := p.Get(nodeA)
+	require.True(t, found)
+
+	assert.Equal(t, models.AlarmStatusDanger, node.AlarmStatus.Status)
+}
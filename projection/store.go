@@ -0,0 +1,161 @@
+package projection
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+// Checkpoint is the position in the event stream of the last applied event,
+// e.g. a sequence ID or a queue offset. It's not interpreted by the projection.
+type Checkpoint string
+
+// Snapshot is the persisted state of a node, together with the versions of
+// the events it was built from.
+type Snapshot struct {
+	Node
+	ThresholdVersion   models.Version
+	AlarmStatusVersion models.Version
+}
+
+// Store persists the projected state so it survives restarts.
+type Store interface {
+	// Load returns the latest snapshot of each node and the last saved
+	// checkpoint, which is empty if nothing has been saved.
+	Load(ctx context.Context) ([]Snapshot, Checkpoint, error)
+	// Save stores the snapshots, replacing any previous snapshots of the same
+	// nodes, and the checkpoint.
+	Save(ctx context.Context, snapshots []Snapshot, checkpoint Checkpoint) error
+}
+
+// Restore replaces the state of the projection with the state in the store,
+// and returns the checkpoint to resume the event stream from. Subscribers are
+// not notified.
+func (p *Projection) Restore(ctx context.Context, store Store) (Checkpoint, error) {
+	snapshots, checkpoint, err := store.Load(ctx)
+	if err != nil {
+		return "", fmt.Errorf("loading snapshots failed: %w", err)
+	}
+
+	nodes := make(map[uuid.UUID]node, len(snapshots))
+
+	for _, snapshot := range snapshots {
+		restored := snapshot.toNode()
+		nodes[snapshot.NodeID] = node{
+			threshold:          restored.Threshold,
+			thresholdVersion:   snapshot.ThresholdVersion,
+			alarmStatus:        restored.AlarmStatus,
+			alarmStatusVersion: snapshot.AlarmStatusVersion,
+		}
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.nodes = nodes
+	p.dirty = map[uuid.UUID]struct{}{}
+
+	return checkpoint, nil
+}
+
+// Save stores the nodes which changed since the last save together with the
+// checkpoint, which should be the position of the last event applied.
+func (p *Projection) Save(ctx context.Context, store Store, checkpoint Checkpoint) error {
+	p.mutex.Lock()
+
+	snapshots := make([]Snapshot, 0, len(p.dirty))
+
+	for nodeID := range p.dirty {
+		n := p.nodes[nodeID]
+
+		snapshots = append(snapshots, Snapshot{
+			Node:               n.toNode(nodeID),
+			ThresholdVersion:   n.thresholdVersion,
+			AlarmStatusVersion: n.alarmStatusVersion,
+		})
+	}
+
+	p.dirty = map[uuid.UUID]struct{}{}
+	p.mutex.Unlock()
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].NodeID < snapshots[j].NodeID
+	})
+
+	if err := store.Save(ctx, snapshots, checkpoint); err != nil {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+
+		for _, snapshot := range snapshots {
+			p.dirty[snapshot.NodeID] = struct{}{}
+		}
+
+		return fmt.Errorf("saving snapshots failed: %w", err)
+	}
+
+	return nil
+}
+
+// toNode returns a deep copy of the node of the snapshot.
+func (s Snapshot) toNode() Node {
+	return node{
+		threshold:          s.Threshold,
+		thresholdVersion:   s.ThresholdVersion,
+		alarmStatus:        s.AlarmStatus,
+		alarmStatusVersion: s.AlarmStatusVersion,
+	}.toNode(s.NodeID)
+}
+
+// MemoryStore keeps the snapshots in memory, e.g. for tests or to share the
+// state between projections in the same process.
+type MemoryStore struct {
+	mutex      sync.Mutex
+	snapshots  map[uuid.UUID]Snapshot
+	checkpoint Checkpoint
+}
+
+var _ Store = &MemoryStore{mutex: sync.Mutex{}, snapshots: nil, checkpoint: ""}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		mutex:      sync.Mutex{},
+		snapshots:  map[uuid.UUID]Snapshot{},
+		checkpoint: "",
+	}
+}
+
+func (s *MemoryStore) Load(_ context.Context) ([]Snapshot, Checkpoint, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	snapshots := make([]Snapshot, 0, len(s.snapshots))
+
+	for _, snapshot := range s.snapshots {
+		snapshot.Node = snapshot.toNode()
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].NodeID < snapshots[j].NodeID
+	})
+
+	return snapshots, s.checkpoint, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, snapshots []Snapshot, checkpoint Checkpoint) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, snapshot := range snapshots {
+		snapshot.Node = snapshot.toNode()
+		s.snapshots[snapshot.NodeID] = snapshot
+	}
+
+	s.checkpoint = checkpoint
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package projection_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-pas-client/projection"
+)
+
+func f64p(f float64) *float64 {
+	return &f
+}
+
+func openFileStore(t *testing.T, path string) *projection.FileStore {
+	t.Helper()
+
+	store, err := projection.OpenFileStore(path)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		store.Close()
+	})
+
+	return store
+}
+
+func populate(p *projection.Projection) {
+	p.ApplyThreshold(models.ThresholdEvent{
+		AggregateID: nodeA,
+		SequenceID:  "01A",
+		Timestamp:   time.Date(2022, time.March, 4, 12, 30, 0, 123456789, time.UTC),
+		Threshold: models.Threshold{
+			ThresholdType: models.ThresholdTypeOverallOutOfWindow,
+			Overall:       &models.Overall{Unit: "C", OuterHigh: f64p(70), InnerHigh: f64p(50)},
+			BandAlarms:    []models.BandAlarm{},
+			HALAlarms:     []models.HALAlarm{},
+			Origin:        &models.Origin{ID: "template-1", Provider: "templates", Type: "template"},
+		},
+	})
+	p.ApplyAlarmStatus(models.AlarmStatusEvent{
+		AggregateID: nodeA,
+		SequenceID:  "01B",
+		AlarmStatus: models.AlarmStatus{
+			Status:    models.AlarmStatusAlert,
+			UpdatedAt: time.Date(2022, time.March, 4, 12, 30, 0, 0, time.UTC),
+			Overall:   &models.GenericAlarmStatus{Status: models.AlarmStatusAlert},
+			Band:      []models.BandAlarmStatus{},
+			HAL:       []models.HALAlarmStatus{},
+		},
+	})
+	p.ApplyAlarmStatus(alarmStatusEvent(nodeB, "01C", models.AlarmStatusDanger))
+}
+
+func Test_Store_SaveAndRestore(t *testing.T) {
+	t.Parallel()
+
+	stores := map[string]func(*testing.T) projection.Store{
+		"memory": func(*testing.T) projection.Store {
+			return projection.NewMemoryStore()
+		},
+		"file": func(t *testing.T) projection.Store {
+			return openFileStore(t, filepath.Join(t.TempDir(), "projection.jsonl"))
+		},
+	}
+
+	for name, newStore := range stores {
+		newStore := newStore
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				ctx      = context.Background()
+				store    = newStore(t)
+				original = projection.New()
+			)
+
+			populate(original)
+
+			require.NoError(t, original.Save(ctx, store, "checkpoint-1"))
+			require.NoError(t, original.Save(ctx, store, "checkpoint-2"))
+
+			restored := projection.New()
+
+			checkpoint, err := restored.Restore(ctx, store)
+			require.NoError(t, err)
+
+			assert.Equal(t, projection.Checkpoint("checkpoint-2"), checkpoint)
+			assert.Equal(t, original.List(nil), restored.List(nil))
+
+			node, found := restored.Get(nodeA)
+			require.True(t, found)
+			require.NotNil(t, node.Threshold)
+			assert.Equal(t, &models.Origin{ID: "template-1", Provider: "templates", Type: "template"}, node.Threshold.Origin)
+
+			// the versions are restored as well, making replays idempotent
+			assert.False(t, restored.ApplyAlarmStatus(alarmStatusEvent(nodeB, "01C", models.AlarmStatusGood)))
+			assert.True(t, restored.ApplyAlarmStatus(alarmStatusEvent(nodeB, "01D", models.AlarmStatusGood)))
+		})
+	}
+}
+
+func Test_Store_Empty(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx   = context.Background()
+		store = openFileStore(t, filepath.Join(t.TempDir(), "projection.jsonl"))
+		p     = projection.New()
+	)
+
+	checkpoint, err := p.Restore(ctx, store)
+	require.NoError(t, err)
+
+	assert.Empty(t, checkpoint)
+	assert.Empty(t, p.List(nil))
+}
+
+func Test_FileStore_OnlySavesChanges(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx   = context.Background()
+		path  = filepath.Join(t.TempDir(), "projection.jsonl")
+		store = openFileStore(t, path)
+		p     = projection.New()
+	)
+
+	populate(p)
+
+	require.NoError(t, p.Save(ctx, store, "1"))
+	require.NoError(t, p.Save(ctx, store, "2"))
+
+	buf, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, strings.Count(string(buf), "\n"), "two snapshots and two checkpoints")
+}
+
+func Test_FileStore_PartialLine(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx  = context.Background()
+		path = filepath.Join(t.TempDir(), "projection.jsonl")
+		p    = projection.New()
+	)
+
+	populate(p)
+
+	store := openFileStore(t, path)
+	require.NoError(t, p.Save(ctx, store, "1"))
+	require.NoError(t, store.Close())
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(t, err)
+
+	_, err = file.WriteString(`{"checkpoint":"2`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	store = openFileStore(t, path)
+
+	snapshots, checkpoint, err := store.Load(ctx)
+	require.NoError(t, err)
+
+	assert.Len(t, snapshots, 2)
+	assert.Equal(t, projection.Checkpoint("1"), checkpoint)
+
+	require.NoError(t, store.Save(ctx, nil, "3"))
+
+	_, checkpoint, err = store.Load(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, projection.Checkpoint("3"), checkpoint)
+}
+
+func Test_FileStore_NumericEnums(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx  = context.Background()
+		path = filepath.Join(t.TempDir(), "projection.jsonl")
+	)
+
+	// a file with the enums as numbers, like written by earlier versions
+	err := os.WriteFile(path, []byte(`{"snapshot":{"nodeId":"`+string(nodeA)+`",`+
+		`"threshold":{"nodeId":"`+string(nodeA)+`","thresholdType":2,`+
+		`"overall":{"unit":"C","outerHigh":70},`+
+		`"bandAlarms":[{"label":"BPFO",`+
+		`"minFrequency":{"valueType":2,"value":1},"maxFrequency":{"valueType":2,"value":3},`+
+		`"overallThreshold":{"unit":"gE","upperAlert":{"valueType":1,"value":2}}}]},`+
+		`"thresholdVersion":{"sequenceId":"01A"},`+
+		`"alarmStatus":{"nodeId":"`+string(nodeA)+`","status":4,"updatedAt":1646397000000},`+
+		`"alarmStatusVersion":{"sequenceId":"01B"}}}`+"\n"+
+		`{"checkpoint":"1"}`+"\n"), 0o600)
+	require.NoError(t, err)
+
+	snapshots, checkpoint, err := openFileStore(t, path).Load(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, projection.Checkpoint("1"), checkpoint)
+	require.Len(t, snapshots, 1)
+	require.NotNil(t, snapshots[0].Threshold)
+	require.NotNil(t, snapshots[0].AlarmStatus)
+
+	threshold := snapshots[0].Threshold
+	assert.Equal(t, models.ThresholdTypeOverallOutOfWindow, threshold.ThresholdType)
+	require.Len(t, threshold.BandAlarms, 1)
+	assert.Equal(t, models.BandAlarmFrequencySpeedMultiple, threshold.BandAlarms[0].MinFrequency.ValueType)
+	require.NotNil(t, threshold.BandAlarms[0].OverallThreshold)
+	require.NotNil(t, threshold.BandAlarms[0].OverallThreshold.UpperAlert)
+	assert.Equal(t, models.BandAlarmThresholdTypeAbsolute, threshold.BandAlarms[0].OverallThreshold.UpperAlert.ValueType)
+	assert.Equal(t, models.AlarmStatusDanger, snapshots[0].AlarmStatus.Status)
+}
+
+func Test_FileStore_Compact(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx   = context.Background()
+		path  = filepath.Join(t.TempDir(), "projection.jsonl")
+		store = openFileStore(t, path)
+		p     = projection.New()
+	)
+
+	populate(p)
+	require.NoError(t, p.Save(ctx, store, "1"))
+
+	p.ApplyAlarmStatus(alarmStatusEvent(nodeB, "01D", models.AlarmStatusGood))
+	require.NoError(t, p.Save(ctx, store, "2"))
+
+	expectedSnapshots, _, err := store.Load(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Compact(ctx))
+
+	buf, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, strings.Count(string(buf), "\n"), "two snapshots and one checkpoint")
+
+	snapshots, checkpoint, err := store.Load(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, expectedSnapshots, snapshots)
+	assert.Equal(t, projection.Checkpoint("2"), checkpoint)
+
+	require.NoError(t, store.Save(ctx, nil, "3"), "the store is writable after compaction")
+}
@@ -0,0 +1,300 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"reflect"
+	"syscall"
+	"time"
+	"unsafe"
+
+	rest "github.com/SKF/go-rest-utility/client"
+	"github.com/SKF/go-rest-utility/client/retry"
+)
+
+const (
+	defaultRetryBackoffBase = 100 * time.Millisecond
+	defaultRetryBackoffCap  = 5 * time.Second
+	defaultRetries          = 3
+)
+
+// RetryPolicy configures how failed requests are retried, see WithRetryPolicy.
+type RetryPolicy struct {
+	// Backoff returns the delay before each retry, the request fails once it
+	// returns an error. A Retry-After header of the response takes precedence
+	// over the delay.
+	Backoff retry.BackoffProvider
+	// StatusCodes are the response status codes which are retried, connection
+	// resets are always retried.
+	StatusCodes []int
+	// Methods are the HTTP methods which are retried. PatchThreshold is only
+	// retried if its patch begins with a test operation or it's conditional
+	// using PatchThresholdIfMatch, regardless of Methods.
+	Methods []string
+	// OnRetry is called before waiting for each retry.
+	OnRetry func(RetryAttempt)
+}
+
+// RetryAttempt describes a failed request which is about to be retried.
+type RetryAttempt struct {
+	Method string
+	// Attempt is the number of the attempt which failed, starting at 1.
+	Attempt int
+	Delay   time.Duration
+	Err     error
+}
+
+// DefaultRetryPolicy retries GET and PUT requests failing with 429, 502, 503,
+// 504 or a connection reset up to 3 times, with an exponential backoff and
+// jitter.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Backoff: &retry.ExponentialJitterBackoff{
+			Base:         defaultRetryBackoffBase,
+			Cap:          defaultRetryBackoffCap,
+			MaxAttempts:  defaultRetries,
+			JitterSource: rand.Reader,
+		},
+		StatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		Methods: []string{http.MethodGet, http.MethodPut},
+		OnRetry: nil,
+	}
+}
+
+// WithRetryPolicy enables retries of failed requests, by default requests are
+// not retried. Fields of the policy which are nil are taken from
+// DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	defaults := DefaultRetryPolicy()
+
+	if policy.Backoff == nil {
+		policy.Backoff = defaults.Backoff
+	}
+
+	if policy.StatusCodes == nil {
+		policy.StatusCodes = defaults.StatusCodes
+	}
+
+	if policy.Methods == nil {
+		policy.Methods = defaults.Methods
+	}
+
+	return func(o *options) {
+		o.retryPolicy = &policy
+	}
+}
+
+// request builds the rest request for each attempt, as the payload of a rest
+// request can only be read once.
+type request struct {
+	method string
+	route  Route
+	// guarded is set for patches beginning with a test operation, which are
+	// safe to retry as they fail once applied.
+	guarded bool
+	build   func() *rest.Request
+}
+
+func (c *Client) do(ctx context.Context, r request) (*rest.Response, error) {
+	for attempt := 1; ; attempt++ {
+		release, err := c.options.limits.acquire(ctx, r.route)
+		if err != nil {
+			return nil, err
+		}
+
+		var retryAfter time.Duration
+
+		response, err := c.Do(context.WithValue(ctx, retryAfterKey{}, &retryAfter), r.build())
+
+		release()
+
+		if err == nil {
+			return response, nil
+		}
+
+		err = translateError(err)
+
+		delay, retryable := c.options.retryPolicy.delay(r, attempt, err, retryAfter)
+		if !retryable || ctx.Err() != nil {
+			return nil, err
+		}
+
+		if !sleep(ctx, delay) {
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// unmarshal decodes the body of the response into v and closes it.
+func unmarshal(response *rest.Response, v interface{}) error {
+	defer closeBody(response)
+
+	if response.StatusCode == http.StatusNoContent || response.ContentLength == 0 {
+		return nil
+	}
+
+	return translateError(response.Unmarshal(v))
+}
+
+// closeBody closes the body of a response which isn't read.
+func closeBody(response *rest.Response) {
+	_ = response.Body.Close()
+}
+
+// retryAfterKey holds the Retry-After of the error response of an attempt,
+// as recorded by retryAfterTransport.
+type retryAfterKey struct{}
+
+// retryAfterTransport records the Retry-After header of error responses,
+// which the rest client only keeps for responses with a problem body.
+type retryAfterTransport struct {
+	base http.RoundTripper
+}
+
+func (t retryAfterTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	response, err := base.RoundTrip(r)
+	if err != nil || response.StatusCode < http.StatusBadRequest {
+		return response, err //nolint:wrapcheck
+	}
+
+	if after, ok := r.Context().Value(retryAfterKey{}).(*time.Duration); ok {
+		*after = retryAfter(response)
+	}
+
+	return response, nil
+}
+
+// wrapTransport wraps the transport of the rest client in a
+// retryAfterTransport once all options have been applied, so a transport
+// configured by the caller, e.g. using rest.WithCustomTransport, is kept. The
+// rest client doesn't expose its http client, which is why it's looked up by
+// reflection, leaving the transport as is if the field isn't found.
+func wrapTransport(restClient *rest.Client) {
+	field := reflect.ValueOf(restClient).Elem().FieldByName("client")
+	if !field.IsValid() || field.Type() != reflect.TypeOf((*http.Client)(nil)) {
+		return
+	}
+
+	httpClient := *(**http.Client)(unsafe.Pointer(field.UnsafeAddr())) //nolint:gosec
+	if httpClient == nil {
+		return
+	}
+
+	httpClient.Transport = retryAfterTransport{base: httpClient.Transport}
+}
+
+// delay returns how long to wait before retrying the failed attempt, and
+// whether it should be retried at all. The retry after recorded from the
+// response takes precedence over the one of the error.
+func (p *RetryPolicy) delay(r request, attempt int, err error, retryAfter time.Duration) (time.Duration, bool) {
+	if p == nil || !p.retriesMethod(r) || !p.retriesError(err) {
+		return 0, false
+	}
+
+	delay, backoffErr := p.Backoff.BackoffByAttempt(attempt)
+	if backoffErr != nil {
+		return 0, false
+	}
+
+	if retryAfter == 0 {
+		retryAfter = errorRetryAfter(err)
+	}
+
+	if retryAfter > 0 {
+		delay = retryAfter
+	}
+
+	if p.OnRetry != nil {
+		p.OnRetry(RetryAttempt{
+			Method:  r.method,
+			Attempt: attempt,
+			Delay:   delay,
+			Err:     err,
+		})
+	}
+
+	return delay, true
+}
+
+func (p *RetryPolicy) retriesMethod(r request) bool {
+	if r.method == http.MethodPatch {
+		return r.guarded
+	}
+
+	for _, method := range p.Methods {
+		if method == r.method {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *RetryPolicy) retriesError(err error) bool {
+	if isConnectionReset(err) {
+		return true
+	}
+
+	status := errorStatus(err)
+
+	for _, code := range p.StatusCodes {
+		if code == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isConnectionReset reports whether the connection was reset by the peer. A
+// response which ends early, i.e. io.EOF, is not a reset, as the request may
+// have been applied.
+func isConnectionReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET)
+}
+
+// errorStatus returns the status code the PAS API responded with, or 0 if
+// the request failed without a response.
+func errorStatus(err error) int {
+	var (
+		problem interface{ ProblemStatus() int }
+		httpErr rest.HTTPError
+	)
+
+	switch {
+	case errors.As(err, &problem):
+		return problem.ProblemStatus()
+	case errors.As(err, &httpErr):
+		return httpErr.StatusCode
+	default:
+		return 0
+	}
+}
+
+func errorRetryAfter(err error) time.Duration {
+	var (
+		problemErr ProblemError
+		serverErr  ServerError
+	)
+
+	switch {
+	case errors.As(err, &problemErr):
+		return problemErr.RetryAfter
+	case errors.As(err, &serverErr):
+		return serverErr.RetryAfter
+	default:
+		return 0
+	}
+}
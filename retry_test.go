@@ -0,0 +1,350 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wI2L/jsondiff"
+
+	"github.com/SKF/go-pas-client/models"
+	rest "github.com/SKF/go-rest-utility/client"
+	"github.com/SKF/go-rest-utility/client/retry"
+	"github.com/SKF/go-rest-utility/problems"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+// failingServer responds with the status to the first failures requests,
+// echoing the request body of any request after that.
+func failingServer(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		if atomic.AddInt32(&calls, 1) <= failures {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+
+			w.Header().Set("Content-Type", problems.ContentType)
+			w.WriteHeader(status)
+
+			w.Write([]byte(`{"title": "failed"}`))
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		if len(body) > 0 && r.Method == http.MethodPut {
+			w.Write([]byte(`{}`))
+		} else {
+			w.Write([]byte(`{"thresholdType": 1}`))
+		}
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, &calls
+}
+
+func fastRetries(attempts *[]RetryAttempt) RetryPolicy {
+	return RetryPolicy{
+		Backoff:     &retry.ExponentialJitterBackoff{MaxAttempts: 3},
+		StatusCodes: nil,
+		Methods:     nil,
+		OnRetry: func(attempt RetryAttempt) {
+			*attempts = append(*attempts, attempt)
+		},
+	}
+}
+
+func Test_Retry_NotEnabledByDefault(t *testing.T) {
+	t.Parallel()
+
+	server, calls := failingServer(t, 1, http.StatusServiceUnavailable, nil)
+	client := New(rest.WithBaseURL(server.URL))
+
+	_, err := client.GetThreshold(context.Background(), uuid.New())
+
+	assert.ErrorIs(t, err, ErrServer)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func Test_Retry_StatusCodes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		status  int
+		retried bool
+	}{
+		{status: http.StatusTooManyRequests, retried: true},
+		{status: http.StatusBadGateway, retried: true},
+		{status: http.StatusServiceUnavailable, retried: true},
+		{status: http.StatusGatewayTimeout, retried: true},
+		{status: http.StatusInternalServerError, retried: false},
+		{status: http.StatusNotFound, retried: false},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(http.StatusText(test.status), func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				attempts      []RetryAttempt
+				server, calls = failingServer(t, 2, test.status, nil)
+				client        = NewWithOptions([]rest.Option{rest.WithBaseURL(server.URL)}, WithRetryPolicy(fastRetries(&attempts)))
+			)
+
+			_, err := client.GetThreshold(context.Background(), uuid.New())
+
+			if !test.retried {
+				assert.Error(t, err)
+				assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+				assert.Empty(t, attempts)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+			require.Len(t, attempts, 2)
+			assert.Equal(t, http.MethodGet, attempts[0].Method)
+			assert.Equal(t, 1, attempts[0].Attempt)
+			assert.Equal(t, 2, attempts[1].Attempt)
+		})
+	}
+}
+
+func Test_Retry_Exhausted(t *testing.T) {
+	t.Parallel()
+
+	var (
+		attempts      []RetryAttempt
+		server, calls = failingServer(t, 10, http.StatusBadGateway, nil)
+		client        = NewWithOptions([]rest.Option{rest.WithBaseURL(server.URL)}, WithRetryPolicy(fastRetries(&attempts)))
+	)
+
+	err := client.SetThreshold(context.Background(), uuid.New(), models.Threshold{})
+
+	assert.ErrorIs(t, err, ErrServer)
+	assert.Equal(t, int32(4), atomic.LoadInt32(calls))
+	assert.Len(t, attempts, 3)
+}
+
+func Test_Retry_RetryAfter(t *testing.T) {
+	t.Parallel()
+
+	var (
+		attempts      []RetryAttempt
+		server, calls = failingServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}})
+		client        = NewWithOptions([]rest.Option{rest.WithBaseURL(server.URL)}, WithRetryPolicy(fastRetries(&attempts)))
+	)
+
+	start := time.Now()
+
+	_, err := client.GetAlarmStatus(context.Background(), uuid.New())
+	require.NoError(t, err)
+
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+	require.Len(t, attempts, 1)
+	assert.Equal(t, time.Second, attempts[0].Delay)
+
+	var problem ProblemError
+
+	require.ErrorAs(t, attempts[0].Err, &problem)
+	assert.Equal(t, time.Second, problem.RetryAfter)
+}
+
+// countingTransport counts the requests passed to the default transport.
+type countingTransport struct {
+	requests int32
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.requests, 1)
+
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func Test_Retry_RetryAfter_PlainResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]*countingTransport{
+		"default transport": nil,
+		"custom transport":  {requests: 0},
+	}
+
+	for name, transport := range tests {
+		transport := transport
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					w.Header().Set("Content-Type", "text/plain")
+					w.Header().Set("Retry-After", "1")
+					w.WriteHeader(http.StatusServiceUnavailable)
+					w.Write([]byte("try again later"))
+
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status": 2}`))
+			}))
+			defer server.Close()
+
+			restOpts := []rest.Option{rest.WithBaseURL(server.URL)}
+			if transport != nil {
+				restOpts = append(restOpts, rest.WithCustomTransport(transport))
+			}
+
+			var (
+				attempts []RetryAttempt
+				client   = NewWithOptions(restOpts, WithRetryPolicy(fastRetries(&attempts)))
+			)
+
+			_, err := client.GetAlarmStatus(context.Background(), uuid.New())
+			require.NoError(t, err)
+
+			assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+			require.Len(t, attempts, 1)
+			assert.Equal(t, time.Second, attempts[0].Delay)
+
+			if transport != nil {
+				assert.Equal(t, int32(2), atomic.LoadInt32(&transport.requests), "the custom transport is kept")
+			}
+		})
+	}
+}
+
+func Test_Retry_PatchThreshold(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		patch   models.Patch
+		retried bool
+	}{
+		"unguarded": {
+			patch:   models.Patch{{Type: jsondiff.OperationReplace, Path: "/thresholdType", Value: 1}},
+			retried: false,
+		},
+		"guarded": {
+			patch: models.Patch{
+				{Type: jsondiff.OperationTest, Path: "/thresholdType", Value: 2},
+				{Type: jsondiff.OperationReplace, Path: "/thresholdType", Value: 1},
+			},
+			retried: true,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				attempts      []RetryAttempt
+				server, calls = failingServer(t, 1, http.StatusServiceUnavailable, nil)
+				client        = NewWithOptions([]rest.Option{rest.WithBaseURL(server.URL)}, WithRetryPolicy(fastRetries(&attempts)))
+			)
+
+			_, err := client.PatchThreshold(context.Background(), uuid.New(), test.patch)
+
+			if !test.retried {
+				assert.ErrorIs(t, err, ErrServer)
+				assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+			require.Len(t, attempts, 1)
+			assert.Equal(t, http.MethodPatch, attempts[0].Method)
+		})
+	}
+}
+
+func Test_Retry_ConnectionReset(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+
+			// Closing without lingering resets the connection.
+			require.NoError(t, conn.(*net.TCPConn).SetLinger(0))
+
+			conn.Close()
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status": 2}`))
+	}))
+	defer server.Close()
+
+	var (
+		attempts []RetryAttempt
+		client   = NewWithOptions([]rest.Option{rest.WithBaseURL(server.URL)}, WithRetryPolicy(fastRetries(&attempts)))
+	)
+
+	alarmStatus, err := client.GetAlarmStatus(context.Background(), uuid.New())
+	require.NoError(t, err)
+
+	assert.Equal(t, models.AlarmStatusGood, alarmStatus.Status)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Len(t, attempts, 1)
+}
+
+func Test_Retry_ClosedConnectionNotRetried(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+
+		conn.Close()
+	}))
+	defer server.Close()
+
+	var (
+		attempts []RetryAttempt
+		client   = NewWithOptions([]rest.Option{rest.WithBaseURL(server.URL)}, WithRetryPolicy(fastRetries(&attempts)))
+	)
+
+	err := client.SetExternalAlarmStatus(context.Background(), uuid.New(), models.ExternalAlarmStatus{
+		Status: models.AlarmStatusGood,
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Empty(t, attempts)
+}
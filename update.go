@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+// UpdateThreshold fetches the current threshold of the node, applies mutate
+// to it and patches the threshold with the changes, guarded by test
+// operations. If the threshold is modified concurrently the update is retried
+// from the start, once the retries are exhausted a ConflictError is returned.
+// The patch is also conditional on the ETag of the threshold, if the PAS API
+// supplied one.
+func (c *Client) UpdateThreshold(
+	ctx context.Context,
+	nodeID uuid.UUID,
+	mutate func(*models.Threshold) error,
+) (models.Threshold, error) {
+	for attempt := 1; ; attempt++ {
+		current, err := c.GetThreshold(ctx, nodeID)
+		if err != nil {
+			return models.Threshold{}, err
+		}
+
+		updated := current.Clone()
+
+		if err = mutate(&updated); err != nil {
+			return models.Threshold{}, fmt.Errorf("mutating threshold failed: %w", err)
+		}
+
+		patch, err := models.Diff(current, updated, models.WithGuards())
+		if err != nil {
+			return models.Threshold{}, err
+		}
+
+		if len(patch) == 0 {
+			return current, nil
+		}
+
+		threshold, err := c.PatchThresholdIfMatch(ctx, nodeID, patch, current.ETag)
+		if err == nil {
+			return threshold, nil
+		}
+
+		if !errors.Is(err, ErrConflict) && !errors.Is(err, ErrPreconditionFailed) {
+			return models.Threshold{}, err
+		}
+
+		backoff, backoffErr := c.options.conflictBackoff.BackoffByAttempt(attempt)
+		if backoffErr != nil {
+			return models.Threshold{}, ConflictError{NodeID: nodeID, Attempts: attempt, Err: err}
+		}
+
+		timer := time.NewTimer(backoff)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return models.Threshold{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
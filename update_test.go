@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SKF/go-pas-client/models"
+	rest "github.com/SKF/go-rest-utility/client"
+	"github.com/SKF/go-rest-utility/client/retry"
+	"github.com/SKF/go-rest-utility/problems"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+func conflictingServer(t *testing.T, conflicts int32) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var patches int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+
+			w.Write([]byte(`{"thresholdType": 2, "overall": {"unit": "C", "outerHigh": 70}}`))
+		case http.MethodPatch:
+			var patch models.Patch
+
+			body, _ := ioutil.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(body, &patch))
+			assert.Equal(t, "test", string(patch[0].Type))
+
+			if atomic.AddInt32(&patches, 1) <= conflicts {
+				w.Header().Set("Content-Type", problems.ContentType)
+				w.WriteHeader(http.StatusConflict)
+
+				w.Write([]byte(`{"title": "test operation failed"}`))
+
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+
+			w.Write([]byte(`{"thresholdType": 2, "overall": {"unit": "C", "outerHigh": 80}}`))
+		}
+	}))
+
+	return server, &patches
+}
+
+func Test_UpdateThreshold(t *testing.T) {
+	t.Parallel()
+
+	server, patches := conflictingServer(t, 2)
+	defer server.Close()
+
+	client := NewWithOptions([]rest.Option{rest.WithBaseURL(server.URL)}, WithConflictBackoff(&retry.ExponentialJitterBackoff{MaxAttempts: 3}))
+
+	actual, err := client.UpdateThreshold(context.TODO(), uuid.EmptyUUID, func(threshold *models.Threshold) error {
+		threshold.Overall.OuterHigh = f64p(80)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, f64p(80), actual.Overall.OuterHigh)
+	assert.Equal(t, int32(3), atomic.LoadInt32(patches))
+}
+
+func Test_UpdateThreshold_RetriesExhausted(t *testing.T) {
+	t.Parallel()
+
+	server, patches := conflictingServer(t, 10)
+	defer server.Close()
+
+	client := NewWithOptions([]rest.Option{rest.WithBaseURL(server.URL)}, WithConflictBackoff(&retry.ExponentialJitterBackoff{MaxAttempts: 2}))
+
+	_, err := client.UpdateThreshold(context.TODO(), uuid.EmptyUUID, func(threshold *models.Threshold) error {
+		threshold.Overall.OuterHigh = f64p(80)
+
+		return nil
+	})
+
+	var conflictErr ConflictError
+
+	require.True(t, errors.As(err, &conflictErr))
+	assert.Equal(t, 3, conflictErr.Attempts)
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Equal(t, int32(3), atomic.LoadInt32(patches))
+}
+
+func Test_UpdateThreshold_Unchanged(t *testing.T) {
+	t.Parallel()
+
+	server, patches := conflictingServer(t, 0)
+	defer server.Close()
+
+	client := New(rest.WithBaseURL(server.URL))
+
+	actual, err := client.UpdateThreshold(context.TODO(), uuid.EmptyUUID, func(*models.Threshold) error {
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, f64p(70), actual.Overall.OuterHigh)
+	assert.Equal(t, int32(0), atomic.LoadInt32(patches))
+}
@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/SKF/go-rest-utility/client/retry"
+
+	"github.com/SKF/go-pas-client/models"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+const (
+	defaultWatchInterval = time.Minute
+	// watchJitterFraction spreads the polls of each node randomly over a
+	// tenth of the interval.
+	watchJitterFraction = 10
+	// watchBackoffCapFactor caps the backoff after failed polls to a
+	// multiple of the interval.
+	watchBackoffCapFactor = 10
+)
+
+// AlarmStatusChange is delivered by WatchAlarmStatus when the alarm status
+// of a node was updated, or when polling it failed in which case only NodeID
+// and Err are set. Previous is nil for the first alarm status of each node.
+type AlarmStatusChange struct {
+	NodeID      uuid.UUID
+	Previous    *models.AlarmStatus
+	Current     models.AlarmStatus
+	Transitions []models.AlarmTransition
+	Err         error
+}
+
+// WatchAlarmStatus polls the alarm status of the nodes every interval and
+// delivers a change on the returned channel whenever the UpdatedAt of an
+// alarm status changes. Polls are jittered, and nodes failing to be polled
+// are backed off exponentially up to ten intervals. The channel is closed
+// once the context is canceled.
+func (c *Client) WatchAlarmStatus(
+	ctx context.Context,
+	nodeIDs []uuid.UUID,
+	interval time.Duration,
+) <-chan AlarmStatusChange {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	var (
+		changes = make(chan AlarmStatusChange, len(nodeIDs))
+		wg      sync.WaitGroup
+		seen    = make(map[uuid.UUID]struct{}, len(nodeIDs))
+	)
+
+	for _, nodeID := range nodeIDs {
+		if _, duplicate := seen[nodeID]; duplicate {
+			continue
+		}
+
+		seen[nodeID] = struct{}{}
+
+		wg.Add(1)
+
+		go func(nodeID uuid.UUID) {
+			defer wg.Done()
+
+			c.watchAlarmStatus(ctx, nodeID, interval, changes)
+		}(nodeID)
+	}
+
+	go func() {
+		wg.Wait()
+		close(changes)
+	}()
+
+	return changes
+}
+
+func (c *Client) watchAlarmStatus(
+	ctx context.Context,
+	nodeID uuid.UUID,
+	interval time.Duration,
+	changes chan<- AlarmStatusChange,
+) {
+	var (
+		previous *models.AlarmStatus
+		failures int
+		delay    = jitter(interval / watchJitterFraction)
+		backoff  = &retry.ExponentialJitterBackoff{
+			Base:         interval,
+			Cap:          interval * watchBackoffCapFactor,
+			MaxAttempts:  0,
+			JitterSource: rand.Reader,
+		}
+	)
+
+	for sleep(ctx, delay) {
+		current, err := c.GetAlarmStatus(ctx, nodeID)
+
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			failures++
+
+			failure := AlarmStatusChange{
+				NodeID:      nodeID,
+				Previous:    nil,
+				Current:     models.AlarmStatus{},
+				Transitions: nil,
+				Err:         err,
+			}
+
+			if !send(ctx, changes, failure) {
+				return
+			}
+
+			delay = interval
+
+			if extra, backoffErr := backoff.BackoffByAttempt(failures); backoffErr == nil {
+				delay += extra
+			}
+
+			continue
+		case previous == nil || !current.UpdatedAt.Equal(previous.UpdatedAt):
+			change := AlarmStatusChange{
+				NodeID:      nodeID,
+				Previous:    previous,
+				Current:     current,
+				Transitions: nil,
+				Err:         nil,
+			}
+
+			if previous == nil {
+				change.Transitions = models.CompareAlarmStatus(models.AlarmStatus{}, current)
+			} else {
+				change.Transitions = models.CompareAlarmStatus(*previous, current)
+			}
+
+			if !send(ctx, changes, change) {
+				return
+			}
+
+			previous = &current
+		}
+
+		failures = 0
+		delay = interval + jitter(interval/watchJitterFraction)
+	}
+}
+
+// sleep waits for the duration and reports whether the context is still
+// active.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func send(ctx context.Context, changes chan<- AlarmStatusChange, change AlarmStatusChange) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case changes <- change:
+		return true
+	}
+}
+
+// jitter returns a random duration in [0, limit).
+func jitter(limit time.Duration) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(limit)))
+	if err != nil {
+		return 0
+	}
+
+	return time.Duration(n.Int64())
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SKF/go-pas-client/models"
+	rest "github.com/SKF/go-rest-utility/client"
+	"github.com/SKF/go-rest-utility/problems"
+	"github.com/SKF/go-utility/v2/uuid"
+)
+
+func receive(t *testing.T, changes <-chan AlarmStatusChange) AlarmStatusChange {
+	t.Helper()
+
+	select {
+	case change, ok := <-changes:
+		require.True(t, ok, "channel closed")
+
+		return change
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for change")
+	}
+
+	return AlarmStatusChange{}
+}
+
+func Test_WatchAlarmStatus(t *testing.T) {
+	t.Parallel()
+
+	responses := []string{
+		`{"status": 2, "updatedAt": 1000, "overallAlarm": {"status": 2}}`,
+		``,
+		`{"status": 2, "updatedAt": 1000, "overallAlarm": {"status": 2}}`,
+		`{"status": 4, "updatedAt": 2000, "overallAlarm": {"status": 4}}`,
+	}
+
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(atomic.AddInt32(&calls, 1)) - 1
+		if call >= len(responses) {
+			call = len(responses) - 1
+		}
+
+		if responses[call] == "" {
+			w.Header().Set("Content-Type", problems.ContentType)
+			w.WriteHeader(http.StatusInternalServerError)
+
+			w.Write([]byte(`{"title": "internal server error", "status": 500}`))
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		w.Write([]byte(responses[call]))
+	}))
+	defer server.Close()
+
+	var (
+		client      = New(rest.WithBaseURL(server.URL))
+		nodeID      = uuid.New()
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+
+	changes := client.WatchAlarmStatus(ctx, []uuid.UUID{nodeID, nodeID}, 10*time.Millisecond)
+
+	first := receive(t, changes)
+	require.NoError(t, first.Err)
+
+	assert.Equal(t, nodeID, first.NodeID)
+	assert.Nil(t, first.Previous)
+	assert.Equal(t, models.AlarmStatusGood, first.Current.Status)
+	assert.Equal(t, []models.AlarmTransition{{
+		Component: models.AlarmComponentOverall,
+		From:      models.AlarmStatusNotConfigured,
+		To:        models.AlarmStatusGood,
+	}}, first.Transitions)
+
+	failure := receive(t, changes)
+	assert.ErrorIs(t, failure.Err, ErrServer)
+	assert.Equal(t, nodeID, failure.NodeID)
+
+	// the unchanged alarm status is not delivered
+	second := receive(t, changes)
+	require.NoError(t, second.Err)
+
+	require.NotNil(t, second.Previous)
+	assert.Equal(t, models.AlarmStatusGood, second.Previous.Status)
+	assert.Equal(t, models.AlarmStatusDanger, second.Current.Status)
+	assert.Equal(t, []models.AlarmTransition{{
+		Component: models.AlarmComponentOverall,
+		From:      models.AlarmStatusGood,
+		To:        models.AlarmStatusDanger,
+	}}, second.Transitions)
+
+	cancel()
+
+	for range changes {
+		// drain until closed
+	}
+
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&calls), int32(len(responses)))
+}